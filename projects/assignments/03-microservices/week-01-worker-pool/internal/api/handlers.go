@@ -0,0 +1,468 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/cs-mastery/worker-pool/internal/pool"
+	"github.com/cs-mastery/worker-pool/pkg/types"
+)
+
+// maxRetryAfter caps the Retry-After hint, in seconds
+const maxRetryAfter = 60
+
+// Handler serves the REST API for a worker pool
+type Handler struct {
+	pool  *pool.Pool
+	jobID uint64 // Counter for generated job IDs
+}
+
+// NewHandler creates an API handler for the given pool
+func NewHandler(p *pool.Pool) *Handler {
+	return &Handler{pool: p}
+}
+
+// submitJobRequest is the body of POST /jobs
+type submitJobRequest struct {
+	ID       string      `json:"id"`
+	Type     string      `json:"type"`
+	Tenant   string      `json:"tenant"`
+	Key      string      `json:"partition_key"`
+	Data     interface{} `json:"data"`
+	Priority int         `json:"priority"`
+	Timeout  string      `json:"timeout"` // Go duration, e.g. "30s"
+}
+
+// submitBatchRequest is the body of POST /batches
+type submitBatchRequest struct {
+	Jobs []submitJobRequest `json:"jobs"`
+}
+
+// jobResponse describes a job's current state
+type jobResponse struct {
+	ID          string           `json:"id"`
+	Status      string           `json:"status"`
+	Attempt     int              `json:"attempt,omitempty"`
+	WorkerID    int              `json:"worker_id,omitempty"`
+	Error       string           `json:"error,omitempty"`
+	SubmittedAt *time.Time       `json:"submitted_at,omitempty"`
+	UpdatedAt   *time.Time       `json:"updated_at,omitempty"`
+	History     []statusResponse `json:"history,omitempty"`
+}
+
+// statusResponse is one entry of a job's status history
+type statusResponse struct {
+	Status string    `json:"status"`
+	At     time.Time `json:"at"`
+}
+
+// groupResponse describes a batch's progress
+type groupResponse struct {
+	ID         string            `json:"id"`
+	Status     string            `json:"status"` // "running" or "done"
+	JobIDs     []string          `json:"job_ids"`
+	Total      int               `json:"total"`
+	Pending    int               `json:"pending"`
+	Succeeded  int               `json:"succeeded"`
+	Failed     int               `json:"failed"`
+	Errors     map[string]string `json:"errors,omitempty"` // By job ID
+	CreatedAt  time.Time         `json:"created_at"`
+	FinishedAt *time.Time        `json:"finished_at,omitempty"`
+}
+
+// healthResponse is the body of GET /health
+type healthResponse struct {
+	Status      string `json:"status"` // "ok", "paused", "draining" or "stopped"
+	Paused      bool   `json:"paused"`
+	PausedFor   string `json:"paused_for,omitempty"`
+	Workers     int    `json:"workers"`
+	QueueLength int    `json:"queue_length"`
+}
+
+// pauseResponse is the body returned by the pause and resume endpoints
+type pauseResponse struct {
+	Paused    bool   `json:"paused"`
+	PausedFor string `json:"paused_for,omitempty"`
+}
+
+// workerCountRequest is the body of PUT /workers
+type workerCountRequest struct {
+	Count int `json:"count"`
+}
+
+// errorResponse is the body of every error reply
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// Register adds the API routes to router
+func (h *Handler) Register(router *mux.Router) {
+	router.HandleFunc("/jobs", h.SubmitJob).Methods(http.MethodPost)
+	router.HandleFunc("/jobs/{id}", h.GetJobStatus).Methods(http.MethodGet)
+	router.HandleFunc("/jobs/{id}", h.CancelJob).Methods(http.MethodDelete)
+	router.HandleFunc("/batches", h.SubmitBatch).Methods(http.MethodPost)
+	router.HandleFunc("/batches/{id}", h.GetBatchStatus).Methods(http.MethodGet)
+	router.HandleFunc("/batches/{id}", h.CancelBatch).Methods(http.MethodDelete)
+	router.HandleFunc("/metrics", h.GetMetrics).Methods(http.MethodGet)
+	router.HandleFunc("/health", h.HealthCheck).Methods(http.MethodGet)
+	router.HandleFunc("/workers", h.GetWorkers).Methods(http.MethodGet)
+	router.HandleFunc("/workers", h.SetWorkerCount).Methods(http.MethodPut)
+	router.HandleFunc("/admin/pause", h.Pause).Methods(http.MethodPost)
+	router.HandleFunc("/admin/resume", h.Resume).Methods(http.MethodPost)
+}
+
+// SubmitJob queues a job. A missing ID is generated. A request whose
+// Idempotency-Key header repeats an earlier accepted one queues nothing
+// and replays the original response; reusing the key with a different
+// body is refused with 422.
+func (h *Handler) SubmitJob(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("reading request body: %w", err))
+		return
+	}
+	var req submitJobRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+
+	job, err := h.newJob(req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if key := r.Header.Get("Idempotency-Key"); key != "" {
+		sum := sha256.Sum256(body)
+		job.IdempotencyKey = key
+		job.IdempotencyFingerprint = hex.EncodeToString(sum[:])
+	}
+
+	err = h.pool.SubmitWithContext(r.Context(), job)
+	var duplicate *pool.DuplicateSubmissionError
+	if errors.As(err, &duplicate) {
+		w.Header().Set("Idempotent-Replayed", "true")
+		if duplicate.Response == nil {
+			// The first request has not been answered yet
+			writeJSON(w, http.StatusAccepted, jobResponse{ID: duplicate.JobID, Status: types.JobPending.String()})
+			return
+		}
+		writeBody(w, duplicate.Response.StatusCode, duplicate.Response.Body)
+		return
+	}
+	if err != nil {
+		h.writeSubmitError(w, err)
+		return
+	}
+
+	resp, err := json.Marshal(jobResponse{ID: job.ID, Status: types.JobPending.String()})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	h.pool.RecordIdempotentResponse(job.IdempotencyKey, job.ID, pool.IdempotentResponse{StatusCode: http.StatusAccepted, Body: resp})
+	writeBody(w, http.StatusAccepted, resp)
+}
+
+// SubmitBatch queues a batch of jobs as a group, all or nothing. Missing
+// job IDs are generated.
+func (h *Handler) SubmitBatch(w http.ResponseWriter, r *http.Request) {
+	var req submitBatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+
+	jobs := make([]types.Job, 0, len(req.Jobs))
+	for i, jobReq := range req.Jobs {
+		job, err := h.newJob(jobReq)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("job %d: %w", i, err))
+			return
+		}
+		jobs = append(jobs, job)
+	}
+
+	group, err := h.pool.SubmitBatch(r.Context(), jobs)
+	if err != nil {
+		h.writeSubmitError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, newGroupResponse(group.Status()))
+}
+
+// GetBatchStatus returns a batch's progress
+func (h *Handler) GetBatchStatus(w http.ResponseWriter, r *http.Request) {
+	status, err := h.pool.GetGroupStatus(mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newGroupResponse(status))
+}
+
+// CancelBatch cancels every unfinished job in a batch
+func (h *Handler) CancelBatch(w http.ResponseWriter, r *http.Request) {
+	group, err := h.pool.GetGroup(mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+
+	group.Cancel()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetJobStatus returns a job's status and history
+func (h *Handler) GetJobStatus(w http.ResponseWriter, r *http.Request) {
+	record, err := h.pool.GetJobStatus(mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newJobResponse(record))
+}
+
+// CancelJob cancels a queued, scheduled or running job
+func (h *Handler) CancelJob(w http.ResponseWriter, r *http.Request) {
+	err := h.pool.CancelJob(mux.Vars(r)["id"])
+	switch {
+	case err == nil:
+		w.WriteHeader(http.StatusNoContent)
+	case errors.Is(err, pool.ErrJobNotFound):
+		writeError(w, http.StatusNotFound, err)
+	case errors.Is(err, pool.ErrJobFinished):
+		writeError(w, http.StatusConflict, err)
+	default:
+		writeError(w, http.StatusInternalServerError, err)
+	}
+}
+
+// GetMetrics returns the pool metrics
+func (h *Handler) GetMetrics(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, h.pool.GetMetrics())
+}
+
+// HealthCheck reports whether the pool is accepting and processing jobs.
+// A paused pool is healthy; a draining or stopped one is not.
+func (h *Handler) HealthCheck(w http.ResponseWriter, r *http.Request) {
+	resp := healthResponse{
+		Status:      "ok",
+		Workers:     h.pool.GetWorkerCount(),
+		QueueLength: h.pool.GetQueueLength(),
+	}
+	if pausedFor := h.pool.PausedFor(); h.pool.IsPaused() {
+		resp.Status = "paused"
+		resp.Paused = true
+		resp.PausedFor = pausedFor.Round(time.Millisecond).String()
+	}
+
+	code := http.StatusOK
+	switch {
+	case !h.pool.IsRunning():
+		resp.Status = "stopped"
+		code = http.StatusServiceUnavailable
+	case h.pool.IsDraining():
+		resp.Status = "draining"
+		code = http.StatusServiceUnavailable
+	}
+
+	writeJSON(w, code, resp)
+}
+
+// GetWorkers lists the pool's workers
+func (h *Handler) GetWorkers(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, h.pool.GetWorkers())
+}
+
+// SetWorkerCount resizes the pool
+func (h *Handler) SetWorkerCount(w http.ResponseWriter, r *http.Request) {
+	var req workerCountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+
+	if err := h.pool.SetWorkerCount(req.Count); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, workerCountRequest{Count: h.pool.GetWorkerCount()})
+}
+
+// Pause stops workers picking up queued jobs
+func (h *Handler) Pause(w http.ResponseWriter, r *http.Request) {
+	if err := h.pool.Pause(); err != nil {
+		writeError(w, http.StatusServiceUnavailable, err)
+		return
+	}
+	h.writePauseState(w)
+}
+
+// Resume lets workers pick up queued jobs again
+func (h *Handler) Resume(w http.ResponseWriter, r *http.Request) {
+	if err := h.pool.Resume(); err != nil {
+		writeError(w, http.StatusServiceUnavailable, err)
+		return
+	}
+	h.writePauseState(w)
+}
+
+func (h *Handler) writePauseState(w http.ResponseWriter) {
+	resp := pauseResponse{Paused: h.pool.IsPaused()}
+	if resp.Paused {
+		resp.PausedFor = h.pool.PausedFor().Round(time.Millisecond).String()
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// newJob builds a job from a submit request, generating a missing ID
+func (h *Handler) newJob(req submitJobRequest) (types.Job, error) {
+	job := types.Job{
+		ID:           req.ID,
+		Type:         req.Type,
+		Tenant:       req.Tenant,
+		PartitionKey: req.Key,
+		Data:         req.Data,
+		Priority:     req.Priority,
+	}
+	if job.ID == "" {
+		job.ID = fmt.Sprintf("job-%d-%d", time.Now().UnixNano(), atomic.AddUint64(&h.jobID, 1))
+	}
+	if req.Timeout != "" {
+		timeout, err := time.ParseDuration(req.Timeout)
+		if err != nil {
+			return job, fmt.Errorf("invalid timeout: %w", err)
+		}
+		job.Timeout = timeout
+	}
+	return job, nil
+}
+
+// writeSubmitError replies to a rejected submission, with a Retry-After
+// hint when the client may succeed later
+func (h *Handler) writeSubmitError(w http.ResponseWriter, err error) {
+	code := submitStatus(err)
+	if code == http.StatusTooManyRequests || code == http.StatusServiceUnavailable {
+		w.Header().Set("Retry-After", strconv.Itoa(h.retryAfter()))
+	}
+	writeError(w, code, err)
+}
+
+// submitStatus maps a submission error to an HTTP status code
+func submitStatus(err error) int {
+	var unknownType *pool.UnknownJobTypeError
+	switch {
+	case errors.Is(err, pool.ErrMissingJobID), errors.As(err, &unknownType),
+		errors.Is(err, pool.ErrEmptyBatch), errors.Is(err, pool.ErrBatchTooLarge),
+		errors.Is(err, pool.ErrBatchIdempotencyKey):
+		return http.StatusBadRequest
+	case errors.Is(err, pool.ErrDuplicateJobID):
+		return http.StatusConflict
+	case errors.Is(err, pool.ErrIdempotencyKeyReused):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, pool.ErrQueueFull), errors.Is(err, pool.ErrTenantQueueFull),
+		errors.Is(err, pool.ErrJobDropped):
+		return http.StatusTooManyRequests
+	case errors.Is(err, pool.ErrPoolNotRunning), errors.Is(err, pool.ErrPoolDraining),
+		errors.Is(err, pool.ErrSubmitTimeout):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// retryAfter estimates how many seconds a rejected client should wait
+// before retrying: the time to work through the current queue at the
+// recent throughput, between 1 and maxRetryAfter
+func (h *Handler) retryAfter() int {
+	metrics := h.pool.GetMetrics()
+	if metrics.JobsPerSecond <= 0 {
+		return 1
+	}
+
+	seconds := int(math.Ceil(float64(metrics.QueueLength) / metrics.JobsPerSecond))
+	switch {
+	case seconds < 1:
+		return 1
+	case seconds > maxRetryAfter:
+		return maxRetryAfter
+	default:
+		return seconds
+	}
+}
+
+// newJobResponse converts a job record to its API form
+func newJobResponse(record types.JobRecord) jobResponse {
+	resp := jobResponse{
+		ID:          record.ID,
+		Status:      record.Status.String(),
+		Attempt:     record.Attempt,
+		WorkerID:    record.WorkerID,
+		SubmittedAt: &record.SubmittedAt,
+		UpdatedAt:   &record.UpdatedAt,
+	}
+	if record.Error != nil {
+		resp.Error = record.Error.Error()
+	}
+	for _, change := range record.History {
+		resp.History = append(resp.History, statusResponse{Status: change.Status.String(), At: change.At})
+	}
+	return resp
+}
+
+// newGroupResponse converts a group status to its API form
+func newGroupResponse(status types.GroupStatus) groupResponse {
+	resp := groupResponse{
+		ID:        status.ID,
+		Status:    "running",
+		JobIDs:    status.JobIDs,
+		Total:     status.Total,
+		Pending:   status.Pending,
+		Succeeded: status.Succeeded,
+		Failed:    status.Failed,
+		CreatedAt: status.CreatedAt,
+	}
+	if status.Done() {
+		resp.Status = "done"
+		resp.FinishedAt = &status.FinishedAt
+	}
+	if len(status.Errors) > 0 {
+		resp.Errors = make(map[string]string, len(status.Errors))
+		for id, err := range status.Errors {
+			resp.Errors[id] = err.Error()
+		}
+	}
+	return resp
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeBody writes an already encoded JSON body
+func writeBody(w http.ResponseWriter, code int, body []byte) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(body)
+}
+
+func writeError(w http.ResponseWriter, code int, err error) {
+	writeJSON(w, code, errorResponse{Error: err.Error()})
+}
@@ -0,0 +1,129 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/cs-mastery/worker-pool/internal/pool"
+	"github.com/cs-mastery/worker-pool/pkg/types"
+)
+
+// newTestServer starts config's pool and serves its API. The pool is
+// paused, so submitted jobs stay queued until the test resumes it.
+func newTestServer(t *testing.T, config types.PoolConfig) (*pool.Pool, http.Handler) {
+	t.Helper()
+
+	config.Handler = func(ctx context.Context, job types.Job) (interface{}, error) {
+		return nil, nil
+	}
+	p := pool.NewPool(config)
+	if err := p.Start(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { p.Stop() })
+	if err := p.Pause(); err != nil {
+		t.Fatal(err)
+	}
+
+	router := mux.NewRouter()
+	NewHandler(p).Register(router)
+	return p, router
+}
+
+// post sends a JSON body to path with the given headers
+func post(handler http.Handler, path, body string, header map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	for name, value := range header {
+		req.Header.Set(name, value)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestSubmitStatus(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{pool.ErrMissingJobID, http.StatusBadRequest},
+		{&pool.UnknownJobTypeError{Type: "x"}, http.StatusBadRequest},
+		{fmt.Errorf("batch job 0: %w", pool.ErrBatchIdempotencyKey), http.StatusBadRequest},
+		{pool.ErrDuplicateJobID, http.StatusConflict},
+		{pool.ErrQueueFull, http.StatusTooManyRequests},
+		{pool.ErrTenantQueueFull, http.StatusTooManyRequests},
+		{pool.ErrJobDropped, http.StatusTooManyRequests},
+		{fmt.Errorf("batch job a: %w", pool.ErrTenantQueueFull), http.StatusTooManyRequests},
+		{pool.ErrPoolDraining, http.StatusServiceUnavailable},
+		{pool.ErrSubmitTimeout, http.StatusServiceUnavailable},
+		{errors.New("disk full"), http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		if got := submitStatus(tt.err); got != tt.want {
+			t.Errorf("submitStatus(%v) = %d, want %d", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestSubmitJobDroppedNewest(t *testing.T) {
+	_, handler := newTestServer(t, types.PoolConfig{
+		WorkerCount:    1,
+		QueueSize:      1,
+		OverflowPolicy: types.OverflowDropNewest,
+	})
+
+	if rec := post(handler, "/jobs", `{"id": "first"}`, nil); rec.Code != http.StatusAccepted {
+		t.Fatalf("first submit = %d %s, want 202", rec.Code, rec.Body)
+	}
+	rec := post(handler, "/jobs", `{"id": "second"}`, nil)
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("submit to a full queue = %d %s, want 429", rec.Code, rec.Body)
+	}
+	if rec.Header().Get("Retry-After") == "" {
+		t.Error("429 response has no Retry-After header")
+	}
+}
+
+func TestSubmitJobReplaysIdempotentResponse(t *testing.T) {
+	_, handler := newTestServer(t, types.PoolConfig{IdempotencyWindow: time.Minute})
+	key := map[string]string{"Idempotency-Key": "order-42"}
+
+	// Without an ID in the body the job ID is generated, so only a
+	// replay can return the same one
+	first := post(handler, "/jobs", `{"data": {"order": 42}}`, key)
+	if first.Code != http.StatusAccepted {
+		t.Fatalf("first submit = %d %s, want 202", first.Code, first.Body)
+	}
+
+	again := post(handler, "/jobs", `{"data": {"order": 42}}`, key)
+	if again.Code != first.Code || again.Body.String() != first.Body.String() {
+		t.Errorf("replay = %d %s, want %d %s", again.Code, again.Body, first.Code, first.Body)
+	}
+	if again.Header().Get("Idempotent-Replayed") != "true" {
+		t.Error("replay is missing the Idempotent-Replayed header")
+	}
+}
+
+func TestSubmitJobRejectsReusedIdempotencyKey(t *testing.T) {
+	p, handler := newTestServer(t, types.PoolConfig{IdempotencyWindow: time.Minute})
+	key := map[string]string{"Idempotency-Key": "order-42"}
+
+	if rec := post(handler, "/jobs", `{"id": "a", "data": 1}`, key); rec.Code != http.StatusAccepted {
+		t.Fatalf("first submit = %d %s, want 202", rec.Code, rec.Body)
+	}
+	rec := post(handler, "/jobs", `{"id": "b", "data": 2}`, key)
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("submit with a reused key = %d %s, want 422", rec.Code, rec.Body)
+	}
+	if _, err := p.GetJobStatus("b"); !errors.Is(err, pool.ErrJobNotFound) {
+		t.Errorf("job submitted with a reused key was queued: %v", err)
+	}
+}
@@ -0,0 +1,150 @@
+package config
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/cs-mastery/worker-pool/internal/pool"
+	"github.com/cs-mastery/worker-pool/pkg/types"
+)
+
+// Config holds the service configuration
+type Config struct {
+	// Worker pool settings
+	WorkerCount       int
+	QueueSize         int
+	JobTimeout        time.Duration
+	ShutdownTimeout   time.Duration
+	OverflowPolicy    types.OverflowPolicy
+	SubmitTimeout     time.Duration
+	IdempotencyWindow time.Duration
+
+	// Lease settings: a zero VisibilityTimeout disables leases
+	VisibilityTimeout time.Duration
+	MaxDeliveries     int
+
+	// Durable queue settings: an empty WALDir keeps jobs in memory only
+	WALDir   string
+	WALFsync pool.FsyncPolicy
+
+	// HTTP server settings
+	HTTPPort    int
+	HTTPTimeout time.Duration
+
+	// Metrics settings
+	EnableMetrics   bool
+	MetricsInterval time.Duration
+}
+
+// Load reads the configuration from environment variables, falling back
+// to defaults for unset or invalid values
+func Load() Config {
+	defaults := types.DefaultPoolConfig()
+
+	return Config{
+		WorkerCount:       getInt("WORKER_COUNT", defaults.WorkerCount),
+		QueueSize:         getInt("QUEUE_SIZE", defaults.QueueSize),
+		JobTimeout:        getDuration("JOB_TIMEOUT", defaults.JobTimeout),
+		ShutdownTimeout:   getDuration("SHUTDOWN_TIMEOUT", defaults.ShutdownTimeout),
+		OverflowPolicy:    getOverflowPolicy("OVERFLOW_POLICY", defaults.OverflowPolicy),
+		SubmitTimeout:     getDuration("SUBMIT_TIMEOUT", defaults.SubmitTimeout),
+		IdempotencyWindow: getDuration("IDEMPOTENCY_WINDOW", defaults.IdempotencyWindow),
+		VisibilityTimeout: getDuration("VISIBILITY_TIMEOUT", defaults.VisibilityTimeout),
+		MaxDeliveries:     getInt("MAX_DELIVERIES", defaults.MaxDeliveries),
+		WALDir:            os.Getenv("WAL_DIR"),
+		WALFsync:          getFsyncPolicy("WAL_FSYNC", pool.FsyncAlways),
+		HTTPPort:          getInt("HTTP_PORT", 8080),
+		HTTPTimeout:       getDuration("HTTP_TIMEOUT", 10*time.Second),
+		EnableMetrics:     getBool("METRICS_ENABLED", defaults.EnableMetrics),
+		MetricsInterval:   getDuration("METRICS_INTERVAL", defaults.MetricsInterval),
+	}
+}
+
+// PoolConfig returns the worker pool configuration, starting from the
+// pool defaults
+func (c Config) PoolConfig() types.PoolConfig {
+	poolConfig := types.DefaultPoolConfig()
+	poolConfig.WorkerCount = c.WorkerCount
+	poolConfig.QueueSize = c.QueueSize
+	poolConfig.JobTimeout = c.JobTimeout
+	poolConfig.ShutdownTimeout = c.ShutdownTimeout
+	poolConfig.OverflowPolicy = c.OverflowPolicy
+	poolConfig.SubmitTimeout = c.SubmitTimeout
+	poolConfig.IdempotencyWindow = c.IdempotencyWindow
+	poolConfig.VisibilityTimeout = c.VisibilityTimeout
+	poolConfig.MaxDeliveries = c.MaxDeliveries
+	poolConfig.EnableMetrics = c.EnableMetrics
+	poolConfig.MetricsInterval = c.MetricsInterval
+	return poolConfig
+}
+
+func getInt(key string, fallback int) int {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return fallback
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("config: invalid %s=%q, using %d", key, value, fallback)
+		return fallback
+	}
+	return n
+}
+
+func getDuration(key string, fallback time.Duration) time.Duration {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return fallback
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("config: invalid %s=%q, using %v", key, value, fallback)
+		return fallback
+	}
+	return d
+}
+
+// getOverflowPolicy parses a policy name such as "fail_fast"
+func getOverflowPolicy(key string, fallback types.OverflowPolicy) types.OverflowPolicy {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return fallback
+	}
+	for policy := types.OverflowBlock; policy <= types.OverflowCallerRuns; policy++ {
+		if policy.String() == value {
+			return policy
+		}
+	}
+	log.Printf("config: invalid %s=%q, using %s", key, value, fallback)
+	return fallback
+}
+
+// getFsyncPolicy parses a policy name such as "interval"
+func getFsyncPolicy(key string, fallback pool.FsyncPolicy) pool.FsyncPolicy {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return fallback
+	}
+	for policy := pool.FsyncAlways; policy <= pool.FsyncNever; policy++ {
+		if policy.String() == value {
+			return policy
+		}
+	}
+	log.Printf("config: invalid %s=%q, using %s", key, value, fallback)
+	return fallback
+}
+
+func getBool(key string, fallback bool) bool {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return fallback
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Printf("config: invalid %s=%q, using %t", key, value, fallback)
+		return fallback
+	}
+	return b
+}
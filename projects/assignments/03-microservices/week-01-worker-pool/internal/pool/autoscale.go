@@ -0,0 +1,258 @@
+package pool
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cs-mastery/worker-pool/pkg/types"
+)
+
+// maxScaleEvents bounds the scaling history kept for metrics
+const maxScaleEvents = 50
+
+// autoscaler holds the state behind the pool's scaling decisions
+type autoscaler struct {
+	config types.AutoscaleConfig
+
+	mu            sync.Mutex
+	waits         []time.Duration // Queue waits observed since the last evaluation
+	idleSince     time.Time       // When spare capacity was first seen; zero while saturated
+	lastScaleUp   time.Time
+	lastScaleDown time.Time
+	scaleUps      int64
+	scaleDowns    int64
+	events        []types.ScaleEvent // Oldest first, at most maxScaleEvents
+}
+
+// newAutoscaler creates an autoscaler, or returns nil if autoscaling is
+// disabled
+func newAutoscaler(config types.AutoscaleConfig) *autoscaler {
+	if config.MaxWorkers <= 0 {
+		return nil
+	}
+
+	if config.MinWorkers <= 0 {
+		config.MinWorkers = 1
+	}
+	if config.MaxWorkers < config.MinWorkers {
+		config.MaxWorkers = config.MinWorkers
+	}
+	if config.Interval <= 0 {
+		config.Interval = time.Second
+	}
+	if config.QueuePerWorker <= 0 {
+		config.QueuePerWorker = 1
+	}
+	return &autoscaler{config: config}
+}
+
+// clamp keeps a worker count within the configured bounds
+func (a *autoscaler) clamp(count int) int {
+	if count < a.config.MinWorkers {
+		return a.config.MinWorkers
+	}
+	if count > a.config.MaxWorkers {
+		return a.config.MaxWorkers
+	}
+	return count
+}
+
+// observeWait records how long a job waited in the queue
+func (a *autoscaler) observeWait(wait time.Duration) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.waits = append(a.waits, wait)
+}
+
+// takeP95 returns the 95th percentile of the waits observed since the
+// last call and resets the window
+func (a *autoscaler) takeP95() time.Duration {
+	a.mu.Lock()
+	waits := a.waits
+	a.waits = nil
+	a.mu.Unlock()
+
+	if len(waits) == 0 {
+		return 0
+	}
+	sort.Slice(waits, func(i, j int) bool { return waits[i] < waits[j] })
+	return waits[int(math.Ceil(0.95*float64(len(waits))))-1]
+}
+
+// decide returns the worker count the pool should move to and why, or
+// the current count and "" to stay put
+func (a *autoscaler) decide(now time.Time, workers, busy, queued int, p95 time.Duration) (int, string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	cfg := a.config
+	if workers < cfg.MinWorkers {
+		return cfg.MinWorkers, "below minimum"
+	}
+	if workers > cfg.MaxWorkers {
+		return cfg.MaxWorkers, "above maximum"
+	}
+
+	// Spare capacity: an idle worker and nothing waiting
+	if busy < workers && queued == 0 {
+		if a.idleSince.IsZero() {
+			a.idleSince = now
+		}
+	} else {
+		a.idleSince = time.Time{}
+	}
+
+	var reason string
+	switch {
+	case queued > workers*cfg.QueuePerWorker:
+		reason = fmt.Sprintf("queue depth %d exceeds %d per worker", queued, cfg.QueuePerWorker)
+	case cfg.WaitP95 > 0 && p95 > cfg.WaitP95:
+		reason = fmt.Sprintf("p95 wait %v exceeds %v", p95.Round(time.Millisecond), cfg.WaitP95)
+	}
+	if reason != "" {
+		if workers == cfg.MaxWorkers || now.Sub(a.lastScaleUp) < cfg.ScaleUpCooldown {
+			return workers, ""
+		}
+		// Add enough workers to cover the backlog, at least one
+		target := workers + 1
+		if needed := (queued + cfg.QueuePerWorker - 1) / cfg.QueuePerWorker; needed > target {
+			target = needed
+		}
+		return a.clamp(target), reason
+	}
+
+	if cfg.IdleTime > 0 && !a.idleSince.IsZero() && now.Sub(a.idleSince) >= cfg.IdleTime &&
+		workers > cfg.MinWorkers && now.Sub(a.lastScaleDown) >= cfg.ScaleDownCooldown {
+		return workers - 1, fmt.Sprintf("idle capacity for %v", now.Sub(a.idleSince).Round(time.Millisecond))
+	}
+	return workers, ""
+}
+
+// record notes a scaling change and starts the matching cooldown
+func (a *autoscaler) record(event types.ScaleEvent) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if event.To > event.From {
+		a.scaleUps++
+		a.lastScaleUp = event.At
+	} else {
+		a.scaleDowns++
+		a.lastScaleDown = event.At
+		// Idle time must build up again before the next step down
+		a.idleSince = time.Time{}
+	}
+
+	a.events = append(a.events, event)
+	if len(a.events) > maxScaleEvents {
+		a.events = a.events[len(a.events)-maxScaleEvents:]
+	}
+}
+
+// snapshot returns the scale counters and a copy of the event history
+func (a *autoscaler) snapshot() (ups, downs int64, events []types.ScaleEvent) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.scaleUps, a.scaleDowns, append([]types.ScaleEvent(nil), a.events...)
+}
+
+// runAutoscaler periodically resizes the pool within the configured
+// bounds
+func (p *Pool) runAutoscaler(ctx context.Context) {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(p.autoscaler.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case now := <-ticker.C:
+			p.autoscale(ctx, now)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// autoscale makes one scaling decision
+func (p *Pool) autoscale(ctx context.Context, now time.Time) {
+	p.workersMu.Lock()
+	workers, busy := len(p.workers), 0
+	for _, w := range p.workers {
+		if w.IsBusy() {
+			busy++
+		}
+	}
+	p.workersMu.Unlock()
+
+	// Jobs parked behind a job type or partition key cap are left out:
+	// they wait for a running job to finish, not for a free worker, so
+	// more workers would not start them any sooner
+	p.queueMu.Lock()
+	queued := p.queue.Len()
+	p.queueMu.Unlock()
+	if p.stealer != nil {
+		queued += int(p.stealer.size.Load())
+	}
+
+	target, reason := p.autoscaler.decide(now, workers, busy, queued, p.autoscaler.takeP95())
+	if target != workers {
+		p.resize(ctx, target, reason)
+	}
+}
+
+// ScaleEvents returns the recent scaling changes, oldest first
+func (p *Pool) ScaleEvents() []types.ScaleEvent {
+	if p.autoscaler == nil {
+		return nil
+	}
+	_, _, events := p.autoscaler.snapshot()
+	return events
+}
+
+// resize starts or retires workers until the pool has count of them and
+// records the change. Retired workers finish their current job first;
+// idle workers are retired before busy ones.
+func (p *Pool) resize(ctx context.Context, count int, reason string) {
+	p.workersMu.Lock()
+	if ctx.Err() != nil {
+		p.workersMu.Unlock()
+		return
+	}
+
+	from := len(p.workers)
+	for len(p.workers) < count {
+		p.workers = append(p.workers, p.startWorker(ctx, p.nextWorkerID))
+		p.nextWorkerID++
+	}
+	if len(p.workers) > count {
+		// Stable sort puts idle workers first
+		sort.SliceStable(p.workers, func(i, j int) bool {
+			return !p.workers[i].IsBusy() && p.workers[j].IsBusy()
+		})
+		for _, w := range p.workers[:len(p.workers)-count] {
+			w.Retire()
+		}
+		p.workers = append([]*Worker(nil), p.workers[len(p.workers)-count:]...)
+	}
+	p.metrics.SetTotalWorkers(int32(len(p.workers)))
+	p.workersMu.Unlock()
+
+	if from == count {
+		return
+	}
+
+	event := types.ScaleEvent{At: time.Now(), From: from, To: count, Reason: reason}
+	if p.autoscaler != nil {
+		p.autoscaler.record(event)
+	}
+	slog.Info("worker pool scaled",
+		slog.Int("from", from),
+		slog.Int("to", count),
+		slog.String("reason", reason))
+}
@@ -0,0 +1,117 @@
+package pool
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cs-mastery/worker-pool/pkg/types"
+)
+
+func TestAutoscalerDecide(t *testing.T) {
+	config := types.AutoscaleConfig{
+		MinWorkers:        2,
+		MaxWorkers:        8,
+		QueuePerWorker:    2,
+		WaitP95:           100 * time.Millisecond,
+		IdleTime:          time.Minute,
+		ScaleUpCooldown:   10 * time.Second,
+		ScaleDownCooldown: 90 * time.Second,
+	}
+	start := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	// Steps run in order against one autoscaler; each decision that
+	// changes the count is recorded, starting its cooldown
+	steps := []struct {
+		name                  string
+		at                    time.Duration
+		workers, busy, queued int
+		p95                   time.Duration
+		want                  int
+	}{
+		{"below minimum", 0, 1, 1, 0, 0, 2},
+		{"above maximum", 0, 9, 9, 0, 0, 8},
+		{"queue within limit", 10 * time.Second, 2, 2, 4, 0, 2},
+		{"backlog covered at once", 10 * time.Second, 2, 2, 10, 0, 5},
+		{"scale-up cooldown", 15 * time.Second, 5, 5, 20, 0, 5},
+		{"cooldown over", 21 * time.Second, 5, 5, 12, 0, 6},
+		{"slow waits", 32 * time.Second, 6, 6, 0, 200 * time.Millisecond, 7},
+		{"capped at maximum", 43 * time.Second, 7, 7, 40, 0, 8},
+		{"at maximum", 54 * time.Second, 8, 8, 40, 0, 8},
+		{"idle starts", 60 * time.Second, 8, 2, 0, 0, 8},
+		{"idle too briefly", 90 * time.Second, 8, 2, 0, 0, 8},
+		{"idle long enough", 120 * time.Second, 8, 2, 0, 0, 7},
+		{"idle time restarts after a step down", 130 * time.Second, 7, 2, 0, 0, 7},
+		{"scale-down cooldown", 200 * time.Second, 7, 2, 0, 0, 7},
+		{"cooldown and idle time over", 211 * time.Second, 7, 2, 0, 0, 6},
+		{"busy again resets idle time", 220 * time.Second, 6, 6, 0, 0, 6},
+		{"not idle long enough since", 250 * time.Second, 6, 1, 0, 0, 6},
+		{"never below minimum", 400 * time.Second, 2, 0, 0, 0, 2},
+	}
+
+	a := newAutoscaler(config)
+	for _, step := range steps {
+		now := start.Add(step.at)
+		got, reason := a.decide(now, step.workers, step.busy, step.queued, step.p95)
+		if got != step.want {
+			t.Fatalf("%s: decide = %d (%q), want %d", step.name, got, reason, step.want)
+		}
+		if (got != step.workers) != (reason != "") {
+			t.Errorf("%s: decide = %d with reason %q", step.name, got, reason)
+		}
+		if got != step.workers {
+			a.record(types.ScaleEvent{At: now, From: step.workers, To: got, Reason: reason})
+		}
+	}
+
+	ups, downs, events := a.snapshot()
+	if ups != 5 || downs != 3 || len(events) != 8 {
+		t.Errorf("snapshot = %d ups, %d downs, %d events; want 5, 3, 8", ups, downs, len(events))
+	}
+}
+
+func TestAutoscalerP95(t *testing.T) {
+	a := newAutoscaler(types.AutoscaleConfig{MaxWorkers: 1})
+	for i := 1; i <= 100; i++ {
+		a.observeWait(time.Duration(i) * time.Millisecond)
+	}
+	if got := a.takeP95(); got != 95*time.Millisecond {
+		t.Errorf("p95 = %v, want 95ms", got)
+	}
+	if got := a.takeP95(); got != 0 {
+		t.Errorf("p95 after reset = %v, want 0", got)
+	}
+}
+
+func TestPoolScalesUpUnderLoad(t *testing.T) {
+	release := make(chan struct{})
+	p := NewPool(types.PoolConfig{
+		WorkerCount: 1,
+		QueueSize:   20,
+		Autoscale:   types.AutoscaleConfig{MinWorkers: 1, MaxWorkers: 4, Interval: 10 * time.Millisecond},
+		Handler: func(ctx context.Context, job types.Job) (interface{}, error) {
+			<-release
+			return nil, nil
+		},
+	})
+	if err := p.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer p.Stop()
+	defer close(release)
+
+	for i := 0; i < 10; i++ {
+		submitAll(t, p, types.Job{ID: string(rune('a' + i))})
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for p.GetWorkerCount() != 4 {
+		if time.Now().After(deadline) {
+			t.Fatalf("worker count = %d, want 4", p.GetWorkerCount())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if events := p.ScaleEvents(); len(events) == 0 || events[0].From != 1 {
+		t.Errorf("scale events = %+v, want a scale-up from 1", events)
+	}
+}
@@ -0,0 +1,125 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/cs-mastery/worker-pool/pkg/types"
+)
+
+// memoryBackend is the default QueueBackend. It records nothing: queued
+// jobs live only in the pool's in-memory queues and are lost with the
+// process.
+type memoryBackend struct{}
+
+// NewMemoryBackend returns a QueueBackend that keeps nothing beyond the
+// pool's own memory
+func NewMemoryBackend() types.QueueBackend {
+	return memoryBackend{}
+}
+
+func (memoryBackend) Append(types.Job, time.Time) error                    { return nil }
+func (memoryBackend) Update(string, types.JobStatus, int, time.Time) error { return nil }
+func (memoryBackend) Ack(string) error                                     { return nil }
+func (memoryBackend) Replay() ([]types.QueueEntry, error)                  { return nil, nil }
+func (memoryBackend) Close() error                                         { return nil }
+
+// durable reports whether a job can be recorded in the queue backend.
+// Jobs whose payload is a function, such as MapReduce tasks and workflow
+// steps, cannot be replayed and are kept in memory only.
+func durable(job types.Job) bool {
+	_, isTask := job.Data.(taskFunc)
+	return !isTask
+}
+
+// persist records a newly accepted job in the queue backend. The job
+// must be rejected if it fails.
+func (p *Pool) persist(job types.Job, runAt time.Time) error {
+	if !durable(job) {
+		return nil
+	}
+	if err := p.backend.Append(job, runAt); err != nil {
+		return fmt.Errorf("recording job %s: %w", job.ID, err)
+	}
+	return nil
+}
+
+// recordState records a job's new state in the queue backend. It is best
+// effort: if it fails, a replay runs the job with an older attempt number.
+func (p *Pool) recordState(job types.Job, status types.JobStatus, runAt time.Time) {
+	if durable(job) {
+		p.backend.Update(job.ID, status, job.Attempt, runAt)
+	}
+}
+
+// acknowledge removes a finished job from the queue backend. ctx is the
+// context the job finished under. Jobs cancelled because the pool is
+// stopping, by Stop or by Drain's deadline, stay recorded so they run
+// again after a restart.
+func (p *Pool) acknowledge(ctx context.Context, result types.JobResult) {
+	stopping := p.retainUnfinished.Load() || ctx.Err() != nil
+	if stopping && errors.Is(result.Error, context.Canceled) {
+		return
+	}
+	p.backend.Ack(result.JobID)
+}
+
+// restore schedules the unfinished jobs read back from the queue backend.
+// They join the queue through the scheduler, which waits for queue slots,
+// and are not recorded again. A job that was running when the backend
+// last recorded it never settled its lease, so it counts as redelivered.
+// A job that could not be read back is failed and dead-lettered; it stays
+// in the backend, so a restart with the problem fixed replays it.
+func (p *Pool) restore(entries []types.QueueEntry) {
+	for _, entry := range entries {
+		job := entry.Job
+		if job.Attempt < 1 {
+			job.Attempt = 1
+		}
+		if entry.Status == types.JobProcessing {
+			job.Deliveries = 1
+		}
+		if entry.Err != nil {
+			p.restoreFailed(job, entry.Err)
+			continue
+		}
+
+		job, err := p.track(job, types.JobScheduled, nil)
+		if err != nil {
+			continue
+		}
+		p.schedule(job, entry.RunAt)
+		if job.Deliveries > 0 {
+			p.metrics.IncrementJobsRedelivered()
+		}
+		p.metrics.IncrementJobsSubmitted()
+		p.metrics.IncrementTypeSubmitted(job.Type)
+		p.metrics.IncrementTenantSubmitted(job.Tenant)
+	}
+}
+
+// restoreFailed records a replayed job that could not be read back as
+// failed and dead-letters it, without acknowledging it in the backend
+func (p *Pool) restoreFailed(job types.Job, err error) {
+	slog.Error("replaying queued job failed",
+		slog.String("job_id", job.ID),
+		slog.String("type", job.Type),
+		slog.Any("error", err))
+
+	job, trackErr := p.track(job, types.JobPending, nil)
+	if trackErr != nil {
+		return
+	}
+	result := types.JobResult{
+		JobID:   job.ID,
+		Error:   err,
+		Attempt: job.Attempt,
+		Errors:  []error{err},
+	}
+	p.jobs.finish(result)
+	p.deadLetter(job, result)
+	p.metrics.IncrementJobsFailed()
+}
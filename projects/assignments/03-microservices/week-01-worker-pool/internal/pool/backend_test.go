@@ -0,0 +1,91 @@
+package pool
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cs-mastery/worker-pool/pkg/types"
+)
+
+// openFileBackend opens a FileBackend in dir, failing the test on error
+func openFileBackend(t *testing.T, dir string) *FileBackend {
+	t.Helper()
+	b, err := NewFileBackend(FileBackendConfig{Dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestStopKeepsUnfinishedJobsForReplay(t *testing.T) {
+	dir := t.TempDir()
+	backend := openFileBackend(t, dir)
+
+	started := make(chan struct{})
+	p := NewPool(types.PoolConfig{
+		WorkerCount:  1,
+		QueueBackend: backend,
+		Handler: func(ctx context.Context, job types.Job) (interface{}, error) {
+			if job.ID == "slow" {
+				close(started)
+				<-ctx.Done()
+				return nil, ctx.Err()
+			}
+			return job.ID, nil
+		},
+	})
+	if err := p.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	submitAll(t, p, types.Job{ID: "quick"})
+	if _, err := p.GetResult(); err != nil {
+		t.Fatal(err)
+	}
+	submitAll(t, p, types.Job{ID: "slow"})
+	<-started
+	submitAll(t, p, types.Job{ID: "queued"})
+
+	// Stop cancels the running job, which must not count as finished
+	if err := p.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	if err := backend.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	backend = openFileBackend(t, dir)
+	defer backend.Close()
+	ran := make(chan string, 3)
+	p = NewPool(types.PoolConfig{
+		WorkerCount:  1,
+		QueueBackend: backend,
+		Handler: func(ctx context.Context, job types.Job) (interface{}, error) {
+			ran <- job.ID
+			return nil, nil
+		},
+	})
+	if err := p.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer p.Stop()
+
+	var replayed []string
+	for len(replayed) < 2 {
+		select {
+		case id := <-ran:
+			replayed = append(replayed, id)
+		case <-time.After(2 * time.Second):
+			t.Fatalf("replayed %v, want slow and queued", replayed)
+		}
+	}
+	if replayed[0] != "slow" || replayed[1] != "queued" {
+		t.Errorf("replayed %v, want [slow queued]", replayed)
+	}
+	select {
+	case id := <-ran:
+		t.Errorf("finished job %s was replayed", id)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
@@ -0,0 +1,389 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cs-mastery/worker-pool/pkg/types"
+)
+
+// Batch errors
+var (
+	ErrEmptyBatch    = errors.New("batch has no jobs")
+	ErrBatchTooLarge = errors.New("batch is larger than the queue")
+	ErrGroupNotFound = errors.New("job group not found")
+
+	// ErrBatchIdempotencyKey is returned by SubmitBatch for a job with an
+	// IdempotencyKey, which batches do not honor
+	ErrBatchIdempotencyKey = errors.New("batch jobs cannot have an idempotency key")
+)
+
+// JobGroup is a handle to a batch of jobs submitted with SubmitBatch.
+// Member results go to the group, never to the pool's shared result
+// queue.
+type JobGroup struct {
+	pool   *Pool
+	id     string
+	jobIDs []string
+	done   chan struct{}
+
+	mu         sync.Mutex
+	pending    int
+	succeeded  int
+	failed     int
+	errors     map[string]error
+	callbacks  []func(types.GroupStatus)
+	createdAt  time.Time
+	finishedAt time.Time
+}
+
+// SubmitBatch queues a batch of jobs as a group, all or nothing: if any
+// job is invalid, its tenant is at MaxQueued or the queue cannot take the
+// whole batch, none of the jobs is queued.
+//
+// Under OverflowBlock SubmitBatch waits, bounded by ctx and
+// SubmitTimeout, until the queue has room for every job; under any other
+// policy a batch that does not fit at once fails with ErrQueueFull, and
+// no job is dropped or run in the caller. A batch is not deduplicated:
+// a job with an IdempotencyKey fails with ErrBatchIdempotencyKey.
+func (p *Pool) SubmitBatch(ctx context.Context, jobs []types.Job) (*JobGroup, error) {
+	if len(jobs) == 0 {
+		return nil, ErrEmptyBatch
+	}
+	if len(jobs) > p.config.QueueSize {
+		return nil, fmt.Errorf("%w: %d jobs, queue size %d", ErrBatchTooLarge, len(jobs), p.config.QueueSize)
+	}
+
+	p.mu.RLock()
+	running, draining, poolCtx := p.running, p.draining, p.ctx
+	p.mu.RUnlock()
+
+	if !running {
+		return nil, ErrPoolNotRunning
+	}
+	if draining {
+		return nil, ErrPoolDraining
+	}
+
+	prepared := make([]types.Job, len(jobs))
+	seen := make(map[string]bool, len(jobs))
+	for i, job := range jobs {
+		if job.IdempotencyKey != "" {
+			return nil, fmt.Errorf("batch job %d: %w", i, ErrBatchIdempotencyKey)
+		}
+		job, err := p.prepare(job)
+		if err != nil {
+			return nil, fmt.Errorf("batch job %d: %w", i, err)
+		}
+		if seen[job.ID] {
+			return nil, fmt.Errorf("batch job %d: %w: %s", i, ErrDuplicateJobID, job.ID)
+		}
+		seen[job.ID] = true
+		job.EnqueuedAt = job.CreatedAt
+		prepared[i] = job
+	}
+
+	g := &JobGroup{
+		pool:      p,
+		id:        fmt.Sprintf("batch-%d", atomic.AddUint64(&p.groupSeq, 1)),
+		done:      make(chan struct{}),
+		pending:   len(prepared),
+		errors:    make(map[string]error),
+		createdAt: time.Now(),
+	}
+
+	// Each step undoes the ones before it if it fails
+	for i, job := range prepared {
+		w := &watcher{fn: g.jobFinished, exclusive: true}
+		tracked, err := p.track(job, types.JobPending, w)
+		if err != nil {
+			p.untrackBatch(prepared[:i])
+			return nil, err
+		}
+		prepared[i] = tracked
+		g.jobIDs = append(g.jobIDs, job.ID)
+	}
+
+	for i, job := range prepared {
+		if err := p.admitTenant(job); err != nil {
+			for _, admitted := range prepared[:i] {
+				p.releaseAdmission(admitted)
+			}
+			p.untrackBatch(prepared)
+			p.metrics.IncrementTenantRejected(job.Tenant)
+			return nil, fmt.Errorf("batch job %s: %w", job.ID, err)
+		}
+	}
+
+	err := p.reserveSlots(ctx, poolCtx, len(prepared))
+	if err == nil {
+		err = p.persistBatch(prepared)
+	}
+	if err != nil {
+		for _, job := range prepared {
+			p.releaseAdmission(job)
+			if errors.Is(err, ErrQueueFull) || errors.Is(err, ErrSubmitTimeout) {
+				p.metrics.IncrementTenantRejected(job.Tenant)
+			}
+		}
+		p.untrackBatch(prepared)
+		return nil, err
+	}
+
+	p.groupsMu.Lock()
+	p.evictGroupsLocked(time.Now())
+	p.groups[g.id] = g
+	p.groupsMu.Unlock()
+
+	for _, job := range prepared {
+		p.metrics.IncrementJobsSubmitted()
+		p.metrics.IncrementTypeSubmitted(job.Type)
+		p.metrics.IncrementTenantSubmitted(job.Tenant)
+		p.enqueue(job, true)
+	}
+	return g, nil
+}
+
+// persistBatch records the jobs of a batch in the queue backend, all or
+// none. On failure it gives back the batch's queue slots.
+func (p *Pool) persistBatch(jobs []types.Job) error {
+	for i, job := range jobs {
+		if err := p.persist(job, time.Time{}); err != nil {
+			for _, recorded := range jobs[:i] {
+				p.backend.Ack(recorded.ID)
+			}
+			for range jobs {
+				<-p.slots
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// untrackBatch forgets the jobs of a batch whose submission failed
+func (p *Pool) untrackBatch(jobs []types.Job) {
+	for _, job := range jobs {
+		p.untrack(job)
+	}
+}
+
+// reserveSlots takes n queue slots or none. One batch reserves at a time,
+// so two partly reserved batches never wait on each other.
+func (p *Pool) reserveSlots(ctx, poolCtx context.Context, n int) error {
+	p.batchMu.Lock()
+	defer p.batchMu.Unlock()
+
+	taken := 0
+	giveBack := func() {
+		for ; taken > 0; taken-- {
+			<-p.slots
+		}
+	}
+
+fill:
+	for taken < n {
+		select {
+		case p.slots <- struct{}{}:
+			taken++
+		default:
+			break fill
+		}
+	}
+	if taken == n {
+		return nil
+	}
+
+	if p.config.OverflowPolicy != types.OverflowBlock {
+		giveBack()
+		p.metrics.IncrementSubmitsRejected()
+		return ErrQueueFull
+	}
+
+	p.metrics.IncrementSubmitsBlocked()
+
+	var timeout <-chan time.Time
+	if p.config.SubmitTimeout > 0 {
+		timer := time.NewTimer(p.config.SubmitTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	for taken < n {
+		select {
+		case p.slots <- struct{}{}:
+			taken++
+		case <-ctx.Done():
+			giveBack()
+			return ctx.Err()
+		case <-poolCtx.Done():
+			giveBack()
+			return ErrPoolNotRunning
+		case <-timeout:
+			giveBack()
+			p.metrics.IncrementSubmitsRejected()
+			return ErrSubmitTimeout
+		}
+	}
+	return nil
+}
+
+// jobFinished records a member's final result and settles the group once
+// every member has finished
+func (g *JobGroup) jobFinished(result types.JobResult) {
+	g.mu.Lock()
+	g.pending--
+	if result.Error != nil {
+		g.failed++
+		g.errors[result.JobID] = result.Error
+	} else {
+		g.succeeded++
+	}
+	if g.pending > 0 {
+		g.mu.Unlock()
+		return
+	}
+
+	g.finishedAt = time.Now()
+	callbacks := g.callbacks
+	g.callbacks = nil
+	status := g.statusLocked()
+	close(g.done)
+	g.mu.Unlock()
+
+	go func() {
+		for _, fn := range callbacks {
+			fn(status)
+		}
+	}()
+	go g.pool.groupFinished(g)
+}
+
+// ID returns the group's ID
+func (g *JobGroup) ID() string {
+	return g.id
+}
+
+// JobIDs returns the IDs of the group's jobs in submission order
+func (g *JobGroup) JobIDs() []string {
+	return append([]string(nil), g.jobIDs...)
+}
+
+// Done returns a channel that is closed once every job has finished
+func (g *JobGroup) Done() <-chan struct{} {
+	return g.done
+}
+
+// Wait blocks until every job in the group has finished or ctx is done
+// and returns the group's final status, or ctx's error if ctx ends first
+func (g *JobGroup) Wait(ctx context.Context) (types.GroupStatus, error) {
+	select {
+	case <-g.done:
+		return g.Status(), nil
+	case <-ctx.Done():
+		return types.GroupStatus{}, ctx.Err()
+	}
+}
+
+// OnComplete registers fn to be called with the group's final status
+// once every job has finished. fn runs in its own goroutine; if the group
+// has already finished it is called straight away.
+func (g *JobGroup) OnComplete(fn func(types.GroupStatus)) {
+	g.mu.Lock()
+	if g.pending > 0 {
+		g.callbacks = append(g.callbacks, fn)
+		g.mu.Unlock()
+		return
+	}
+	status := g.statusLocked()
+	g.mu.Unlock()
+
+	go fn(status)
+}
+
+// Cancel cancels every job in the group that has not finished yet
+func (g *JobGroup) Cancel() {
+	for _, id := range g.jobIDs {
+		g.pool.CancelJob(id)
+	}
+}
+
+// Status returns a snapshot of the group's progress
+func (g *JobGroup) Status() types.GroupStatus {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.statusLocked()
+}
+
+// statusLocked builds the group's status. Caller holds g.mu.
+func (g *JobGroup) statusLocked() types.GroupStatus {
+	errs := make(map[string]error, len(g.errors))
+	for id, err := range g.errors {
+		errs[id] = err
+	}
+	return types.GroupStatus{
+		ID:         g.id,
+		JobIDs:     append([]string(nil), g.jobIDs...),
+		Total:      len(g.jobIDs),
+		Pending:    g.pending,
+		Succeeded:  g.succeeded,
+		Failed:     g.failed,
+		Errors:     errs,
+		CreatedAt:  g.createdAt,
+		FinishedAt: g.finishedAt,
+	}
+}
+
+// GetGroup returns a group submitted with SubmitBatch. Finished groups
+// remain available like finished jobs, for PoolConfig.JobRetention.
+func (p *Pool) GetGroup(id string) (*JobGroup, error) {
+	p.groupsMu.Lock()
+	p.evictGroupsLocked(time.Now())
+	g, ok := p.groups[id]
+	p.groupsMu.Unlock()
+
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrGroupNotFound, id)
+	}
+	return g, nil
+}
+
+// GetGroupStatus returns the status of a group submitted with SubmitBatch
+func (p *Pool) GetGroupStatus(id string) (types.GroupStatus, error) {
+	g, err := p.GetGroup(id)
+	if err != nil {
+		return types.GroupStatus{}, err
+	}
+	return g.Status(), nil
+}
+
+// groupFinished queues a finished group for eviction
+func (p *Pool) groupFinished(g *JobGroup) {
+	p.groupsMu.Lock()
+	defer p.groupsMu.Unlock()
+
+	if p.groups[g.id] != g {
+		return
+	}
+	p.finishedGroups = append(p.finishedGroups, finishedJob{id: g.id, at: g.finishedAt})
+	p.evictGroupsLocked(time.Now())
+}
+
+// evictGroupsLocked drops finished groups past JobRetention or beyond
+// MaxJobRecords. Caller holds groupsMu.
+func (p *Pool) evictGroupsLocked(now time.Time) {
+	for len(p.finishedGroups) > 0 {
+		oldest := p.finishedGroups[0]
+		expired := p.config.JobRetention > 0 && now.Sub(oldest.at) > p.config.JobRetention
+		if !expired && len(p.finishedGroups) <= p.config.MaxJobRecords {
+			return
+		}
+
+		p.finishedGroups = p.finishedGroups[1:]
+		delete(p.groups, oldest.id)
+	}
+}
@@ -0,0 +1,208 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cs-mastery/worker-pool/pkg/types"
+)
+
+// failingBackend fails the Append of one job ID and records every Ack
+type failingBackend struct {
+	memoryBackend
+	failID string
+
+	mu    sync.Mutex
+	acked []string
+}
+
+func (b *failingBackend) Append(job types.Job, _ time.Time) error {
+	if job.ID == b.failID {
+		return errors.New("disk full")
+	}
+	return nil
+}
+
+func (b *failingBackend) Ack(id string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.acked = append(b.acked, id)
+	return nil
+}
+
+// startPausedPool starts a pool whose jobs stay queued, holding their
+// queue slots, until it is resumed
+func startPausedPool(t *testing.T, config types.PoolConfig) *Pool {
+	t.Helper()
+	if config.Handler == nil {
+		config.Handler = func(ctx context.Context, job types.Job) (interface{}, error) {
+			return nil, nil
+		}
+	}
+	p := startPool(t, config)
+	if err := p.Pause(); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+// assertUntracked fails the test if any of ids is known to the pool
+func assertUntracked(t *testing.T, p *Pool, ids ...string) {
+	t.Helper()
+	for _, id := range ids {
+		if _, err := p.GetJobStatus(id); !errors.Is(err, ErrJobNotFound) {
+			t.Errorf("job %s of a rejected batch is tracked: %v", id, err)
+		}
+	}
+}
+
+func TestSubmitBatchTenantRejection(t *testing.T) {
+	p := startPausedPool(t, types.PoolConfig{
+		WorkerCount: 1,
+		Tenants:     map[string]types.TenantConfig{"small": {MaxQueued: 1}},
+	})
+
+	// The second "small" job is over the limit, so the whole batch fails
+	_, err := p.SubmitBatch(context.Background(), []types.Job{
+		{ID: "a", Tenant: "other"},
+		{ID: "b", Tenant: "small"},
+		{ID: "c", Tenant: "small"},
+	})
+	if !errors.Is(err, ErrTenantQueueFull) {
+		t.Fatalf("SubmitBatch = %v, want ErrTenantQueueFull", err)
+	}
+	assertUntracked(t, p, "a", "b", "c")
+	if n := p.GetQueueLength(); n != 0 {
+		t.Errorf("queue length = %d, want 0", n)
+	}
+
+	// Admissions were given back
+	if err := p.Submit(types.Job{ID: "d", Tenant: "small"}); err != nil {
+		t.Errorf("submitting after the rejected batch: %v", err)
+	}
+}
+
+func TestSubmitBatchRollsBackSlots(t *testing.T) {
+	for _, policy := range []types.OverflowPolicy{
+		types.OverflowFailFast, types.OverflowDropOldest, types.OverflowDropNewest, types.OverflowCallerRuns,
+	} {
+		t.Run(fmt.Sprint(policy), func(t *testing.T) {
+			p := startPausedPool(t, types.PoolConfig{WorkerCount: 1, QueueSize: 3, OverflowPolicy: policy})
+			submitAll(t, p, types.Job{ID: "queued"})
+
+			_, err := p.SubmitBatch(context.Background(), []types.Job{{ID: "a"}, {ID: "b"}, {ID: "c"}})
+			if !errors.Is(err, ErrQueueFull) {
+				t.Fatalf("SubmitBatch = %v, want ErrQueueFull", err)
+			}
+			assertUntracked(t, p, "a", "b", "c")
+			if n := len(p.slots); n != 1 {
+				t.Errorf("%d queue slots taken after the rollback, want 1", n)
+			}
+			if n := p.GetQueueLength(); n != 1 {
+				t.Errorf("queue length = %d, want 1: a job was dropped", n)
+			}
+
+			if _, err := p.SubmitBatch(context.Background(), []types.Job{{ID: "a"}, {ID: "b"}}); err != nil {
+				t.Errorf("a batch that fits: %v", err)
+			}
+		})
+	}
+}
+
+func TestSubmitBatchPersistFailure(t *testing.T) {
+	backend := &failingBackend{failID: "c"}
+	p := startPausedPool(t, types.PoolConfig{WorkerCount: 1, QueueSize: 4, QueueBackend: backend})
+
+	_, err := p.SubmitBatch(context.Background(), []types.Job{{ID: "a"}, {ID: "b"}, {ID: "c"}, {ID: "d"}})
+	if err == nil {
+		t.Fatal("SubmitBatch succeeded with a failing backend")
+	}
+	assertUntracked(t, p, "a", "b", "c", "d")
+
+	backend.mu.Lock()
+	acked := backend.acked
+	backend.mu.Unlock()
+	if want := []string{"a", "b"}; fmt.Sprint(acked) != fmt.Sprint(want) {
+		t.Errorf("acked = %v, want the persisted members %v", acked, want)
+	}
+	if n := len(p.slots); n != 0 {
+		t.Errorf("%d queue slots taken after the rollback, want 0", n)
+	}
+}
+
+func TestSubmitBatchRejectsIdempotencyKey(t *testing.T) {
+	p := startPausedPool(t, types.PoolConfig{WorkerCount: 1, IdempotencyWindow: time.Minute})
+
+	_, err := p.SubmitBatch(context.Background(), []types.Job{{ID: "a"}, {ID: "b", IdempotencyKey: "k"}})
+	if !errors.Is(err, ErrBatchIdempotencyKey) {
+		t.Fatalf("SubmitBatch = %v, want ErrBatchIdempotencyKey", err)
+	}
+	assertUntracked(t, p, "a", "b")
+}
+
+func TestJobGroupWaitAndOnComplete(t *testing.T) {
+	p := startPausedPool(t, types.PoolConfig{
+		WorkerCount: 2,
+		Handler: func(ctx context.Context, job types.Job) (interface{}, error) {
+			if job.ID == "bad" {
+				return nil, errors.New("boom")
+			}
+			return job.ID, nil
+		},
+	})
+
+	g, err := p.SubmitBatch(context.Background(), []types.Job{{ID: "a"}, {ID: "bad"}, {ID: "b"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	before := make(chan types.GroupStatus, 1)
+	g.OnComplete(func(status types.GroupStatus) { before <- status })
+
+	// Wait gives up with ctx while the group is unfinished
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := g.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Wait on a paused group = %v, want DeadlineExceeded", err)
+	}
+
+	if err := p.Resume(); err != nil {
+		t.Fatal(err)
+	}
+	status, err := g.Wait(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Total != 3 || status.Pending != 0 || status.Succeeded != 2 || status.Failed != 1 {
+		t.Errorf("status = %+v, want 2 succeeded and 1 failed", status)
+	}
+	if err := status.Errors["bad"]; err == nil || err.Error() != "boom" {
+		t.Errorf("Errors[bad] = %v, want boom", err)
+	}
+	if status.FinishedAt.IsZero() {
+		t.Error("FinishedAt is zero after Wait")
+	}
+
+	// Callbacks registered before and after the end both see the final
+	// status
+	after := make(chan types.GroupStatus, 1)
+	g.OnComplete(func(status types.GroupStatus) { after <- status })
+	for name, ch := range map[string]chan types.GroupStatus{"before": before, "after": after} {
+		select {
+		case got := <-ch:
+			if got.Pending != 0 || got.Succeeded != 2 || got.Failed != 1 {
+				t.Errorf("OnComplete %s = %+v, want the final status", name, got)
+			}
+		case <-time.After(time.Second):
+			t.Errorf("OnComplete registered %s the end was never called", name)
+		}
+	}
+
+	// Members' results go to the group, not the shared queue
+	if n := len(p.resultQueue); n != 0 {
+		t.Errorf("%d batch results in the shared result queue", n)
+	}
+}
@@ -0,0 +1,86 @@
+package pool
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// NoCodecError is returned when a durable backend has no codec for a
+// job's type
+type NoCodecError struct {
+	Type string
+}
+
+func (e *NoCodecError) Error() string {
+	return fmt.Sprintf("no codec registered for job type %q", e.Type)
+}
+
+// Codec converts job payloads to and from bytes so they can be written to
+// disk
+type Codec interface {
+	Encode(data interface{}) ([]byte, error)
+	Decode(raw []byte) (interface{}, error)
+}
+
+// JSONCodec encodes payloads as JSON and decodes them into a T, so a
+// handler asserting job.Data.(T) sees the same type after a replay.
+// JSONCodec[any] decodes into maps, slices and float64s.
+type JSONCodec[T any] struct{}
+
+// Encode marshals data to JSON
+func (JSONCodec[T]) Encode(data interface{}) ([]byte, error) {
+	return json.Marshal(data)
+}
+
+// Decode unmarshals JSON into a T
+func (JSONCodec[T]) Decode(raw []byte) (interface{}, error) {
+	var v T
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// CodecRegistry maps job types to the codecs for their payloads. Types
+// without a codec of their own use the default codec, if one is set.
+type CodecRegistry struct {
+	mu       sync.RWMutex
+	codecs   map[string]Codec
+	fallback Codec
+}
+
+// NewCodecRegistry creates an empty codec registry
+func NewCodecRegistry() *CodecRegistry {
+	return &CodecRegistry{codecs: make(map[string]Codec)}
+}
+
+// Register sets the codec for a job type, replacing any earlier
+// registration. The empty type is for untyped jobs.
+func (r *CodecRegistry) Register(jobType string, codec Codec) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.codecs[jobType] = codec
+}
+
+// SetDefault sets the codec for job types with none registered
+func (r *CodecRegistry) SetDefault(codec Codec) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.fallback = codec
+}
+
+// Lookup returns the codec for a job type, failing with *NoCodecError if
+// there is none
+func (r *CodecRegistry) Lookup(jobType string) (Codec, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if codec, ok := r.codecs[jobType]; ok {
+		return codec, nil
+	}
+	if r.fallback != nil {
+		return r.fallback, nil
+	}
+	return nil, &NoCodecError{Type: jobType}
+}
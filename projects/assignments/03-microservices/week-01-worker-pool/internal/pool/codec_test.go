@@ -0,0 +1,163 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cs-mastery/worker-pool/pkg/types"
+)
+
+type email struct {
+	To      string
+	Subject string
+}
+
+func TestCodecRegistry(t *testing.T) {
+	r := NewCodecRegistry()
+	r.Register("email", JSONCodec[email]{})
+
+	codec, err := r.Lookup("email")
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := codec.Encode(email{To: "a@example.com", Subject: "hi"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := codec.Decode(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := data.(email); !ok || got.To != "a@example.com" || got.Subject != "hi" {
+		t.Errorf("decoded %#v, want the email back", data)
+	}
+
+	var noCodec *NoCodecError
+	if _, err := r.Lookup("sms"); !errors.As(err, &noCodec) || noCodec.Type != "sms" {
+		t.Errorf("Lookup(sms) = %v, want *NoCodecError for sms", err)
+	}
+	r.SetDefault(JSONCodec[any]{})
+	if codec, err := r.Lookup("sms"); err != nil || codec != (JSONCodec[any]{}) {
+		t.Errorf("Lookup(sms) with a default = %v, %v", codec, err)
+	}
+}
+
+func TestFileBackendCodecs(t *testing.T) {
+	dir := t.TempDir()
+	codecs := NewCodecRegistry()
+	codecs.Register("email", JSONCodec[email]{})
+	codecs.Register("count", JSONCodec[int]{})
+	b, err := NewFileBackend(FileBackendConfig{Dir: dir, Codecs: codecs})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var noCodec *NoCodecError
+	if err := b.Append(types.Job{ID: "sms", Type: "sms", Data: "text"}, time.Time{}); !errors.As(err, &noCodec) {
+		t.Errorf("Append without a codec = %v, want *NoCodecError", err)
+	}
+	sent := email{To: "a@example.com", Subject: "hi"}
+	for _, job := range []types.Job{
+		{ID: "mail", Type: "email", Data: sent},
+		{ID: "n", Type: "count", Data: 3},
+		{ID: "empty", Type: "sms"},
+	} {
+		if err := b.Append(job, time.Time{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	b.Close()
+
+	b, err = NewFileBackend(FileBackendConfig{Dir: dir, Codecs: codecs})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+	entries, err := b.Replay()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var data []interface{}
+	for _, entry := range entries {
+		if entry.Err != nil {
+			t.Errorf("job %s: %v", entry.Job.ID, entry.Err)
+		}
+		data = append(data, entry.Job.Data)
+	}
+	if want := []interface{}{sent, 3, nil}; !reflect.DeepEqual(data, want) {
+		t.Errorf("replayed payloads %#v, want %#v", data, want)
+	}
+}
+
+func TestReplayUndecodablePayload(t *testing.T) {
+	dir := t.TempDir()
+	writer := NewCodecRegistry()
+	writer.SetDefault(JSONCodec[any]{})
+	b, err := NewFileBackend(FileBackendConfig{Dir: dir, Codecs: writer})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.Append(types.Job{ID: "bad", Data: "not a number"}, time.Time{})
+	b.Append(types.Job{ID: "good", Data: 7}, time.Time{})
+	b.Close()
+
+	// The reader's codec cannot decode the first payload
+	reader := NewCodecRegistry()
+	reader.Register("", JSONCodec[int]{})
+	config := FileBackendConfig{Dir: dir, Codecs: reader}
+	b, err = NewFileBackend(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, err := b.Replay()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Err == nil || entries[0].Job.Data != nil || entries[1].Err != nil {
+		t.Fatalf("entries = %+v, want bad with an error and good decoded", entries)
+	}
+
+	ran := make(chan types.Job, 2)
+	p := NewPool(types.PoolConfig{
+		WorkerCount:    1,
+		DeadLetterSize: 10,
+		QueueBackend:   b,
+		Handler: func(ctx context.Context, job types.Job) (interface{}, error) {
+			ran <- job
+			return nil, nil
+		},
+	})
+	if err := p.Start(); err != nil {
+		t.Fatalf("Start with an undecodable job: %v", err)
+	}
+
+	select {
+	case job := <-ran:
+		if job.ID != "good" || job.Data != 7 {
+			t.Errorf("ran %s with %v, want good with 7", job.ID, job.Data)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("the decodable job never ran")
+	}
+	if letter, ok := p.GetDeadLetter("bad"); !ok || letter.Result.Error == nil {
+		t.Errorf("dead letter for bad = %+v, %v; want one with the decode error", letter, ok)
+	}
+	if record, err := p.GetJobStatus("bad"); err != nil || record.Status != types.JobFailed {
+		t.Errorf("bad status = %v, %v; want failed", record.Status, err)
+	}
+	p.Stop()
+	b.Close()
+
+	// The undecodable job is kept for a restart with the right codec
+	b, err = NewFileBackend(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+	if got := replayIDs(t, b); !reflect.DeepEqual(got, []string{"bad"}) {
+		t.Errorf("replayed %v, want [bad]", got)
+	}
+}
@@ -0,0 +1,236 @@
+package pool
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronSchedule computes the fire times of a recurring job
+type cronSchedule interface {
+	// next returns the first fire time strictly after t, or the zero time
+	// if there is none
+	next(t time.Time) time.Time
+}
+
+// everySchedule fires at a fixed interval ("@every 30s")
+type everySchedule struct {
+	interval time.Duration
+}
+
+func (s everySchedule) next(t time.Time) time.Time {
+	return t.Add(s.interval)
+}
+
+// cronSpec is a parsed 5-field cron expression. Each field is a bitset of
+// the values it matches.
+type cronSpec struct {
+	minute, hour, dom, month, dow uint64
+	domStar, dowStar              bool
+}
+
+// cronField describes the bounds and names allowed in one cron field
+type cronField struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var (
+	minuteField = cronField{name: "minute", min: 0, max: 59}
+	hourField   = cronField{name: "hour", min: 0, max: 23}
+	domField    = cronField{name: "day of month", min: 1, max: 31}
+	monthField  = cronField{name: "month", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	dowField = cronField{name: "day of week", min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+// cronDescriptors maps shorthand specs to their 5-field equivalent
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// parseCron parses a standard 5-field cron expression
+// (minute hour day-of-month month day-of-week), a descriptor such as
+// @daily, or "@every <duration>"
+func parseCron(spec string) (cronSchedule, error) {
+	spec = strings.TrimSpace(spec)
+
+	if strings.HasPrefix(spec, "@every ") {
+		interval, err := time.ParseDuration(strings.TrimSpace(strings.TrimPrefix(spec, "@every ")))
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron spec %q: %w", spec, err)
+		}
+		if interval <= 0 {
+			return nil, fmt.Errorf("invalid cron spec %q: interval must be positive", spec)
+		}
+		return everySchedule{interval: interval}, nil
+	}
+	if expanded, ok := cronDescriptors[strings.ToLower(spec)]; ok {
+		spec = expanded
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid cron spec %q: expected 5 fields, got %d", spec, len(fields))
+	}
+
+	var s cronSpec
+	var err error
+	if s.minute, err = minuteField.parse(fields[0]); err != nil {
+		return nil, err
+	}
+	if s.hour, err = hourField.parse(fields[1]); err != nil {
+		return nil, err
+	}
+	if s.dom, err = domField.parse(fields[2]); err != nil {
+		return nil, err
+	}
+	if s.month, err = monthField.parse(fields[3]); err != nil {
+		return nil, err
+	}
+	if s.dow, err = dowField.parse(fields[4]); err != nil {
+		return nil, err
+	}
+
+	// 7 is an alias for Sunday
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+	s.domStar = strings.HasPrefix(fields[2], "*")
+	s.dowStar = strings.HasPrefix(fields[4], "*")
+
+	return &s, nil
+}
+
+// parse converts a comma-separated list of values, ranges and steps into
+// a bitset
+func (f cronField) parse(expr string) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(expr, ",") {
+		partBits, err := f.parsePart(part)
+		if err != nil {
+			return 0, fmt.Errorf("invalid %s field %q: %w", f.name, expr, err)
+		}
+		bits |= partBits
+	}
+	return bits, nil
+}
+
+// parsePart parses one list element: "*", "N", "N-M", each optionally
+// followed by "/step"
+func (f cronField) parsePart(part string) (uint64, error) {
+	rangeExpr, stepExpr, hasStep := strings.Cut(part, "/")
+
+	step := 1
+	if hasStep {
+		var err error
+		step, err = strconv.Atoi(stepExpr)
+		if err != nil || step <= 0 {
+			return 0, fmt.Errorf("bad step %q", stepExpr)
+		}
+	}
+
+	var start, end int
+	switch {
+	case rangeExpr == "*":
+		start, end = f.min, f.max
+	case strings.Contains(rangeExpr, "-"):
+		lo, hi, _ := strings.Cut(rangeExpr, "-")
+		var err error
+		if start, err = f.value(lo); err != nil {
+			return 0, err
+		}
+		if end, err = f.value(hi); err != nil {
+			return 0, err
+		}
+	default:
+		var err error
+		if start, err = f.value(rangeExpr); err != nil {
+			return 0, err
+		}
+		end = start
+		// "N/step" means N through the maximum
+		if hasStep {
+			end = f.max
+		}
+	}
+
+	if start > end {
+		return 0, fmt.Errorf("range %d-%d is reversed", start, end)
+	}
+
+	var bits uint64
+	for v := start; v <= end; v += step {
+		bits |= 1 << uint(v)
+	}
+	return bits, nil
+}
+
+// value parses a single number or name and checks it is in bounds
+func (f cronField) value(s string) (int, error) {
+	if v, ok := f.names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("bad value %q", s)
+	}
+	if v < f.min || v > f.max {
+		return 0, fmt.Errorf("value %d out of range %d-%d", v, f.min, f.max)
+	}
+	return v, nil
+}
+
+// next returns the first minute after t that matches every field. It
+// gives up after five years, which only happens for impossible dates such
+// as "0 0 30 2 *".
+func (s *cronSpec) next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// dayMatches applies the cron day rule: when both day-of-month and
+// day-of-week are restricted, matching either one is enough
+func (s *cronSpec) dayMatches(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+
+	if s.domStar || s.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
@@ -0,0 +1,132 @@
+package pool
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cs-mastery/worker-pool/pkg/types"
+)
+
+func TestCronNext(t *testing.T) {
+	// A Monday
+	from := time.Date(2024, time.January, 15, 10, 7, 30, 0, time.UTC)
+
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{"*/15 * * * *", time.Date(2024, time.January, 15, 10, 15, 0, 0, time.UTC)},
+		{"0 9 * * 1-5", time.Date(2024, time.January, 16, 9, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2024, time.January, 16, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2024, time.January, 15, 11, 0, 0, 0, time.UTC)},
+		{"30 12 1 * *", time.Date(2024, time.February, 1, 12, 30, 0, 0, time.UTC)},
+		{"0 0 1 jan-mar/2 *", time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2024, time.January, 21, 0, 0, 0, 0, time.UTC)},
+		// Day of month and day of week both restricted: either matches
+		{"0 0 13 * fri", time.Date(2024, time.January, 19, 0, 0, 0, 0, time.UTC)},
+		{"@every 90s", from.Add(90 * time.Second)},
+		{"0 0 30 2 *", time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			schedule, err := parseCron(tt.spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := schedule.next(from); !got.Equal(tt.want) {
+				t.Errorf("next(%v) = %v, want %v", from, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCronInvalidSpecs(t *testing.T) {
+	for _, spec := range []string{
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"* * * foo *",
+		"@every -1s",
+		"@every soon",
+	} {
+		if _, err := parseCron(spec); err == nil {
+			t.Errorf("parseCron(%q) succeeded, want an error", spec)
+		}
+	}
+}
+
+func TestRecurringJobSkipsOverlappingRuns(t *testing.T) {
+	var running, maxRunning atomic.Int32
+	p := NewPool(types.PoolConfig{
+		WorkerCount: 4,
+		Handler: func(ctx context.Context, job types.Job) (interface{}, error) {
+			n := running.Add(1)
+			defer running.Add(-1)
+			for {
+				max := maxRunning.Load()
+				if n <= max || maxRunning.CompareAndSwap(max, n) {
+					break
+				}
+			}
+			time.Sleep(50 * time.Millisecond)
+			return nil, nil
+		},
+	})
+	if err := p.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer p.Stop()
+	go func() {
+		for {
+			if _, err := p.GetResult(); err != nil {
+				return
+			}
+		}
+	}()
+
+	err := p.AddRecurringJob(types.RecurringJob{Name: "tick", Spec: "@every 10ms", Overlap: types.OverlapSkip})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.AddRecurringJob(types.RecurringJob{Name: "tick", Spec: "@every 1s"}); err == nil {
+		t.Error("registering a name twice succeeded")
+	}
+
+	time.Sleep(200 * time.Millisecond)
+	infos := p.ListRecurringJobs()
+	if len(infos) != 1 || infos[0].Runs == 0 || infos[0].Skipped == 0 {
+		t.Errorf("schedules = %+v, want tick with both runs and skipped runs", infos)
+	}
+	if !p.RemoveRecurringJob("tick") {
+		t.Fatal("RemoveRecurringJob found no schedule")
+	}
+
+	if n := maxRunning.Load(); n != 1 {
+		t.Errorf("%d runs overlapped, want 1 at a time", n)
+	}
+	if infos = p.ListRecurringJobs(); len(infos) != 0 {
+		t.Errorf("schedules after removal = %v, want none", infos)
+	}
+}
+
+func TestRecurringJobInfo(t *testing.T) {
+	p := NewPool(types.PoolConfig{WorkerCount: 1})
+	if err := p.AddRecurringJob(types.RecurringJob{Name: "nightly", Spec: "@daily"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.AddRecurringJob(types.RecurringJob{Name: "bad", Spec: "not a spec"}); err == nil {
+		t.Error("registering an invalid spec succeeded")
+	}
+
+	infos := p.ListRecurringJobs()
+	if len(infos) != 1 || infos[0].Name != "nightly" {
+		t.Fatalf("schedules = %v, want nightly", infos)
+	}
+	if infos[0].NextRun.IsZero() || !infos[0].NextRun.After(time.Now()) {
+		t.Errorf("NextRun = %v, want a time in the future", infos[0].NextRun)
+	}
+}
@@ -0,0 +1,172 @@
+package pool
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/cs-mastery/worker-pool/pkg/types"
+)
+
+// deadLetterQueue holds jobs that failed after exhausting their retries.
+// It is bounded; when full the oldest entry is evicted.
+type deadLetterQueue struct {
+	mu      sync.Mutex
+	order   []string
+	entries map[string]types.DeadLetter
+	max     int
+}
+
+// newDeadLetterQueue creates a dead-letter queue holding at most max jobs
+func newDeadLetterQueue(max int) *deadLetterQueue {
+	return &deadLetterQueue{
+		entries: make(map[string]types.DeadLetter),
+		max:     max,
+	}
+}
+
+// add stores a dead letter, replacing any earlier entry for the same job
+func (q *deadLetterQueue) add(letter types.DeadLetter) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	id := letter.Job.ID
+	if _, exists := q.entries[id]; exists {
+		q.removeLocked(id)
+	}
+	for len(q.order) >= q.max {
+		q.removeLocked(q.order[0])
+	}
+
+	q.order = append(q.order, id)
+	q.entries[id] = letter
+}
+
+// list returns all dead letters, oldest first
+func (q *deadLetterQueue) list() []types.DeadLetter {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	letters := make([]types.DeadLetter, 0, len(q.order))
+	for _, id := range q.order {
+		letters = append(letters, q.entries[id])
+	}
+	return letters
+}
+
+// get returns the dead letter for a job
+func (q *deadLetterQueue) get(id string) (types.DeadLetter, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	letter, ok := q.entries[id]
+	return letter, ok
+}
+
+// take removes and returns the dead letter for a job
+func (q *deadLetterQueue) take(id string) (types.DeadLetter, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	letter, ok := q.entries[id]
+	if ok {
+		q.removeLocked(id)
+	}
+	return letter, ok
+}
+
+// purge removes every dead letter and returns how many were removed
+func (q *deadLetterQueue) purge() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	n := len(q.order)
+	q.order = nil
+	q.entries = make(map[string]types.DeadLetter)
+	return n
+}
+
+// len returns the number of dead letters
+func (q *deadLetterQueue) len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.order)
+}
+
+func (q *deadLetterQueue) removeLocked(id string) {
+	delete(q.entries, id)
+	for i, queued := range q.order {
+		if queued == id {
+			q.order = append(q.order[:i], q.order[i+1:]...)
+			return
+		}
+	}
+}
+
+// deadLetter moves a finally-failed job to the dead-letter queue
+func (p *Pool) deadLetter(job types.Job, result types.JobResult) {
+	if p.deadLetters == nil {
+		return
+	}
+
+	p.deadLetters.add(types.DeadLetter{
+		Job:      job,
+		Result:   result,
+		FailedAt: time.Now(),
+	})
+}
+
+// ListDeadLetters returns the jobs that exhausted their retries, oldest
+// first
+func (p *Pool) ListDeadLetters() []types.DeadLetter {
+	if p.deadLetters == nil {
+		return nil
+	}
+	return p.deadLetters.list()
+}
+
+// GetDeadLetter returns the dead-lettered job with the given ID
+func (p *Pool) GetDeadLetter(id string) (types.DeadLetter, bool) {
+	if p.deadLetters == nil {
+		return types.DeadLetter{}, false
+	}
+	return p.deadLetters.get(id)
+}
+
+// RequeueDeadLetter removes a job from the dead-letter queue and submits
+// it again with a fresh attempt count
+func (p *Pool) RequeueDeadLetter(ctx context.Context, id string) error {
+	if p.deadLetters == nil {
+		return fmt.Errorf("dead letter %s not found", id)
+	}
+
+	letter, ok := p.deadLetters.take(id)
+	if !ok {
+		return fmt.Errorf("dead letter %s not found", id)
+	}
+
+	// The job's context ended with its last attempt
+	job := letter.Job
+	job.Context = nil
+	job.Attempt = 0
+	job.AttemptErrors = nil
+	job.RetryDelay = 0
+	job.Deliveries = 0
+
+	if err := p.SubmitWithContext(ctx, job); err != nil {
+		// Put it back so the job is not lost
+		p.deadLetters.add(letter)
+		return err
+	}
+	return nil
+}
+
+// PurgeDeadLetters discards every dead-lettered job and returns how many
+// were discarded
+func (p *Pool) PurgeDeadLetters() int {
+	if p.deadLetters == nil {
+		return 0
+	}
+	return p.deadLetters.purge()
+}
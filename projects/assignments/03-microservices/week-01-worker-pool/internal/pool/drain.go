@@ -0,0 +1,103 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"github.com/cs-mastery/worker-pool/pkg/types"
+)
+
+// ErrPoolDraining is returned by submissions while the pool is draining
+var ErrPoolDraining = errors.New("pool is draining")
+
+// Drain shuts the pool down in two phases. It first rejects new
+// submissions with ErrPoolDraining and lets queued, scheduled, retrying
+// and running jobs finish. Once ctx is done it cancels every job still
+// left, stops the pool and reports which jobs completed, which were
+// cancelled while running and which never started.
+func (p *Pool) Drain(ctx context.Context) (types.DrainReport, error) {
+	p.mu.Lock()
+	if !p.running {
+		p.mu.Unlock()
+		return types.DrainReport{}, ErrPoolNotRunning
+	}
+	if p.draining {
+		p.mu.Unlock()
+		return types.DrainReport{}, ErrPoolDraining
+	}
+	p.draining = true
+	p.mu.Unlock()
+
+	defer func() {
+		p.mu.Lock()
+		p.draining = false
+		p.mu.Unlock()
+	}()
+
+	// Jobs active now are the ones the report covers; nothing new can be
+	// submitted from here on
+	started, notStarted := p.jobs.active()
+	ids := append(started, notStarted...)
+
+	// Phase one: wait for every active job to finish or for the deadline
+wait:
+	for {
+		started, notStarted = p.jobs.active()
+		if len(started)+len(notStarted) == 0 {
+			break
+		}
+
+		select {
+		case <-p.jobs.changed:
+		case <-ctx.Done():
+			break wait
+		}
+	}
+
+	// Phase two: cancel whatever is left and stop. The queue backend
+	// keeps these jobs so they run again after a restart.
+	p.retainUnfinished.Store(true)
+	defer p.retainUnfinished.Store(false)
+	started, notStarted = p.jobs.active()
+	for _, id := range append(started, notStarted...) {
+		p.CancelJob(id)
+	}
+	err := p.Stop()
+
+	return newDrainReport(ids, started, notStarted), err
+}
+
+// IsDraining returns whether Drain is in progress
+func (p *Pool) IsDraining() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.draining
+}
+
+// newDrainReport sorts the jobs active when draining began into the
+// report's buckets, given the jobs still started or not started at the
+// deadline
+func newDrainReport(ids, cancelled, notStarted []string) types.DrainReport {
+	left := make(map[string]bool, len(cancelled)+len(notStarted))
+	for _, id := range cancelled {
+		left[id] = true
+	}
+	for _, id := range notStarted {
+		left[id] = true
+	}
+
+	var report types.DrainReport
+	for _, id := range ids {
+		if !left[id] {
+			report.Completed = append(report.Completed, id)
+		}
+	}
+	report.Cancelled = cancelled
+	report.NotStarted = notStarted
+
+	sort.Strings(report.Completed)
+	sort.Strings(report.Cancelled)
+	sort.Strings(report.NotStarted)
+	return report
+}
@@ -0,0 +1,97 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cs-mastery/worker-pool/pkg/types"
+)
+
+func TestDrainReport(t *testing.T) {
+	started := make(chan struct{}, 2)
+	p := NewPool(types.PoolConfig{
+		WorkerCount: 2,
+		Handler: func(ctx context.Context, job types.Job) (interface{}, error) {
+			started <- struct{}{}
+			if job.ID == "quick" {
+				time.Sleep(20 * time.Millisecond)
+				return nil, nil
+			}
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+	})
+	if err := p.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer p.Stop()
+
+	submitAll(t, p, types.Job{ID: "quick"}, types.Job{ID: "slow"})
+	<-started
+	<-started
+	if err := p.SubmitAfter(types.Job{ID: "later"}, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	done := make(chan types.DrainReport)
+	go func() {
+		report, err := p.Drain(ctx)
+		if err != nil {
+			t.Error(err)
+		}
+		done <- report
+	}()
+
+	for !p.IsDraining() {
+		time.Sleep(time.Millisecond)
+	}
+	if err := p.Submit(types.Job{ID: "rejected"}); !errors.Is(err, ErrPoolDraining) {
+		t.Errorf("Submit while draining = %v, want ErrPoolDraining", err)
+	}
+	if _, err := p.Drain(ctx); !errors.Is(err, ErrPoolDraining) {
+		t.Errorf("second Drain = %v, want ErrPoolDraining", err)
+	}
+
+	report := <-done
+	want := types.DrainReport{
+		Completed:  []string{"quick"},
+		Cancelled:  []string{"slow"},
+		NotStarted: []string{"later"},
+	}
+	if !reflect.DeepEqual(report, want) {
+		t.Errorf("report = %+v, want %+v", report, want)
+	}
+	if p.IsRunning() {
+		t.Error("pool still running after Drain")
+	}
+}
+
+func TestDrainFinishesBeforeDeadline(t *testing.T) {
+	p := NewPool(types.PoolConfig{
+		WorkerCount: 1,
+		Handler: func(ctx context.Context, job types.Job) (interface{}, error) {
+			return nil, nil
+		},
+	})
+	if err := p.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	submitAll(t, p, types.Job{ID: "a"}, types.Job{ID: "b"})
+	report, err := p.Drain(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(report.Completed, []string{"a", "b"}) || len(report.Cancelled)+len(report.NotStarted) != 0 {
+		t.Errorf("report = %+v, want a and b completed", report)
+	}
+
+	if _, err := p.Drain(context.Background()); !errors.Is(err, ErrPoolNotRunning) {
+		t.Errorf("Drain on a stopped pool = %v, want ErrPoolNotRunning", err)
+	}
+}
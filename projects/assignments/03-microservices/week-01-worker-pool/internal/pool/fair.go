@@ -0,0 +1,278 @@
+package pool
+
+import (
+	"container/heap"
+	"errors"
+	"time"
+
+	"github.com/cs-mastery/worker-pool/pkg/types"
+)
+
+// ErrTenantQueueFull is returned when a tenant already has
+// TenantConfig.MaxQueued jobs waiting
+var ErrTenantQueueFull = errors.New("tenant queue is full")
+
+// tenantQueue holds one tenant's ready jobs and dispatch state
+type tenantQueue struct {
+	name     string
+	config   types.TenantConfig
+	ready    *priorityQueue
+	deficit  int  // Dispatches left in the tenant's current turn
+	inFlight int  // Jobs handed to workers and not finished yet
+	reserved int  // Admitted submissions not queued yet
+	active   bool // In the round, i.e. has ready jobs
+}
+
+// atLimit reports whether the tenant has MaxInFlight jobs running
+func (tq *tenantQueue) atLimit() bool {
+	return tq.config.MaxInFlight > 0 && tq.inFlight >= tq.config.MaxInFlight
+}
+
+// fairQueue is the pool's ready queue. Each tenant has its own priority
+// queue, and tenants with ready jobs take turns by deficit round robin:
+// every job costs one unit and a tenant's turn is worth Weight units, so
+// over a round each tenant dispatches in proportion to its weight however
+// many jobs it has queued. Tenants at MaxInFlight give up their turn.
+//
+// fairQueue is not safe for concurrent use; the pool guards it with
+// queueMu.
+type fairQueue struct {
+	tenants       map[string]*tenantQueue
+	round         []*tenantQueue // Tenants with ready jobs, in turn order
+	current       int            // Index in round of the tenant whose turn it is
+	configs       map[string]types.TenantConfig
+	defaults      types.TenantConfig
+	agingInterval time.Duration
+}
+
+// newFairQueue creates an empty fair queue. Tenants missing from configs
+// use defaults.
+func newFairQueue(configs map[string]types.TenantConfig, defaults types.TenantConfig, agingInterval time.Duration) *fairQueue {
+	return &fairQueue{
+		tenants:       make(map[string]*tenantQueue),
+		configs:       configs,
+		defaults:      defaults,
+		agingInterval: agingInterval,
+	}
+}
+
+// tenant returns the queue for a tenant, creating it if needed
+func (q *fairQueue) tenant(name string) *tenantQueue {
+	tq, ok := q.tenants[name]
+	if !ok {
+		config, ok := q.configs[name]
+		if !ok {
+			config = q.defaults
+		}
+		if config.Weight <= 0 {
+			config.Weight = 1
+		}
+		tq = &tenantQueue{name: name, config: config, ready: newPriorityQueue(q.agingInterval)}
+		q.tenants[name] = tq
+	}
+	return tq
+}
+
+// Len returns the number of ready jobs across all tenants
+func (q *fairQueue) Len() int {
+	n := 0
+	for _, tq := range q.round {
+		n += tq.ready.Len()
+	}
+	return n
+}
+
+// push adds a job to its tenant's queue
+func (q *fairQueue) push(item *queueItem) {
+	tq := q.tenant(item.job.Tenant)
+	heap.Push(tq.ready, item)
+	if !tq.active {
+		tq.active = true
+		q.round = append(q.round, tq)
+	}
+}
+
+// next returns the highest-priority job of the tenant whose turn it is,
+// or nil if every tenant with ready jobs is at MaxInFlight. It does not
+// remove the job.
+func (q *fairQueue) next() *queueItem {
+	for i := 0; i < len(q.round); i++ {
+		tq := q.round[q.current]
+		if !tq.atLimit() {
+			if tq.deficit <= 0 {
+				tq.deficit = tq.config.Weight
+			}
+			return tq.ready.peek()
+		}
+		tq.deficit = 0
+		q.advance()
+	}
+	return nil
+}
+
+// take removes a job that is being handed to a worker, charging it to
+// its tenant's turn and in-flight count
+func (q *fairQueue) take(item *queueItem) {
+	tq := q.tenants[item.job.Tenant]
+	heap.Remove(tq.ready, item.index)
+	tq.inFlight++
+	tq.deficit--
+
+	switch {
+	case tq.ready.Len() == 0:
+		q.deactivate(tq)
+	case tq.deficit <= 0 && q.round[q.current] == tq:
+		q.advance()
+	}
+}
+
+// remove drops a job that leaves the queue without being dispatched
+func (q *fairQueue) remove(item *queueItem) {
+	tq := q.tenants[item.job.Tenant]
+	heap.Remove(tq.ready, item.index)
+	if tq.ready.Len() == 0 {
+		q.deactivate(tq)
+	}
+}
+
+// each calls fn for every ready job
+func (q *fairQueue) each(fn func(item *queueItem)) {
+	for _, tq := range q.round {
+		for _, item := range tq.ready.items {
+			fn(item)
+		}
+	}
+}
+
+// acquire counts a job that bypasses the queue against its tenant's
+// in-flight jobs
+func (q *fairQueue) acquire(tenant string) {
+	q.tenant(tenant).inFlight++
+}
+
+// release marks one of a tenant's in-flight jobs as finished. It reports
+// whether the tenant may have been holding back ready jobs.
+func (q *fairQueue) release(tenant string) bool {
+	tq, ok := q.tenants[tenant]
+	if !ok {
+		return false
+	}
+	tq.inFlight--
+	q.tidy(tq)
+	return tq.active && tq.config.MaxInFlight > 0
+}
+
+// advance passes the turn to the next tenant
+func (q *fairQueue) advance() {
+	if len(q.round) > 0 {
+		q.current = (q.current + 1) % len(q.round)
+	}
+}
+
+// deactivate takes a tenant without ready jobs out of the round
+func (q *fairQueue) deactivate(tq *tenantQueue) {
+	for i, t := range q.round {
+		if t == tq {
+			q.round = append(q.round[:i], q.round[i+1:]...)
+			if i < q.current {
+				q.current--
+			}
+			break
+		}
+	}
+	if q.current >= len(q.round) {
+		q.current = 0
+	}
+	tq.active = false
+	tq.deficit = 0
+	q.tidy(tq)
+}
+
+// tidy forgets a tenant with nothing queued, reserved or in flight
+func (q *fairQueue) tidy(tq *tenantQueue) {
+	if !tq.active && tq.inFlight == 0 && tq.reserved == 0 {
+		delete(q.tenants, tq.name)
+	}
+}
+
+// tenantUsage is a tenant's current queue depth and in-flight count
+type tenantUsage struct {
+	queued   int
+	inFlight int
+}
+
+// admitTenant reserves room in a job's tenant queue, failing with
+// ErrTenantQueueFull if the tenant is at MaxQueued. The reservation
+// passes to the job when it is enqueued; otherwise it must be given back
+// with releaseAdmission.
+func (p *Pool) admitTenant(job types.Job) error {
+	p.queueMu.Lock()
+	defer p.queueMu.Unlock()
+
+	tq := p.queue.tenant(job.Tenant)
+	if limit := tq.config.MaxQueued; limit > 0 {
+		if tq.ready.Len()+tq.reserved+p.parkedTenantLocked(job.Tenant) >= limit {
+			p.queue.tidy(tq)
+			return ErrTenantQueueFull
+		}
+	}
+	tq.reserved++
+	return nil
+}
+
+// releaseAdmission gives back a reservation taken by admitTenant
+func (p *Pool) releaseAdmission(job types.Job) {
+	p.queueMu.Lock()
+	defer p.queueMu.Unlock()
+	p.unreserveLocked(job.Tenant)
+}
+
+// unreserveLocked drops one of a tenant's reservations. Caller holds
+// queueMu.
+func (p *Pool) unreserveLocked(tenant string) {
+	if tq, ok := p.queue.tenants[tenant]; ok {
+		tq.reserved--
+		p.queue.tidy(tq)
+	}
+}
+
+// releaseTenant marks an attempt as no longer in flight for its tenant
+// and wakes the dispatcher if the tenant was held back by MaxInFlight
+func (p *Pool) releaseTenant(job types.Job) {
+	p.queueMu.Lock()
+	wake := p.queue.release(job.Tenant)
+	p.queueMu.Unlock()
+
+	if wake {
+		p.wakeDispatcher()
+	}
+}
+
+// parkedTenantLocked counts a tenant's jobs held back by job type or
+// partition key caps. Caller holds queueMu.
+func (p *Pool) parkedTenantLocked(tenant string) int {
+	n := 0
+	p.eachParkedLocked(func(item *queueItem) {
+		if item.job.Tenant == tenant {
+			n++
+		}
+	})
+	return n
+}
+
+// tenantUsage returns the queue depth and in-flight count per tenant
+func (p *Pool) tenantUsage() map[string]tenantUsage {
+	p.queueMu.Lock()
+	defer p.queueMu.Unlock()
+
+	usage := make(map[string]tenantUsage, len(p.queue.tenants))
+	for name, tq := range p.queue.tenants {
+		usage[name] = tenantUsage{queued: tq.ready.Len(), inFlight: tq.inFlight}
+	}
+	p.eachParkedLocked(func(item *queueItem) {
+		u := usage[item.job.Tenant]
+		u.queued++
+		usage[item.job.Tenant] = u
+	})
+	return usage
+}
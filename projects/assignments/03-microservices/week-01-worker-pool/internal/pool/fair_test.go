@@ -0,0 +1,114 @@
+package pool
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/cs-mastery/worker-pool/pkg/types"
+)
+
+// pushJobs adds n jobs of a tenant to q
+func pushJobs(q *fairQueue, tenant string, n int) {
+	for i := 0; i < n; i++ {
+		q.push(&queueItem{job: types.Job{ID: fmt.Sprintf("%s-%d", tenant, i), Tenant: tenant}})
+	}
+}
+
+// dispatchTenants takes up to n jobs from q, finishing each straight
+// away, and returns their tenants in dispatch order
+func dispatchTenants(q *fairQueue, n int) []string {
+	var tenants []string
+	for i := 0; i < n; i++ {
+		item := q.next()
+		if item == nil {
+			break
+		}
+		q.take(item)
+		q.release(item.job.Tenant)
+		tenants = append(tenants, item.job.Tenant)
+	}
+	return tenants
+}
+
+func TestFairQueueWeightedTurns(t *testing.T) {
+	q := newFairQueue(map[string]types.TenantConfig{"gold": {Weight: 3}}, types.TenantConfig{}, 0)
+	pushJobs(q, "gold", 10)
+	pushJobs(q, "free", 10)
+
+	got := dispatchTenants(q, 8)
+	want := []string{"gold", "gold", "gold", "free", "gold", "gold", "gold", "free"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("dispatch order = %v, want %v", got, want)
+	}
+
+	// Once gold runs dry, free gets every turn
+	got = dispatchTenants(q, 12)
+	want = []string{"gold", "gold", "gold", "free", "gold", "free", "free", "free", "free", "free", "free", "free"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("dispatch order = %v, want %v", got, want)
+	}
+	if q.Len() != 0 || len(q.tenants) != 0 {
+		t.Errorf("%d jobs and %d tenants left, want none", q.Len(), len(q.tenants))
+	}
+}
+
+func TestFairQueueMaxInFlight(t *testing.T) {
+	q := newFairQueue(map[string]types.TenantConfig{"limited": {MaxInFlight: 1}}, types.TenantConfig{}, 0)
+	pushJobs(q, "limited", 2)
+	pushJobs(q, "other", 2)
+
+	// The limited tenant's running job makes it give up its turns
+	var got []string
+	for item := q.next(); item != nil; item = q.next() {
+		q.take(item)
+		got = append(got, item.job.ID)
+	}
+	want := []string{"limited-0", "other-0", "other-1"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("dispatched %v, want %v", got, want)
+	}
+
+	if !q.release("limited") {
+		t.Error("release did not report the held back tenant")
+	}
+	if item := q.next(); item == nil || item.job.ID != "limited-1" {
+		t.Errorf("next after release = %v, want limited-1", item)
+	}
+}
+
+func TestTenantMaxQueued(t *testing.T) {
+	p := NewPool(types.PoolConfig{
+		WorkerCount:   1,
+		EnableMetrics: true,
+		Tenants:       map[string]types.TenantConfig{"small": {MaxQueued: 2}},
+	})
+	if err := p.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer p.Stop()
+	if err := p.Pause(); err != nil {
+		t.Fatal(err)
+	}
+
+	submitAll(t, p, types.Job{ID: "a", Tenant: "small"}, types.Job{ID: "b", Tenant: "small"})
+	if err := p.Submit(types.Job{ID: "c", Tenant: "small"}); !errors.Is(err, ErrTenantQueueFull) {
+		t.Errorf("Submit over MaxQueued = %v, want ErrTenantQueueFull", err)
+	}
+	// Other tenants have their own limits
+	submitAll(t, p, types.Job{ID: "d", Tenant: "big"}, types.Job{ID: "e"})
+
+	// Cancelling a queued job makes room again
+	if err := p.CancelJob("a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Submit(types.Job{ID: "c", Tenant: "small"}); err != nil {
+		t.Errorf("Submit after cancelling: %v", err)
+	}
+
+	small := p.GetMetrics().Tenants["small"]
+	if small.Queued != 2 || small.Submitted != 3 || small.Rejected != 1 {
+		t.Errorf("small metrics = %+v, want 2 queued, 3 submitted, 1 rejected", small)
+	}
+}
@@ -0,0 +1,93 @@
+package pool
+
+import (
+	"context"
+	"errors"
+
+	"github.com/cs-mastery/worker-pool/pkg/types"
+)
+
+// JobFuture is a handle to the eventual result of a job submitted with
+// SubmitAsync. The result is delivered only to the future, never to the
+// pool's shared result queue.
+type JobFuture struct {
+	pool   *Pool
+	id     string
+	done   chan struct{}
+	result types.JobResult
+}
+
+// SubmitAsync submits a job and returns a future for its final result,
+// after any retries. ctx bounds how long the caller waits for queue space,
+// as with SubmitWithContext.
+//
+// Resubmitting a job with an IdempotencyKey already used within the
+// idempotency window returns the first job's future and a nil error. If
+// the first job has no future, such as a workflow step, the
+// *DuplicateSubmissionError is returned instead.
+func (p *Pool) SubmitAsync(ctx context.Context, job types.Job) (*JobFuture, error) {
+	future := newJobFuture(p, job.ID)
+	w := &watcher{fn: future.resolve, exclusive: true, future: future}
+	if err := p.submit(ctx, job, w); err != nil {
+		var duplicate *DuplicateSubmissionError
+		if errors.As(err, &duplicate) && duplicate.future != nil {
+			return duplicate.future, nil
+		}
+		return nil, err
+	}
+	return future, nil
+}
+
+// newJobFuture creates an unresolved future for a job
+func newJobFuture(p *Pool, id string) *JobFuture {
+	return &JobFuture{
+		pool: p,
+		id:   id,
+		done: make(chan struct{}),
+	}
+}
+
+// resolve stores the final result and releases waiters. The pool calls it
+// exactly once.
+func (f *JobFuture) resolve(result types.JobResult) {
+	f.result = result
+	close(f.done)
+}
+
+// ID returns the ID of the job behind the future
+func (f *JobFuture) ID() string {
+	return f.id
+}
+
+// Done returns a channel that is closed once the job has a final result
+func (f *JobFuture) Done() <-chan struct{} {
+	return f.done
+}
+
+// Wait blocks until the job finishes or ctx is done. It returns the job's
+// result and error, or ctx's error if ctx ends first.
+func (f *JobFuture) Wait(ctx context.Context) (types.JobResult, error) {
+	select {
+	case <-f.done:
+		return f.result, f.result.Error
+	case <-ctx.Done():
+		return types.JobResult{}, ctx.Err()
+	}
+}
+
+// Result returns the job's result without blocking. ok is false while the
+// job is still pending.
+func (f *JobFuture) Result() (result types.JobResult, ok bool) {
+	select {
+	case <-f.done:
+		return f.result, true
+	default:
+		return types.JobResult{}, false
+	}
+}
+
+// Cancel cancels the job. The future still completes, with a
+// context.Canceled error.
+func (f *JobFuture) Cancel() error {
+	return f.pool.CancelJob(f.id)
+}
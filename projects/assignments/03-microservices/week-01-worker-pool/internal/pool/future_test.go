@@ -0,0 +1,111 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cs-mastery/worker-pool/pkg/types"
+)
+
+func TestJobFutureDeliversResult(t *testing.T) {
+	release := make(chan struct{})
+	p := startPool(t, types.PoolConfig{
+		WorkerCount: 2,
+		Handler: func(ctx context.Context, job types.Job) (interface{}, error) {
+			switch job.ID {
+			case "slow":
+				<-release
+			case "bad":
+				return nil, Permanent(errors.New("bad input"))
+			}
+			return "done " + job.ID, nil
+		},
+	})
+
+	future, err := p.SubmitAsync(context.Background(), types.Job{ID: "slow"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if future.ID() != "slow" {
+		t.Errorf("ID = %q, want slow", future.ID())
+	}
+	if _, ok := future.Result(); ok {
+		t.Error("Result reported a pending job as finished")
+	}
+	select {
+	case <-future.Done():
+		t.Error("Done closed before the job finished")
+	default:
+	}
+
+	// Wait gives up with its ctx without affecting the job
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := future.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Wait on a pending job = %v, want DeadlineExceeded", err)
+	}
+
+	close(release)
+	result, err := future.Wait(context.Background())
+	if err != nil || result.Data != "done slow" {
+		t.Fatalf("Wait = %v, %v; want done slow", result.Data, err)
+	}
+	<-future.Done()
+	if result, ok := future.Result(); !ok || result.Data != "done slow" {
+		t.Errorf("Result = %v, %v after Done; want done slow", result.Data, ok)
+	}
+
+	// A failure is returned by Wait
+	future, err = p.SubmitAsync(context.Background(), types.Job{ID: "bad"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result, err := future.Wait(context.Background()); err == nil || result.Error != err {
+		t.Errorf("Wait on a failing job = %v, want its error", err)
+	}
+
+	// Future-bound results never reach the shared queue
+	submitAll(t, p, types.Job{ID: "plain"})
+	if result, err := p.GetResult(); err != nil || result.JobID != "plain" {
+		t.Errorf("GetResult = %s, %v; want plain", result.JobID, err)
+	}
+	noMoreResults(t, p)
+}
+
+func TestJobFutureCancel(t *testing.T) {
+	started := make(chan struct{})
+	p := startPool(t, types.PoolConfig{
+		WorkerCount: 1,
+		Handler: func(ctx context.Context, job types.Job) (interface{}, error) {
+			if job.ID == "running" {
+				close(started)
+			}
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+	})
+
+	running, err := p.SubmitAsync(context.Background(), types.Job{ID: "running"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-started
+	queued, err := p.SubmitAsync(context.Background(), types.Job{ID: "queued"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, future := range []*JobFuture{running, queued} {
+		if err := future.Cancel(); err != nil {
+			t.Fatal(err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		if _, err := future.Wait(ctx); !errors.Is(err, context.Canceled) {
+			t.Errorf("Wait on cancelled %s = %v, want context.Canceled", future.ID(), err)
+		}
+		cancel()
+	}
+	noMoreResults(t, p)
+}
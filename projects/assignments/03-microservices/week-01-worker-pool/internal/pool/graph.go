@@ -0,0 +1,513 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cs-mastery/worker-pool/pkg/types"
+)
+
+// Graph errors
+var (
+	ErrMissingGraphID   = errors.New("graph ID is required")
+	ErrDuplicateGraphID = errors.New("graph ID already in use")
+	ErrGraphNotFound    = errors.New("graph not found")
+	ErrInvalidGraph     = errors.New("invalid job graph")
+	ErrGraphCycle       = errors.New("job graph has a cycle")
+)
+
+// parentResultsKey is the job context key for a graph node's parent
+// results
+type parentResultsKey struct{}
+
+// ParentResults returns the results of a graph node's parents by job ID.
+// Handlers call it with the context they were given; it returns nil for
+// jobs that are not graph nodes or have no parents.
+func ParentResults(ctx context.Context) map[string]types.JobResult {
+	if results, ok := ctx.Value(parentResultsKey{}).(map[string]types.JobResult); ok {
+		return results
+	}
+	if a, ok := ctx.Value(attemptKey{}).(*attempt); ok && a.job.Context != nil {
+		results, _ := a.job.Context.Value(parentResultsKey{}).(map[string]types.JobResult)
+		return results
+	}
+	return nil
+}
+
+// graphNode is one node of a running graph
+type graphNode struct {
+	job      types.Job
+	parents  []string
+	children []string
+	waiting  int // Parents that have not succeeded yet
+	state    types.NodeState
+	result   *types.JobResult
+}
+
+// GraphRun is a handle to a job graph submitted with SubmitGraph. Node
+// results go to the graph, never to the pool's shared result queue.
+type GraphRun struct {
+	pool   *Pool
+	id     string
+	policy types.GraphFailurePolicy
+	done   chan struct{}
+
+	mu         sync.Mutex
+	nodes      map[string]*graphNode
+	order      []string // Node IDs in declaration order
+	state      types.GraphState
+	remaining  int // Nodes not in a final state
+	startedAt  time.Time
+	finishedAt time.Time
+}
+
+// SubmitGraph validates a job graph and submits the nodes that have no
+// dependencies. Every other node is submitted once all of its parents
+// have succeeded, and its handler can read their results with
+// ParentResults. When a node fails, graph.OnError decides whether the
+// rest of the graph is cancelled or only the node's descendants are
+// skipped.
+//
+// Cycles, unknown dependencies and unregistered job types are reported
+// before anything is queued. ctx bounds how long SubmitGraph waits for
+// queue space for the first nodes; if one cannot be queued the graph is
+// cancelled and the error returned.
+func (p *Pool) SubmitGraph(ctx context.Context, graph types.JobGraph) (*GraphRun, error) {
+	if graph.ID == "" {
+		return nil, ErrMissingGraphID
+	}
+	if !p.IsRunning() {
+		return nil, ErrPoolNotRunning
+	}
+
+	g, err := p.newGraphRun(graph)
+	if err != nil {
+		return nil, err
+	}
+
+	p.graphsMu.Lock()
+	p.evictGraphsLocked(time.Now())
+	if existing, ok := p.graphs[graph.ID]; ok && !existing.finished() {
+		p.graphsMu.Unlock()
+		return nil, fmt.Errorf("%w: %s", ErrDuplicateGraphID, graph.ID)
+	}
+	p.graphs[graph.ID] = g
+	p.graphsMu.Unlock()
+
+	g.mu.Lock()
+	var roots []*graphNode
+	for _, id := range g.order {
+		if node := g.nodes[id]; node.waiting == 0 {
+			node.state = types.NodeQueued
+			roots = append(roots, node)
+		}
+	}
+	g.mu.Unlock()
+
+	for _, node := range roots {
+		if err := g.submitNode(ctx, node); err != nil {
+			g.nodeFinished(node.job.ID, types.JobResult{JobID: node.job.ID, Error: err, Errors: []error{err}})
+			g.Cancel()
+			p.graphsMu.Lock()
+			delete(p.graphs, graph.ID)
+			p.graphsMu.Unlock()
+			return nil, fmt.Errorf("submitting graph node %s: %w", node.job.ID, err)
+		}
+	}
+	return g, nil
+}
+
+// newGraphRun validates a graph and builds its nodes
+func (p *Pool) newGraphRun(graph types.JobGraph) (*GraphRun, error) {
+	if len(graph.Nodes) == 0 {
+		return nil, fmt.Errorf("%w: no nodes", ErrInvalidGraph)
+	}
+
+	g := &GraphRun{
+		pool:      p,
+		id:        graph.ID,
+		policy:    graph.OnError,
+		done:      make(chan struct{}),
+		nodes:     make(map[string]*graphNode, len(graph.Nodes)),
+		state:     types.GraphRunning,
+		remaining: len(graph.Nodes),
+		startedAt: time.Now(),
+	}
+
+	for _, n := range graph.Nodes {
+		id := n.Job.ID
+		if id == "" {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidGraph, ErrMissingJobID)
+		}
+		if _, ok := g.nodes[id]; ok {
+			return nil, fmt.Errorf("%w: duplicate node %s", ErrInvalidGraph, id)
+		}
+		if _, err := p.typeConfig(n.Job); err != nil {
+			return nil, fmt.Errorf("%w: node %s: %w", ErrInvalidGraph, id, err)
+		}
+		g.nodes[id] = &graphNode{
+			job:     n.Job,
+			parents: append([]string(nil), n.DependsOn...),
+			waiting: len(n.DependsOn),
+		}
+		g.order = append(g.order, id)
+	}
+
+	for _, id := range g.order {
+		node := g.nodes[id]
+		seen := make(map[string]bool, len(node.parents))
+		for _, parent := range node.parents {
+			if seen[parent] {
+				return nil, fmt.Errorf("%w: node %s depends on %s twice", ErrInvalidGraph, id, parent)
+			}
+			seen[parent] = true
+
+			parentNode, ok := g.nodes[parent]
+			if !ok {
+				return nil, fmt.Errorf("%w: node %s depends on unknown node %s", ErrInvalidGraph, id, parent)
+			}
+			parentNode.children = append(parentNode.children, id)
+		}
+	}
+
+	if cycle := g.findCycle(); cycle != nil {
+		return nil, fmt.Errorf("%w: %s", ErrGraphCycle, strings.Join(cycle, " -> "))
+	}
+	return g, nil
+}
+
+// findCycle returns the node IDs of a dependency cycle, first node
+// repeated at the end, or nil if the graph is acyclic
+func (g *GraphRun) findCycle() []string {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	color := make(map[string]int, len(g.nodes))
+	var path []string
+
+	var visit func(id string) []string
+	visit = func(id string) []string {
+		color[id] = visiting
+		path = append(path, id)
+		for _, child := range g.nodes[id].children {
+			switch color[child] {
+			case visiting:
+				for i, onPath := range path {
+					if onPath == child {
+						return append(append([]string(nil), path[i:]...), child)
+					}
+				}
+			case unvisited:
+				if cycle := visit(child); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		color[id] = visited
+		return nil
+	}
+
+	for _, id := range g.order {
+		if color[id] == unvisited {
+			if cycle := visit(id); cycle != nil {
+				return cycle
+			}
+		}
+	}
+	return nil
+}
+
+// submitNode queues a node whose parents have all succeeded, handing it
+// their results through its context
+func (g *GraphRun) submitNode(ctx context.Context, node *graphNode) error {
+	g.mu.Lock()
+	job := node.job
+	results := make(map[string]types.JobResult, len(node.parents))
+	for _, parent := range node.parents {
+		results[parent] = *g.nodes[parent].result
+	}
+	g.mu.Unlock()
+
+	parent := job.Context
+	if parent == nil {
+		parent = context.Background()
+	}
+	job.Context = context.WithValue(parent, parentResultsKey{}, results)
+
+	id := job.ID
+	w := &watcher{fn: func(result types.JobResult) { g.nodeFinished(id, result) }, exclusive: true}
+	if err := g.pool.submit(ctx, job, w); err != nil {
+		return err
+	}
+
+	// The graph may have been cancelled while the node was being queued
+	g.mu.Lock()
+	stopped := g.state != types.GraphRunning
+	g.mu.Unlock()
+	if stopped {
+		g.pool.CancelJob(id)
+	}
+	return nil
+}
+
+// submitLater queues nodes that became runnable from a job's completion.
+// It runs in its own goroutine so a full queue never blocks the worker
+// that finished the parent.
+func (g *GraphRun) submitLater(nodes []*graphNode) {
+	if len(nodes) == 0 {
+		return
+	}
+
+	go func() {
+		for _, node := range nodes {
+			if err := g.submitNode(context.Background(), node); err != nil {
+				g.nodeFinished(node.job.ID, types.JobResult{
+					JobID:  node.job.ID,
+					Error:  err,
+					Errors: []error{err},
+				})
+			}
+		}
+	}()
+}
+
+// nodeFinished records a node's final result, queues children that are
+// now runnable and applies the failure policy
+func (g *GraphRun) nodeFinished(id string, result types.JobResult) {
+	g.mu.Lock()
+	node := g.nodes[id]
+	if node.result != nil {
+		g.mu.Unlock()
+		return
+	}
+	node.result = &result
+	g.remaining--
+
+	var ready []*graphNode
+	var cancel []string
+	switch {
+	case result.Error == nil:
+		node.state = types.NodeSucceeded
+		for _, childID := range node.children {
+			child := g.nodes[childID]
+			child.waiting--
+			if child.waiting == 0 && child.state == types.NodeWaiting && g.state == types.GraphRunning {
+				child.state = types.NodeQueued
+				ready = append(ready, child)
+			}
+		}
+	case errors.Is(result.Error, context.Canceled):
+		node.state = types.NodeCancelled
+		cancel = g.failLocked(node)
+	default:
+		node.state = types.NodeFailed
+		cancel = g.failLocked(node)
+	}
+	g.finishIfDoneLocked()
+	g.mu.Unlock()
+
+	g.submitLater(ready)
+	for _, id := range cancel {
+		g.pool.CancelJob(id)
+	}
+}
+
+// failLocked applies the failure policy after a node failed or was
+// cancelled. It returns the IDs of queued nodes to cancel. Caller holds
+// g.mu.
+func (g *GraphRun) failLocked(node *graphNode) []string {
+	if g.policy == types.GraphContinueOnError {
+		g.skipDescendantsLocked(node)
+		return nil
+	}
+	if g.state != types.GraphRunning {
+		return nil
+	}
+	g.state = types.GraphFailed
+	return g.stopLocked()
+}
+
+// skipDescendantsLocked marks every waiting descendant of node as
+// skipped. Caller holds g.mu.
+func (g *GraphRun) skipDescendantsLocked(node *graphNode) {
+	for _, childID := range node.children {
+		child := g.nodes[childID]
+		if child.state != types.NodeWaiting {
+			continue
+		}
+		child.state = types.NodeSkipped
+		g.remaining--
+		g.skipDescendantsLocked(child)
+	}
+}
+
+// stopLocked cancels every waiting node and returns the IDs of queued
+// ones, which finish once the pool cancels them. Caller holds g.mu.
+func (g *GraphRun) stopLocked() []string {
+	var queued []string
+	for _, id := range g.order {
+		switch node := g.nodes[id]; node.state {
+		case types.NodeWaiting:
+			node.state = types.NodeCancelled
+			g.remaining--
+		case types.NodeQueued:
+			queued = append(queued, id)
+		}
+	}
+	return queued
+}
+
+// finishIfDoneLocked settles the graph once every node is final. Caller
+// holds g.mu.
+func (g *GraphRun) finishIfDoneLocked() {
+	if g.remaining > 0 || !g.finishedAt.IsZero() {
+		return
+	}
+
+	if g.state == types.GraphRunning {
+		g.state = types.GraphSucceeded
+		for _, node := range g.nodes {
+			if node.state != types.NodeSucceeded {
+				g.state = types.GraphFailed
+				break
+			}
+		}
+	}
+	g.finishedAt = time.Now()
+	close(g.done)
+	go g.pool.graphFinished(g)
+}
+
+// ID returns the graph's ID
+func (g *GraphRun) ID() string {
+	return g.id
+}
+
+// Done returns a channel that is closed once every node is final
+func (g *GraphRun) Done() <-chan struct{} {
+	return g.done
+}
+
+// finished reports whether every node is final
+func (g *GraphRun) finished() bool {
+	select {
+	case <-g.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// Wait blocks until the graph finishes or ctx is done and returns the
+// graph's final status, or ctx's error if ctx ends first
+func (g *GraphRun) Wait(ctx context.Context) (types.GraphStatus, error) {
+	select {
+	case <-g.done:
+		return g.Status(), nil
+	case <-ctx.Done():
+		return types.GraphStatus{}, ctx.Err()
+	}
+}
+
+// Cancel stops the graph: waiting nodes are cancelled and queued or
+// running ones are cancelled through the pool. It does nothing once the
+// graph has failed or finished.
+func (g *GraphRun) Cancel() {
+	g.mu.Lock()
+	if g.state != types.GraphRunning {
+		g.mu.Unlock()
+		return
+	}
+	g.state = types.GraphCancelled
+	queued := g.stopLocked()
+	g.finishIfDoneLocked()
+	g.mu.Unlock()
+
+	for _, id := range queued {
+		g.pool.CancelJob(id)
+	}
+}
+
+// Status returns a snapshot of the graph and its nodes
+func (g *GraphRun) Status() types.GraphStatus {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	status := types.GraphStatus{
+		ID:         g.id,
+		State:      g.state,
+		Nodes:      make([]types.GraphNodeStatus, 0, len(g.order)),
+		StartedAt:  g.startedAt,
+		FinishedAt: g.finishedAt,
+	}
+	for _, id := range g.order {
+		node := g.nodes[id]
+		nodeStatus := types.GraphNodeStatus{
+			ID:        id,
+			State:     node.state,
+			DependsOn: append([]string(nil), node.parents...),
+		}
+		if node.result != nil {
+			result := *node.result
+			nodeStatus.Result = &result
+		}
+		if node.state == types.NodeQueued {
+			if record, ok := g.pool.jobs.get(id); ok && record.Status == types.JobProcessing {
+				nodeStatus.State = types.NodeRunning
+			}
+		}
+		status.Nodes = append(status.Nodes, nodeStatus)
+	}
+	return status
+}
+
+// GetGraphStatus returns the status of a graph submitted with
+// SubmitGraph. Finished graphs remain available like finished jobs, for
+// PoolConfig.JobRetention.
+func (p *Pool) GetGraphStatus(id string) (types.GraphStatus, error) {
+	p.graphsMu.Lock()
+	p.evictGraphsLocked(time.Now())
+	g, ok := p.graphs[id]
+	p.graphsMu.Unlock()
+
+	if !ok {
+		return types.GraphStatus{}, fmt.Errorf("%w: %s", ErrGraphNotFound, id)
+	}
+	return g.Status(), nil
+}
+
+// graphFinished queues a finished graph for eviction
+func (p *Pool) graphFinished(g *GraphRun) {
+	p.graphsMu.Lock()
+	defer p.graphsMu.Unlock()
+
+	if p.graphs[g.id] != g {
+		return
+	}
+	p.finishedGraphs = append(p.finishedGraphs, finishedJob{id: g.id, at: g.finishedAt})
+	p.evictGraphsLocked(time.Now())
+}
+
+// evictGraphsLocked drops finished graphs past JobRetention or beyond
+// MaxJobRecords. Caller holds graphsMu.
+func (p *Pool) evictGraphsLocked(now time.Time) {
+	for len(p.finishedGraphs) > 0 {
+		oldest := p.finishedGraphs[0]
+		expired := p.config.JobRetention > 0 && now.Sub(oldest.at) > p.config.JobRetention
+		if !expired && len(p.finishedGraphs) <= p.config.MaxJobRecords {
+			return
+		}
+
+		p.finishedGraphs = p.finishedGraphs[1:]
+		if g, ok := p.graphs[oldest.id]; ok && g.finishedAt.Equal(oldest.at) {
+			delete(p.graphs, oldest.id)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cs-mastery/worker-pool/pkg/types"
+)
+
+// waitGraph waits for g to finish, failing the test after a second
+func waitGraph(t *testing.T, g *GraphRun) types.GraphStatus {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	status, err := g.Wait(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return status
+}
+
+func TestGraphRunsParentsFirst(t *testing.T) {
+	var mu sync.Mutex
+	var order []string
+	parents := make(map[string][]string)
+
+	p := startPool(t, types.PoolConfig{WorkerCount: 4, Handler: func(ctx context.Context, job types.Job) (interface{}, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		order = append(order, job.ID)
+		for id := range ParentResults(ctx) {
+			parents[job.ID] = append(parents[job.ID], id)
+		}
+		sort.Strings(parents[job.ID])
+		return job.ID, nil
+	}})
+
+	// A diamond: b and c need a, d needs both
+	g, err := p.SubmitGraph(context.Background(), types.JobGraph{
+		ID: "diamond",
+		Nodes: []types.GraphNode{
+			{Job: types.Job{ID: "d"}, DependsOn: []string{"b", "c"}},
+			{Job: types.Job{ID: "b"}, DependsOn: []string{"a"}},
+			{Job: types.Job{ID: "c"}, DependsOn: []string{"a"}},
+			{Job: types.Job{ID: "a"}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	status := waitGraph(t, g)
+
+	if status.State != types.GraphSucceeded {
+		t.Fatalf("graph state = %s, want succeeded", status.State)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(order) != 4 || order[0] != "a" || order[3] != "d" {
+		t.Errorf("run order = %v, want a first and d last", order)
+	}
+	want := map[string][]string{"b": {"a"}, "c": {"a"}, "d": {"b", "c"}}
+	if !reflect.DeepEqual(parents, want) {
+		t.Errorf("parent results = %v, want %v", parents, want)
+	}
+	if d := status.Nodes[0]; d.ID != "d" || d.Result == nil || d.Result.Data != "d" {
+		t.Errorf("first node status = %+v, want d with its result", d)
+	}
+}
+
+func TestGraphValidation(t *testing.T) {
+	p := startPool(t, types.PoolConfig{WorkerCount: 1, Handler: func(ctx context.Context, job types.Job) (interface{}, error) {
+		return nil, nil
+	}})
+
+	tests := []struct {
+		name  string
+		graph types.JobGraph
+		want  error
+	}{
+		{"missing ID", types.JobGraph{Nodes: []types.GraphNode{{Job: types.Job{ID: "a"}}}}, ErrMissingGraphID},
+		{"no nodes", types.JobGraph{ID: "g"}, ErrInvalidGraph},
+		{"unknown dependency", types.JobGraph{ID: "g", Nodes: []types.GraphNode{
+			{Job: types.Job{ID: "a"}, DependsOn: []string{"missing"}},
+		}}, ErrInvalidGraph},
+		{"cycle", types.JobGraph{ID: "g", Nodes: []types.GraphNode{
+			{Job: types.Job{ID: "root"}},
+			{Job: types.Job{ID: "a"}, DependsOn: []string{"root", "c"}},
+			{Job: types.Job{ID: "b"}, DependsOn: []string{"a"}},
+			{Job: types.Job{ID: "c"}, DependsOn: []string{"b"}},
+		}}, ErrGraphCycle},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := p.SubmitGraph(context.Background(), tt.graph); !errors.Is(err, tt.want) {
+				t.Errorf("SubmitGraph = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	// Nothing was queued for the invalid graphs
+	if n := p.GetQueueLength(); n != 0 {
+		t.Errorf("queue length = %d, want 0", n)
+	}
+}
+
+func TestGraphFailurePolicies(t *testing.T) {
+	// a fails; b needs a; c is independent and d needs c
+	graph := func(policy types.GraphFailurePolicy) types.JobGraph {
+		return types.JobGraph{
+			ID:      fmt.Sprint("policy-", policy),
+			OnError: policy,
+			Nodes: []types.GraphNode{
+				{Job: types.Job{ID: "a", Priority: 9}},
+				{Job: types.Job{ID: "b"}, DependsOn: []string{"a"}},
+				{Job: types.Job{ID: "c"}},
+				{Job: types.Job{ID: "d"}, DependsOn: []string{"c"}},
+			},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		policy types.GraphFailurePolicy
+		want   map[string]types.NodeState
+	}{
+		{"fail fast", types.GraphFailFast, map[string]types.NodeState{
+			"a": types.NodeFailed, "b": types.NodeCancelled, "c": types.NodeCancelled, "d": types.NodeCancelled,
+		}},
+		{"continue on error", types.GraphContinueOnError, map[string]types.NodeState{
+			"a": types.NodeFailed, "b": types.NodeSkipped, "c": types.NodeSucceeded, "d": types.NodeSucceeded,
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// One worker runs a first, by priority, while c waits in the
+			// queue
+			p := startPool(t, types.PoolConfig{WorkerCount: 1, Handler: func(ctx context.Context, job types.Job) (interface{}, error) {
+				if job.ID == "a" {
+					return nil, errors.New("boom")
+				}
+				return nil, ctx.Err()
+			}})
+			g, err := p.SubmitGraph(context.Background(), graph(tt.policy))
+			if err != nil {
+				t.Fatal(err)
+			}
+			status := waitGraph(t, g)
+
+			if status.State != types.GraphFailed {
+				t.Errorf("graph state = %s, want failed", status.State)
+			}
+			got := make(map[string]types.NodeState)
+			for _, node := range status.Nodes {
+				got[node.ID] = node.State
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("node states = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,231 @@
+package pool
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/cs-mastery/worker-pool/pkg/types"
+)
+
+// UnknownJobTypeError is returned when a job names a type that has no
+// registered handler
+type UnknownJobTypeError struct {
+	Type string
+}
+
+func (e *UnknownJobTypeError) Error() string {
+	return fmt.Sprintf("unknown job type %q", e.Type)
+}
+
+// HandlerRegistry maps job types to their handlers and per-type settings
+type HandlerRegistry struct {
+	mu      sync.RWMutex
+	configs map[string]types.JobTypeConfig
+}
+
+// NewHandlerRegistry creates an empty handler registry
+func NewHandlerRegistry() *HandlerRegistry {
+	return &HandlerRegistry{configs: make(map[string]types.JobTypeConfig)}
+}
+
+// Register sets the handler and defaults for a job type, replacing any
+// earlier registration
+func (r *HandlerRegistry) Register(jobType string, config types.JobTypeConfig) error {
+	if jobType == "" {
+		return fmt.Errorf("job type is required")
+	}
+	if config.Handler == nil {
+		return fmt.Errorf("job type %q: handler is required", jobType)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.configs[jobType] = config
+	return nil
+}
+
+// Lookup returns the configuration registered for a job type
+func (r *HandlerRegistry) Lookup(jobType string) (types.JobTypeConfig, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	config, ok := r.configs[jobType]
+	return config, ok
+}
+
+// Types returns the registered job types in sorted order
+func (r *HandlerRegistry) Types() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	jobTypes := make([]string, 0, len(r.configs))
+	for jobType := range r.configs {
+		jobTypes = append(jobTypes, jobType)
+	}
+	sort.Strings(jobTypes)
+	return jobTypes
+}
+
+// typeSlot tracks the running jobs of one type and the queued jobs held
+// back because the type is at its concurrency cap. Guarded by queueMu.
+type typeSlot struct {
+	running int
+	parked  *priorityQueue
+}
+
+// Handlers returns the pool's handler registry
+func (p *Pool) Handlers() *HandlerRegistry {
+	return p.handlers
+}
+
+// RegisterHandler registers the handler and defaults for a job type
+func (p *Pool) RegisterHandler(jobType string, config types.JobTypeConfig) error {
+	return p.handlers.Register(jobType, config)
+}
+
+// handle runs a job with the handler registered for its type, falling
+// back to PoolConfig.Handler and then to simulated work. Jobs carrying a
+// taskFunc run it instead.
+func (p *Pool) handle(ctx context.Context, job types.Job) (interface{}, error) {
+	if task, ok := job.Data.(taskFunc); ok {
+		return task(ctx, job)
+	}
+	if job.Type != "" {
+		config, ok := p.handlers.Lookup(job.Type)
+		if !ok {
+			return nil, Permanent(&UnknownJobTypeError{Type: job.Type})
+		}
+		return config.Handler(ctx, job)
+	}
+
+	if p.config.Handler != nil {
+		return p.config.Handler(ctx, job)
+	}
+	return simulateWork(ctx, job)
+}
+
+// typeConfig returns the configuration for a job's type. Untyped jobs
+// have an empty configuration.
+func (p *Pool) typeConfig(job types.Job) (types.JobTypeConfig, error) {
+	if job.Type == "" {
+		return types.JobTypeConfig{}, nil
+	}
+
+	config, ok := p.handlers.Lookup(job.Type)
+	if !ok {
+		return types.JobTypeConfig{}, &UnknownJobTypeError{Type: job.Type}
+	}
+	return config, nil
+}
+
+// typeSlotLocked returns the slot for a job type. Caller holds queueMu.
+func (p *Pool) typeSlotLocked(jobType string) *typeSlot {
+	slot, ok := p.typeSlots[jobType]
+	if !ok {
+		slot = &typeSlot{parked: newPriorityQueue(p.config.AgingInterval)}
+		p.typeSlots[jobType] = slot
+	}
+	return slot
+}
+
+// atTypeLimitLocked reports whether a job's type already has
+// MaxConcurrency jobs running. Caller holds queueMu.
+func (p *Pool) atTypeLimitLocked(job types.Job) bool {
+	if job.Type == "" {
+		return false
+	}
+
+	config, ok := p.handlers.Lookup(job.Type)
+	if !ok || config.MaxConcurrency <= 0 {
+		return false
+	}
+	return p.typeSlotLocked(job.Type).running >= config.MaxConcurrency
+}
+
+// acquireTypeSlotLocked counts a job of its type as running. Caller holds
+// queueMu.
+func (p *Pool) acquireTypeSlotLocked(job types.Job) {
+	if job.Type != "" {
+		p.typeSlotLocked(job.Type).running++
+	}
+}
+
+// releaseTypeSlot marks an attempt of a typed job as finished and moves
+// the best parked job of that type back onto the ready queue
+func (p *Pool) releaseTypeSlot(job types.Job) {
+	if job.Type == "" {
+		return
+	}
+
+	p.queueMu.Lock()
+	slot := p.typeSlotLocked(job.Type)
+	slot.running--
+	unparked := false
+	if slot.parked.Len() > 0 && !p.atTypeLimitLocked(job) {
+		p.queue.push(heap.Pop(slot.parked).(*queueItem))
+		unparked = true
+	}
+	p.queueMu.Unlock()
+
+	if unparked {
+		p.wakeDispatcher()
+	}
+}
+
+// parkedLengthLocked counts queued jobs held back by concurrency caps.
+// Caller holds queueMu.
+func (p *Pool) parkedLengthLocked() int {
+	n := 0
+	for _, slot := range p.typeSlots {
+		n += slot.parked.Len()
+	}
+	for _, slot := range p.keySlots {
+		n += len(slot.backlog)
+	}
+	return n
+}
+
+// eachParkedLocked calls fn for every queued job held back by a job type
+// or partition key cap. Caller holds queueMu.
+func (p *Pool) eachParkedLocked(fn func(item *queueItem)) {
+	for _, slot := range p.typeSlots {
+		for _, item := range slot.parked.items {
+			fn(item)
+		}
+	}
+	for _, slot := range p.keySlots {
+		for _, item := range slot.backlog {
+			fn(item)
+		}
+	}
+}
+
+// removeParkedLocked removes a job found by eachParkedLocked. Caller
+// holds queueMu.
+func (p *Pool) removeParkedLocked(item *queueItem) {
+	if p.removeBacklogLocked(item) {
+		return
+	}
+	for _, slot := range p.typeSlots {
+		if item.index < slot.parked.Len() && slot.parked.items[item.index] == item {
+			heap.Remove(slot.parked, item.index)
+			p.releaseKeyLocked(item.job)
+			return
+		}
+	}
+}
+
+// typeUsage returns the running and parked counts per job type
+func (p *Pool) typeUsage() map[string]typeSlot {
+	p.queueMu.Lock()
+	defer p.queueMu.Unlock()
+
+	usage := make(map[string]typeSlot, len(p.typeSlots))
+	for jobType, slot := range p.typeSlots {
+		usage[jobType] = *slot
+	}
+	return usage
+}
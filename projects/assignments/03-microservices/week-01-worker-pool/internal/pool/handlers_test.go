@@ -0,0 +1,220 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cs-mastery/worker-pool/pkg/types"
+)
+
+// waitForType waits until the pool's metrics for a job type match want,
+// failing the test after a second
+func waitForType(t *testing.T, p *Pool, jobType string, want func(types.JobTypeMetrics) bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !want(p.GetMetrics().JobTypes[jobType]) {
+		if time.Now().After(deadline) {
+			t.Fatalf("type %s metrics = %+v", jobType, p.GetMetrics().JobTypes[jobType])
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestSubmitUnknownJobType(t *testing.T) {
+	p := startPool(t, types.PoolConfig{WorkerCount: 1})
+	if err := p.RegisterHandler("", types.JobTypeConfig{Handler: simulateWork}); err == nil {
+		t.Error("registered a handler without a type")
+	}
+	if err := p.RegisterHandler("email", types.JobTypeConfig{}); err == nil {
+		t.Error("registered a type without a handler")
+	}
+	if got := p.Handlers().Types(); len(got) != 0 {
+		t.Errorf("registered types = %v, want none", got)
+	}
+
+	err := p.Submit(types.Job{ID: "send", Type: "email"})
+	var unknown *UnknownJobTypeError
+	if !errors.As(err, &unknown) || unknown.Type != "email" {
+		t.Fatalf("Submit = %v, want *UnknownJobTypeError for email", err)
+	}
+	assertUntracked(t, p, "send")
+}
+
+func TestJobTypeDefaults(t *testing.T) {
+	var flakyCalls atomic.Int32
+	p := startPool(t, types.PoolConfig{WorkerCount: 2, Handler: func(ctx context.Context, job types.Job) (interface{}, error) {
+		return nil, errors.New("untyped jobs are not retried")
+	}})
+	if err := p.RegisterHandler("slow", types.JobTypeConfig{
+		Timeout: 10 * time.Millisecond,
+		Handler: func(ctx context.Context, job types.Job) (interface{}, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.RegisterHandler("flaky", types.JobTypeConfig{
+		RetryPolicy: &types.RetryPolicy{MaxAttempts: 3, Backoff: ExponentialBackoff{Initial: time.Millisecond}},
+		Handler: func(ctx context.Context, job types.Job) (interface{}, error) {
+			if flakyCalls.Add(1) < 3 {
+				return nil, fmt.Errorf("attempt %d failed", job.Attempt)
+			}
+			return "ok", nil
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	submitAll(t, p, types.Job{ID: "slow", Type: "slow"}, types.Job{ID: "flaky", Type: "flaky"}, types.Job{ID: "plain"})
+	results := make(map[string]types.JobResult)
+	for i := 0; i < 3; i++ {
+		result, err := p.GetResult()
+		if err != nil {
+			t.Fatal(err)
+		}
+		results[result.JobID] = result
+	}
+
+	if r := results["slow"]; !errors.Is(r.Error, context.DeadlineExceeded) {
+		t.Errorf("slow job error = %v, want the type's timeout", r.Error)
+	}
+	if r := results["flaky"]; r.Error != nil || r.Attempt != 3 {
+		t.Errorf("flaky job = attempt %d, %v; want success on attempt 3", r.Attempt, r.Error)
+	}
+	if r := results["plain"]; r.Error == nil || r.Attempt != 1 {
+		t.Errorf("plain job = attempt %d, %v; want one failed attempt", r.Attempt, r.Error)
+	}
+}
+
+func TestJobTypeMaxConcurrency(t *testing.T) {
+	release := make(chan struct{})
+	var running, maxRunning atomic.Int32
+	p := startPool(t, types.PoolConfig{
+		WorkerCount:     4,
+		EnableMetrics:   true,
+		MetricsInterval: time.Hour,
+		Handler: func(ctx context.Context, job types.Job) (interface{}, error) {
+			return nil, nil
+		},
+	})
+	if err := p.RegisterHandler("capped", types.JobTypeConfig{
+		MaxConcurrency: 2,
+		Handler: func(ctx context.Context, job types.Job) (interface{}, error) {
+			n := running.Add(1)
+			defer running.Add(-1)
+			for {
+				max := maxRunning.Load()
+				if n <= max || maxRunning.CompareAndSwap(max, n) {
+					break
+				}
+			}
+			<-release
+			return nil, nil
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.RegisterHandler("broken", types.JobTypeConfig{
+		Handler: func(ctx context.Context, job types.Job) (interface{}, error) {
+			return nil, Permanent(errors.New("broken"))
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 5; i++ {
+		submitAll(t, p, types.Job{ID: fmt.Sprintf("capped-%d", i), Type: "capped"})
+	}
+	waitForType(t, p, "capped", func(m types.JobTypeMetrics) bool {
+		return m.Running == 2 && m.Waiting == 3
+	})
+
+	// Workers left idle by the cap still run other jobs
+	submitAll(t, p, types.Job{ID: "plain"}, types.Job{ID: "broken", Type: "broken"})
+	for i := 0; i < 2; i++ {
+		result, err := p.GetResult()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.JobID != "plain" && result.JobID != "broken" {
+			t.Fatalf("got result for %s while the capped jobs were blocked", result.JobID)
+		}
+	}
+
+	close(release)
+	for i := 0; i < 5; i++ {
+		if _, err := p.GetResult(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := maxRunning.Load(); n != 2 {
+		t.Errorf("at most %d capped jobs ran at once, want 2", n)
+	}
+
+	// Metrics settle after the results are published
+	waitForType(t, p, "capped", func(m types.JobTypeMetrics) bool {
+		return m.Succeeded == 5 && m.Running == 0
+	})
+	metrics := p.GetMetrics()
+	if m := metrics.JobTypes["capped"]; m.Submitted != 5 || m.Failed != 0 || m.Waiting != 0 || m.AverageLatency <= 0 {
+		t.Errorf("capped metrics = %+v, want 5 submitted and succeeded", m)
+	}
+	if m := metrics.JobTypes["broken"]; m.Submitted != 1 || m.Failed != 1 || m.Succeeded != 0 {
+		t.Errorf("broken metrics = %+v, want 1 submitted and failed", m)
+	}
+	if _, ok := metrics.JobTypes[""]; ok {
+		t.Error("untyped jobs were reported as a job type")
+	}
+}
+
+func TestParkedJobsRunInPriorityOrder(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var mu sync.Mutex
+	var order []string
+	p := startPool(t, types.PoolConfig{WorkerCount: 2, EnableMetrics: true, MetricsInterval: time.Hour})
+	if err := p.RegisterHandler("serial", types.JobTypeConfig{
+		MaxConcurrency: 1,
+		Handler: func(ctx context.Context, job types.Job) (interface{}, error) {
+			mu.Lock()
+			order = append(order, job.ID)
+			mu.Unlock()
+			if job.ID == "holder" {
+				close(started)
+				<-release
+			}
+			return nil, nil
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	submitAll(t, p, types.Job{ID: "holder", Type: "serial"})
+	<-started
+	submitAll(t, p,
+		types.Job{ID: "low", Type: "serial", Priority: 1},
+		types.Job{ID: "high", Type: "serial", Priority: 9},
+		types.Job{ID: "mid", Type: "serial", Priority: 5},
+	)
+	waitForType(t, p, "serial", func(m types.JobTypeMetrics) bool { return m.Waiting == 3 })
+
+	close(release)
+	for i := 0; i < 4; i++ {
+		if _, err := p.GetResult(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if want := []string{"holder", "high", "mid", "low"}; !reflect.DeepEqual(order, want) {
+		t.Errorf("run order = %v, want %v", order, want)
+	}
+}
@@ -0,0 +1,225 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/cs-mastery/worker-pool/pkg/types"
+)
+
+// Idempotency errors
+var (
+	// ErrDuplicateSubmission is matched by *DuplicateSubmissionError
+	ErrDuplicateSubmission = errors.New("duplicate submission")
+	// ErrIdempotencyKeyReused is returned for a job whose IdempotencyKey
+	// was already used within the window with a different
+	// IdempotencyFingerprint
+	ErrIdempotencyKeyReused = errors.New("idempotency key reused for a different request")
+)
+
+// DuplicateSubmissionError is returned by Submit, SubmitWithContext and
+// SubmitAt for a job whose IdempotencyKey was already used within the idempotency
+// window. No new job is queued; JobID names the job that was. Response is
+// the reply recorded for the first submission with
+// RecordIdempotentResponse, or nil if none has been recorded yet.
+type DuplicateSubmissionError struct {
+	IdempotencyKey string
+	JobID          string
+	Response       *IdempotentResponse
+	future         *JobFuture
+}
+
+// IdempotentResponse is the reply a server sent for the first submission
+// under an idempotency key, kept so duplicates can be answered the same
+// way
+type IdempotentResponse struct {
+	StatusCode int
+	Body       []byte
+}
+
+func (e *DuplicateSubmissionError) Error() string {
+	return fmt.Sprintf("duplicate submission: idempotency key %q already used by job %s", e.IdempotencyKey, e.JobID)
+}
+
+// Is makes errors.Is(err, ErrDuplicateSubmission) match
+func (e *DuplicateSubmissionError) Is(target error) bool {
+	return target == ErrDuplicateSubmission
+}
+
+// idempotencyEntry records the job submitted under one idempotency key
+type idempotencyEntry struct {
+	jobID       string
+	fingerprint string
+	future      *JobFuture // Resolved with the job's final result; nil for jobs with their own watcher
+	createdAt   time.Time
+	settled     chan struct{}       // Closed once the submission is accepted or refused
+	accepted    bool                // Set before settled is closed
+	response    *IdempotentResponse // Guarded by the store's mu
+}
+
+// keyedAt remembers when a key was stored, for eviction in insertion
+// order
+type keyedAt struct {
+	key string
+	at  time.Time
+}
+
+// idempotencyStore maps idempotency keys to the jobs submitted under
+// them. Keys expire after window, and at most max are kept, oldest
+// evicted first.
+type idempotencyStore struct {
+	mu      sync.Mutex
+	entries map[string]*idempotencyEntry
+	order   []keyedAt
+	window  time.Duration
+	max     int
+}
+
+// newIdempotencyStore creates an empty store
+func newIdempotencyStore(window time.Duration, max int) *idempotencyStore {
+	return &idempotencyStore{
+		entries: make(map[string]*idempotencyEntry),
+		window:  window,
+		max:     max,
+	}
+}
+
+// claim returns the accepted entry for key if there is one, failing with
+// ErrIdempotencyKeyReused if it was claimed with a different fingerprint.
+// Otherwise it records a pending entry for the given job and returns it
+// with fresh set; the caller must settle it. A concurrent claim of the
+// same key waits for the pending submission to settle, bounded by ctx.
+func (s *idempotencyStore) claim(ctx context.Context, key, fingerprint, jobID string, future *JobFuture) (entry *idempotencyEntry, fresh bool, err error) {
+	for {
+		s.mu.Lock()
+		now := time.Now()
+		s.evictLocked(now)
+
+		existing, ok := s.entries[key]
+		if !ok {
+			entry = &idempotencyEntry{
+				jobID:       jobID,
+				fingerprint: fingerprint,
+				future:      future,
+				createdAt:   now,
+				settled:     make(chan struct{}),
+			}
+			s.entries[key] = entry
+			s.order = append(s.order, keyedAt{key: key, at: now})
+			s.evictLocked(now)
+			s.mu.Unlock()
+			return entry, true, nil
+		}
+		s.mu.Unlock()
+
+		select {
+		case <-existing.settled:
+		case <-ctx.Done():
+			return nil, false, ctx.Err()
+		}
+		if existing.accepted {
+			if existing.fingerprint != fingerprint {
+				return nil, false, fmt.Errorf("%w: key %q belongs to job %s", ErrIdempotencyKeyReused, key, existing.jobID)
+			}
+			return existing, false, nil
+		}
+		// The earlier submission was refused; try to claim the key again
+	}
+}
+
+// settle records whether the submission behind a fresh entry was
+// accepted. A refused submission frees the key.
+func (s *idempotencyStore) settle(key string, entry *idempotencyEntry, accepted bool) {
+	if !accepted {
+		s.mu.Lock()
+		if s.entries[key] == entry {
+			delete(s.entries, key)
+		}
+		s.mu.Unlock()
+	}
+
+	entry.accepted = accepted
+	close(entry.settled)
+}
+
+// record stores the response sent for the job accepted under key. It
+// does nothing if the key has since expired or passed to another job.
+func (s *idempotencyStore) record(key, jobID string, response IdempotentResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entry, ok := s.entries[key]; ok && entry.jobID == jobID {
+		entry.response = &response
+	}
+}
+
+// responseOf returns the response recorded for an entry, if any
+func (s *idempotencyStore) responseOf(entry *idempotencyEntry) *IdempotentResponse {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return entry.response
+}
+
+// evictLocked drops keys past the window or beyond max. Caller holds mu.
+func (s *idempotencyStore) evictLocked(now time.Time) {
+	for len(s.order) > 0 {
+		oldest := s.order[0]
+		expired := now.Sub(oldest.at) > s.window
+		if !expired && len(s.order) <= s.max {
+			return
+		}
+
+		s.order = s.order[1:]
+		// The key may have been freed and claimed again since; only drop
+		// the entry this record refers to
+		if entry, ok := s.entries[oldest.key]; ok && entry.createdAt.Equal(oldest.at) {
+			delete(s.entries, oldest.key)
+		}
+	}
+}
+
+// submitOnce submits a job that carries an idempotency key with queue,
+// which queues or schedules it. A job already submitted under the key
+// within the window is not queued again; the caller gets a
+// *DuplicateSubmissionError naming it instead.
+func (p *Pool) submitOnce(ctx context.Context, job types.Job, w *watcher, queue func(w *watcher) error) error {
+	// Give every keyed job a future so duplicates submitted with
+	// SubmitAsync can share it
+	if w == nil {
+		future := newJobFuture(p, job.ID)
+		w = &watcher{fn: future.resolve, future: future}
+	}
+
+	key := job.IdempotencyKey
+	entry, fresh, err := p.idempotency.claim(ctx, key, job.IdempotencyFingerprint, job.ID, w.future)
+	if err != nil {
+		return err
+	}
+	if !fresh {
+		p.metrics.IncrementSubmitsDeduplicated()
+		return &DuplicateSubmissionError{
+			IdempotencyKey: key,
+			JobID:          entry.jobID,
+			Response:       p.idempotency.responseOf(entry),
+			future:         entry.future,
+		}
+	}
+
+	err = queue(w)
+	p.idempotency.settle(key, entry, err == nil)
+	return err
+}
+
+// RecordIdempotentResponse stores the response sent for the job accepted
+// under an idempotency key, so that duplicates of the submission get it
+// back in DuplicateSubmissionError.Response. It does nothing if
+// idempotency is disabled or the key no longer belongs to jobID.
+func (p *Pool) RecordIdempotentResponse(key, jobID string, response IdempotentResponse) {
+	if p.idempotency == nil || key == "" {
+		return
+	}
+	p.idempotency.record(key, jobID, response)
+}
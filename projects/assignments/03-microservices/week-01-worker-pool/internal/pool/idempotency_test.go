@@ -0,0 +1,156 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cs-mastery/worker-pool/pkg/types"
+)
+
+func TestIdempotentSubmission(t *testing.T) {
+	p := NewPool(types.PoolConfig{
+		WorkerCount:       1,
+		IdempotencyWindow: time.Minute,
+		Handler: func(ctx context.Context, job types.Job) (interface{}, error) {
+			return nil, nil
+		},
+	})
+	if err := p.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer p.Stop()
+
+	job := types.Job{ID: "first", IdempotencyKey: "k", IdempotencyFingerprint: "body-1"}
+	if err := p.Submit(job); err != nil {
+		t.Fatal(err)
+	}
+
+	// A duplicate queues nothing and names the first job
+	job.ID = "second"
+	var duplicate *DuplicateSubmissionError
+	if err := p.Submit(job); !errors.As(err, &duplicate) || duplicate.JobID != "first" {
+		t.Fatalf("duplicate Submit = %v, want a DuplicateSubmissionError for first", err)
+	}
+	if duplicate.Response != nil {
+		t.Errorf("Response = %+v before one was recorded, want nil", duplicate.Response)
+	}
+
+	// Later duplicates get the recorded response; recording for another
+	// job under the key is ignored
+	p.RecordIdempotentResponse("k", "first", IdempotentResponse{StatusCode: 202, Body: []byte("ok")})
+	p.RecordIdempotentResponse("k", "other", IdempotentResponse{StatusCode: 500})
+	if err := p.Submit(job); !errors.As(err, &duplicate) {
+		t.Fatalf("duplicate Submit = %v, want a DuplicateSubmissionError", err)
+	}
+	if resp := duplicate.Response; resp == nil || resp.StatusCode != 202 || string(resp.Body) != "ok" {
+		t.Errorf("Response = %+v, want the recorded 202", resp)
+	}
+
+	// The key cannot be reused for a different request
+	job.IdempotencyFingerprint = "body-2"
+	if err := p.Submit(job); !errors.Is(err, ErrIdempotencyKeyReused) {
+		t.Errorf("Submit with another fingerprint = %v, want ErrIdempotencyKeyReused", err)
+	}
+
+	if _, err := p.GetResult(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.GetJobStatus("second"); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("duplicate was tracked: %v", err)
+	}
+}
+
+func TestIdempotencyKeyFreedByRefusedSubmission(t *testing.T) {
+	p := NewPool(types.PoolConfig{WorkerCount: 1, IdempotencyWindow: time.Minute})
+	if err := p.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer p.Stop()
+
+	// A refused submission does not hold on to its key
+	if err := p.Submit(types.Job{IdempotencyKey: "k"}); !errors.Is(err, ErrMissingJobID) {
+		t.Fatalf("Submit without an ID = %v, want ErrMissingJobID", err)
+	}
+	if err := p.Submit(types.Job{ID: "retry", IdempotencyKey: "k"}); err != nil {
+		t.Errorf("Submit after a refused one: %v", err)
+	}
+}
+
+func TestSubmitAtClaimsIdempotencyKey(t *testing.T) {
+	p := startPool(t, types.PoolConfig{WorkerCount: 1, IdempotencyWindow: time.Minute})
+
+	if err := p.SubmitAt(types.Job{ID: "first", IdempotencyKey: "k"}, time.Now().Add(30*time.Millisecond)); err != nil {
+		t.Fatal(err)
+	}
+
+	// Every way of submitting a duplicate names the scheduled job
+	var duplicate *DuplicateSubmissionError
+	submits := map[string]func(types.Job) error{
+		"SubmitAt":    func(job types.Job) error { return p.SubmitAt(job, time.Now().Add(time.Hour)) },
+		"SubmitAfter": func(job types.Job) error { return p.SubmitAfter(job, time.Hour) },
+		"Submit":      p.Submit,
+	}
+	for name, submit := range submits {
+		if err := submit(types.Job{ID: "dup-" + name, IdempotencyKey: "k"}); !errors.As(err, &duplicate) || duplicate.JobID != "first" {
+			t.Errorf("duplicate %s = %v, want a DuplicateSubmissionError for first", name, err)
+		}
+	}
+	if n := len(p.GetScheduledJobs()); n != 1 {
+		t.Errorf("%d jobs scheduled, want 1", n)
+	}
+
+	result, err := p.GetResult()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.JobID != "first" {
+		t.Errorf("ran %s, want first", result.JobID)
+	}
+	noMoreResults(t, p)
+}
+
+func TestRecurringRunsDropIdempotencyKey(t *testing.T) {
+	runs := make(chan string, 10)
+	p := startPool(t, types.PoolConfig{
+		WorkerCount:       1,
+		IdempotencyWindow: time.Minute,
+		Handler: func(ctx context.Context, job types.Job) (interface{}, error) {
+			select {
+			case runs <- job.IdempotencyKey:
+			default:
+			}
+			return nil, nil
+		},
+	})
+	go func() {
+		for {
+			if _, err := p.GetResult(); err != nil {
+				return
+			}
+		}
+	}()
+
+	err := p.AddRecurringJob(types.RecurringJob{
+		Name: "tick",
+		Spec: "@every 10ms",
+		Job:  types.Job{IdempotencyKey: "tick"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.RemoveRecurringJob("tick")
+
+	// The shared key would deduplicate every run after the first
+	for i := 0; i < 3; i++ {
+		select {
+		case key := <-runs:
+			if key != "" {
+				t.Errorf("run %d has idempotency key %q, want none", i, key)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("only %d recurring runs ran", i)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cs-mastery/worker-pool/pkg/types"
+)
+
+// ErrLeaseExpired is the error of a job whose lease expired on every one
+// of its PoolConfig.MaxDeliveries deliveries. ExtendLease returns it once
+// the calling job has lost its lease.
+var ErrLeaseExpired = errors.New("job lease expired")
+
+// leaseExpired reports whether the attempt holds a lease that has expired
+// by now
+func (a *attempt) leaseExpired(now time.Time) bool {
+	until := a.leaseUntil.Load()
+	return until != 0 && now.UnixNano() > until
+}
+
+// ExtendLease moves the expiry of the lease on the job running under ctx
+// to d from now, for handlers that need longer than
+// PoolConfig.VisibilityTimeout. It returns ErrLeaseExpired if the job has
+// lost its lease and otherwise ctx.Err(). Without leases it only returns
+// ctx.Err().
+func ExtendLease(ctx context.Context, d time.Duration) error {
+	a, ok := ctx.Value(attemptKey{}).(*attempt)
+	if !ok || a.leaseUntil.Load() == 0 {
+		return ctx.Err()
+	}
+	if a.claimed.Load() {
+		return ErrLeaseExpired
+	}
+	a.leaseUntil.Store(time.Now().Add(d).UnixNano())
+	return ctx.Err()
+}
+
+// reapLeases hands jobs whose lease expired back to the queue. A worker
+// settles its lease when the handler returns: a success acks the job and
+// a failure nacks it, handing it to the retry policy. A lease that
+// expires first is settled here instead, and whatever the worker reports
+// later is discarded.
+func (p *Pool) reapLeases(ctx context.Context) {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(watchdogInterval(p.config.VisibilityTimeout))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case now := <-ticker.C:
+			for _, a := range p.expiredLeases(now) {
+				p.redeliver(ctx, a)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// expiredLeases returns the running attempts whose lease has expired
+func (p *Pool) expiredLeases(now time.Time) []*attempt {
+	p.inflightMu.Lock()
+	defer p.inflightMu.Unlock()
+
+	var expired []*attempt
+	for _, a := range p.inflight {
+		if a.leaseExpired(now) {
+			expired = append(expired, a)
+		}
+	}
+	return expired
+}
+
+// redeliver takes an expired attempt away from its worker and queues the
+// job again, or fails it with ErrLeaseExpired once it has used up
+// MaxDeliveries. The attempt's context is cancelled so a handler that is
+// still running can stop.
+func (p *Pool) redeliver(ctx context.Context, a *attempt) {
+	if !a.claim() {
+		// The worker settled the lease after all
+		return
+	}
+	a.cancel()
+
+	job := a.job
+	if max := p.config.MaxDeliveries; max > 0 && job.Deliveries >= max {
+		now := time.Now()
+		p.complete(ctx, job, types.JobResult{
+			JobID:      job.ID,
+			Error:      fmt.Errorf("%w after %d deliveries", ErrLeaseExpired, job.Deliveries),
+			WorkerID:   a.worker.id,
+			StartTime:  a.startedAt,
+			EndTime:    now,
+			Duration:   now.Sub(a.startedAt),
+			Attempt:    job.Attempt,
+			Deliveries: job.Deliveries,
+		})
+		return
+	}
+
+	p.inflightMu.Lock()
+	delete(p.inflight, job.ID)
+	p.inflightMu.Unlock()
+	p.releaseTypeSlot(job)
+	p.releaseTenant(job)
+	p.releaseKey(job)
+
+	p.metrics.IncrementJobsRedelivered()
+	now := time.Now()
+	p.jobs.transition(job.ID, types.JobScheduled, job.Attempt)
+	p.recordState(job, types.JobScheduled, now)
+	p.schedule(job, now)
+}
@@ -0,0 +1,124 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cs-mastery/worker-pool/pkg/types"
+)
+
+// noMoreResults fails the test if another result arrives soon
+func noMoreResults(t *testing.T, p *Pool) {
+	t.Helper()
+	select {
+	case result := <-p.resultQueue:
+		t.Errorf("unexpected result %s: %v, %v", result.JobID, result.Data, result.Error)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestExpiredLeaseIsRedelivered(t *testing.T) {
+	lateErr := make(chan error, 1)
+	p := startPool(t, types.PoolConfig{
+		WorkerCount:       2,
+		VisibilityTimeout: 30 * time.Millisecond,
+		EnableMetrics:     true,
+		MetricsInterval:   time.Hour,
+		Handler: func(ctx context.Context, job types.Job) (interface{}, error) {
+			if job.Deliveries == 1 {
+				// Stuck past the lease, then finishing anyway
+				<-ctx.Done()
+				lateErr <- ExtendLease(ctx, time.Minute)
+				return "late", nil
+			}
+			return "redelivered", nil
+		},
+	})
+
+	submitAll(t, p, types.Job{ID: "job"})
+	result, err := p.GetResult()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Data != "redelivered" || result.Deliveries != 2 || result.Error != nil {
+		t.Errorf("result = %v after %d deliveries (%v), want redelivered after 2", result.Data, result.Deliveries, result.Error)
+	}
+	if err := <-lateErr; !errors.Is(err, ErrLeaseExpired) {
+		t.Errorf("ExtendLease after expiry = %v, want ErrLeaseExpired", err)
+	}
+
+	// The first delivery's late result is discarded
+	noMoreResults(t, p)
+	if n := p.GetMetrics().JobsRedelivered; n != 1 {
+		t.Errorf("JobsRedelivered = %d, want 1", n)
+	}
+}
+
+func TestExtendLeasePreventsRedelivery(t *testing.T) {
+	var deliveries atomic.Int32
+	p := startPool(t, types.PoolConfig{
+		WorkerCount:       2,
+		VisibilityTimeout: 30 * time.Millisecond,
+		Handler: func(ctx context.Context, job types.Job) (interface{}, error) {
+			deliveries.Add(1)
+			// Four times the visibility timeout in all
+			for i := 0; i < 8; i++ {
+				time.Sleep(15 * time.Millisecond)
+				if err := ExtendLease(ctx, 30*time.Millisecond); err != nil {
+					return nil, err
+				}
+			}
+			return "done", nil
+		},
+	})
+
+	submitAll(t, p, types.Job{ID: "long"})
+	result, err := p.GetResult()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Error != nil || result.Data != "done" || result.Deliveries != 1 {
+		t.Errorf("result = %v, %v after %d deliveries; want done after 1", result.Data, result.Error, result.Deliveries)
+	}
+	if n := deliveries.Load(); n != 1 {
+		t.Errorf("handler ran %d times, want 1", n)
+	}
+	noMoreResults(t, p)
+}
+
+func TestLeaseMaxDeliveries(t *testing.T) {
+	var deliveries atomic.Int32
+	p := startPool(t, types.PoolConfig{
+		WorkerCount:       2,
+		VisibilityTimeout: 20 * time.Millisecond,
+		MaxDeliveries:     3,
+		Handler: func(ctx context.Context, job types.Job) (interface{}, error) {
+			deliveries.Add(1)
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+	})
+
+	submitAll(t, p, types.Job{ID: "stuck"})
+	result, err := p.GetResult()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !errors.Is(result.Error, ErrLeaseExpired) || result.Deliveries != 3 {
+		t.Errorf("result = %v after %d deliveries, want ErrLeaseExpired after 3", result.Error, result.Deliveries)
+	}
+	if n := deliveries.Load(); n != 3 {
+		t.Errorf("handler ran %d times, want 3", n)
+	}
+	record, err := p.GetJobStatus("stuck")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if record.Status != types.JobFailed {
+		t.Errorf("status = %s, want failed", record.Status)
+	}
+	noMoreResults(t, p)
+}
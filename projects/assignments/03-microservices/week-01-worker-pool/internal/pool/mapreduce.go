@@ -0,0 +1,191 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/cs-mastery/worker-pool/pkg/types"
+)
+
+// taskFunc is a job payload that runs itself: handle calls it instead of
+// any handler. Helpers such as MapReduce use it to run caller-supplied
+// functions on the pool's workers.
+type taskFunc func(ctx context.Context, job types.Job) (interface{}, error)
+
+// mapReduceRuns numbers MapReduce calls for generated job IDs
+var mapReduceRuns uint64
+
+// MapFunc transforms one input of a MapReduce into an intermediate value.
+// It runs on the pool's workers, so calls may run concurrently.
+type MapFunc[In, Mid any] func(ctx context.Context, input In) (Mid, error)
+
+// ReduceFunc folds one intermediate value into the accumulated output. It
+// runs in the caller's goroutine, one value at a time, in completion
+// order.
+type ReduceFunc[Mid, Out any] func(acc Out, value Mid) (Out, error)
+
+// MapReduceOption configures a MapReduce call
+type MapReduceOption func(*mapReduceConfig)
+
+// mapReduceConfig holds the settings of one MapReduce call
+type mapReduceConfig struct {
+	maxInFlight   int
+	collectErrors bool
+	progress      func(completed, total int)
+	template      types.Job
+}
+
+// WithMaxInFlight bounds how many map jobs are submitted but not finished
+// at once. It defaults to the pool's worker count.
+func WithMaxInFlight(n int) MapReduceOption {
+	return func(c *mapReduceConfig) { c.maxInFlight = n }
+}
+
+// WithCollectErrors keeps mapping after a map job fails and returns every
+// failure together. By default the first failure cancels the rest.
+func WithCollectErrors() MapReduceOption {
+	return func(c *mapReduceConfig) { c.collectErrors = true }
+}
+
+// WithProgress calls fn in the caller's goroutine each time a map job
+// finishes, successfully or not
+func WithProgress(fn func(completed, total int)) MapReduceOption {
+	return func(c *mapReduceConfig) { c.progress = fn }
+}
+
+// WithJobTemplate sets the metadata of every map job (Priority, Timeout,
+// Tenant, RetryPolicy, ...). A non-empty ID is used as the prefix of the
+// map jobs' IDs, which are "<prefix>-<input index>". The template's
+// IdempotencyKey is not copied, since every map job would share it.
+func WithJobTemplate(job types.Job) MapReduceOption {
+	return func(c *mapReduceConfig) { c.template = job }
+}
+
+// mapOutcome is the result of one map job
+type mapOutcome[Mid any] struct {
+	index int
+	value Mid
+	err   error
+}
+
+// MapReduce submits one job per input to p, each running mapFn, and folds
+// the results into an Out with reduceFn as they arrive, starting from the
+// zero Out.
+//
+// By default the first failure cancels the map jobs still in flight and
+// MapReduce returns the output reduced so far with that error. With
+// WithCollectErrors every input is mapped, only successful results are
+// reduced and the failures are returned joined. A reduceFn error or the
+// end of ctx always stops the run. MapReduce returns only once every map
+// job it submitted has finished.
+func MapReduce[In, Mid, Out any](ctx context.Context, p *Pool, inputs []In, mapFn MapFunc[In, Mid], reduceFn ReduceFunc[Mid, Out], opts ...MapReduceOption) (Out, error) {
+	config := mapReduceConfig{maxInFlight: p.GetWorkerCount()}
+	for _, opt := range opts {
+		opt(&config)
+	}
+	if config.maxInFlight < 1 {
+		config.maxInFlight = 1
+	}
+	prefix := config.template.ID
+	if prefix == "" {
+		prefix = fmt.Sprintf("mapreduce-%d", atomic.AddUint64(&mapReduceRuns, 1))
+	}
+
+	// Buffered for every job in flight so watchers never block a worker
+	outcomes := make(chan mapOutcome[Mid], config.maxInFlight)
+	inFlight := make(map[string]bool, config.maxInFlight)
+
+	var acc Out
+	var failures []error
+	var stopErr error // Set once the run stops early
+	done := ctx.Done()
+	next, completed := 0, 0
+	total := len(inputs)
+
+	stop := func(err error) {
+		if stopErr != nil {
+			return
+		}
+		stopErr = err
+		for id := range inFlight {
+			p.CancelJob(id)
+		}
+	}
+	// finished counts every map job that ends, but failures after the
+	// run stopped are fallout from stopping it
+	finished := func(err error) {
+		completed++
+		if err != nil && stopErr == nil {
+			if config.collectErrors {
+				failures = append(failures, err)
+			} else {
+				stop(err)
+			}
+		}
+		if config.progress != nil {
+			config.progress(completed, total)
+		}
+	}
+
+	for {
+		for stopErr == nil && next < total && len(inFlight) < config.maxInFlight {
+			index, input := next, inputs[next]
+			next++
+
+			job := config.template
+			job.ID = fmt.Sprintf("%s-%d", prefix, index)
+			job.Type = ""
+			job.IdempotencyKey, job.IdempotencyFingerprint = "", ""
+			job.Data = taskFunc(func(ctx context.Context, _ types.Job) (interface{}, error) {
+				return mapFn(ctx, input)
+			})
+			w := &watcher{exclusive: true, fn: func(result types.JobResult) {
+				outcome := mapOutcome[Mid]{index: index, err: result.Error}
+				if outcome.err == nil {
+					value, ok := result.Data.(Mid)
+					if !ok {
+						outcome.err = fmt.Errorf("map result is %T, want %T", result.Data, value)
+					}
+					outcome.value = value
+				}
+				outcomes <- outcome
+			}}
+
+			if err := p.submit(ctx, job, w); err != nil {
+				finished(fmt.Errorf("map input %d: %w", index, err))
+				continue
+			}
+			inFlight[job.ID] = true
+		}
+
+		if len(inFlight) == 0 {
+			break
+		}
+
+		select {
+		case outcome := <-outcomes:
+			delete(inFlight, fmt.Sprintf("%s-%d", prefix, outcome.index))
+			if outcome.err != nil {
+				finished(fmt.Errorf("map input %d: %w", outcome.index, outcome.err))
+				continue
+			}
+			finished(nil)
+			if stopErr == nil {
+				var err error
+				if acc, err = reduceFn(acc, outcome.value); err != nil {
+					stop(fmt.Errorf("reduce: %w", err))
+				}
+			}
+		case <-done:
+			done = nil
+			stop(ctx.Err())
+		}
+	}
+
+	if stopErr != nil {
+		failures = append(failures, stopErr)
+	}
+	return acc, errors.Join(failures...)
+}
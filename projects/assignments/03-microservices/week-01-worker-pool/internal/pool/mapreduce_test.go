@@ -0,0 +1,188 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cs-mastery/worker-pool/pkg/types"
+)
+
+// sum is a ReduceFunc adding up ints
+func sum(acc, value int) (int, error) {
+	return acc + value, nil
+}
+
+func TestMapReduceBoundsInFlight(t *testing.T) {
+	p := startPool(t, types.PoolConfig{WorkerCount: 8})
+
+	var running, maxRunning atomic.Int32
+	square := func(ctx context.Context, n int) (int, error) {
+		now := running.Add(1)
+		defer running.Add(-1)
+		for {
+			max := maxRunning.Load()
+			if now <= max || maxRunning.CompareAndSwap(max, now) {
+				break
+			}
+		}
+		time.Sleep(2 * time.Millisecond)
+		return n * n, nil
+	}
+
+	inputs := []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	got, err := MapReduce(context.Background(), p, inputs, square, sum, WithMaxInFlight(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != 385 {
+		t.Errorf("sum of squares = %d, want 385", got)
+	}
+	if n := maxRunning.Load(); n > 3 {
+		t.Errorf("%d map jobs ran at once, want at most 3", n)
+	}
+}
+
+func TestMapReduceStreamsReduce(t *testing.T) {
+	p := startPool(t, types.PoolConfig{WorkerCount: 4})
+
+	// With one job in flight each value is reduced before the next input
+	// is mapped
+	var mu sync.Mutex
+	var events []string
+	log := func(event string) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, event)
+	}
+	mapFn := func(ctx context.Context, s string) (string, error) {
+		log("map " + s)
+		return strings.ToUpper(s), nil
+	}
+	reduceFn := func(acc []string, value string) ([]string, error) {
+		log("reduce " + value)
+		return append(acc, value), nil
+	}
+
+	got, err := MapReduce(context.Background(), p, []string{"a", "b", "c"}, mapFn, reduceFn, WithMaxInFlight(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"A", "B", "C"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("output = %v, want %v", got, want)
+	}
+	want := []string{"map a", "reduce A", "map b", "reduce B", "map c", "reduce C"}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("events = %v, want %v", events, want)
+	}
+}
+
+func TestMapReduceErrors(t *testing.T) {
+	failOdd := func(ctx context.Context, n int) (int, error) {
+		if n%2 == 1 {
+			return 0, fmt.Errorf("odd input %d", n)
+		}
+		return n, nil
+	}
+	inputs := []int{0, 2, 4, 1, 6, 3, 8}
+
+	tests := []struct {
+		name      string
+		opts      []MapReduceOption
+		want      int
+		wantErrs  []string
+		wantCalls int // Progress calls, i.e. map jobs that ended
+	}{
+		{
+			name:      "fail fast",
+			opts:      []MapReduceOption{WithMaxInFlight(1)},
+			want:      6,
+			wantErrs:  []string{"map input 3: odd input 1"},
+			wantCalls: 4,
+		},
+		{
+			name:      "collect errors",
+			opts:      []MapReduceOption{WithMaxInFlight(2), WithCollectErrors()},
+			want:      20,
+			wantErrs:  []string{"map input 3: odd input 1", "map input 5: odd input 3"},
+			wantCalls: 7,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := startPool(t, types.PoolConfig{WorkerCount: 2})
+
+			var progress [][2]int
+			opts := append(tt.opts, WithProgress(func(completed, total int) {
+				progress = append(progress, [2]int{completed, total})
+			}))
+			got, err := MapReduce(context.Background(), p, inputs, failOdd, sum, opts...)
+
+			if got != tt.want {
+				t.Errorf("output = %d, want %d", got, tt.want)
+			}
+			for _, want := range tt.wantErrs {
+				if err == nil || !strings.Contains(err.Error(), want) {
+					t.Errorf("error = %v, want it to contain %q", err, want)
+				}
+			}
+			if len(progress) != tt.wantCalls {
+				t.Fatalf("progress = %v, want %d calls", progress, tt.wantCalls)
+			}
+			for i, call := range progress {
+				if call != [2]int{i + 1, len(inputs)} {
+					t.Errorf("progress call %d = %v, want %v", i, call, [2]int{i + 1, len(inputs)})
+				}
+			}
+		})
+	}
+}
+
+func TestMapReduceCancelledRunReportsEveryJob(t *testing.T) {
+	p := startPool(t, types.PoolConfig{WorkerCount: 4})
+
+	// The first failure cancels the other three jobs in flight, which
+	// still count towards progress
+	started := make(chan struct{}, 4)
+	mapFn := func(ctx context.Context, n int) (int, error) {
+		started <- struct{}{}
+		if n == 0 {
+			for i := 0; i < 3; i++ {
+				<-started
+			}
+			return 0, errors.New("boom")
+		}
+		<-ctx.Done()
+		return 0, ctx.Err()
+	}
+
+	completed := 0
+	_, err := MapReduce(context.Background(), p, []int{0, 1, 2, 3, 4, 5}, mapFn, sum,
+		WithMaxInFlight(4), WithProgress(func(n, total int) { completed = n }))
+	if err == nil || !strings.Contains(err.Error(), "boom") || errors.Is(err, context.Canceled) {
+		t.Errorf("error = %v, want only the first failure", err)
+	}
+	if completed != 4 {
+		t.Errorf("progress reached %d, want the 4 submitted jobs", completed)
+	}
+}
+
+func TestMapReduceTemplateIdempotencyKey(t *testing.T) {
+	p := startPool(t, types.PoolConfig{WorkerCount: 2, IdempotencyWindow: time.Minute})
+
+	identity := func(ctx context.Context, n int) (int, error) { return n, nil }
+	template := types.Job{ID: "keyed", IdempotencyKey: "shared", Priority: 5}
+	got, err := MapReduce(context.Background(), p, []int{1, 2, 3, 4}, identity, sum, WithJobTemplate(template))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != 10 {
+		t.Errorf("sum = %d, want 10: map jobs were deduplicated by the template's key", got)
+	}
+}
@@ -1,6 +1,7 @@
 package pool
 
 import (
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -9,275 +10,524 @@ import (
 
 // Metrics collects and tracks performance metrics for the worker pool
 type Metrics struct {
-	// TODO: Add metrics fields
-	// Hints:
-	// - Use atomic operations for counters to avoid locks
-	// - Track various performance metrics
-	// - Include timing information
-	// - Add fields for rates and averages
-
-	// Example implementation:
-	// jobsSubmitted   int64
-	// jobsProcessed   int64
-	// jobsSucceeded   int64
-	// jobsFailed      int64
-	// totalLatency    int64 // in nanoseconds
-	// activeWorkers   int32
-	// queueLength     int32
-	// totalWorkers    int32
-	// startTime       time.Time
-	// mu              sync.RWMutex
-	// enabled         bool
-	// updateInterval  time.Duration
+	jobsSubmitted int64
+	jobsProcessed int64
+	jobsSucceeded int64
+	jobsFailed    int64
+	jobsRetried   int64
+	jobsCancelled int64
+	restarts      int64 // workers restarted after a crash
+	jobsAbandoned int64
+	redelivered   int64 // jobs requeued after their lease expired
+
+	// Overflow policy outcomes
+	submitsBlocked    int64
+	submitsRejected   int64
+	jobsDroppedOldest int64
+	jobsDroppedNewest int64
+	jobsRunByCaller   int64
+	deduplicated      int64 // submissions suppressed by idempotency key
+	totalLatency      int64 // in nanoseconds
+	attempts          int64 // handler calls seen by MetricsMiddleware
+	attemptErrors     int64
+	handlerTime       int64 // in nanoseconds
+	activeWorkers     int32
+	queueLength       int32
+	totalWorkers      int32
+
+	startTime      time.Time
+	mu             sync.RWMutex
+	enabled        bool
+	updateInterval time.Duration
+	done           chan struct{}
+
+	// Derived values refreshed by updateLoop, guarded by mu
+	jobsPerSecond float64
+	lastProcessed int64
+	lastUpdate    time.Time
+
+	// Queue wait time per priority band, guarded by waitMu
+	waitTimeByBand map[string]*waitTimeStat
+	waitMu         sync.Mutex
+
+	// Counts and latency per job type, guarded by typeMu
+	byType map[string]*typeStat
+	typeMu sync.Mutex
+
+	// Counts per tenant, guarded by tenantMu
+	byTenant map[string]*tenantStat
+	tenantMu sync.Mutex
+}
+
+// tenantStat accumulates counts for one tenant
+type tenantStat struct {
+	submitted int64
+	processed int64
+	rejected  int64
+}
+
+// typeStat accumulates counts and latency for one job type
+type typeStat struct {
+	submitted    int64
+	succeeded    int64
+	failed       int64
+	totalLatency time.Duration
+}
+
+// waitTimeStat accumulates queue wait times for one priority band
+type waitTimeStat struct {
+	jobs  int64
+	total time.Duration
+	max   time.Duration
 }
 
 // NewMetrics creates a new metrics collector
 func NewMetrics(enabled bool, updateInterval time.Duration) *Metrics {
-	// TODO: Implement metrics creation
-	// Hints:
-	// - Initialize all fields
-	// - Set start time
-	// - Configure update interval
-	// - Return new metrics instance
+	if updateInterval <= 0 {
+		updateInterval = time.Second
+	}
 
+	now := time.Now()
 	return &Metrics{
-		// TODO: Initialize fields
-		// Example:
-		// startTime:      time.Now(),
-		// enabled:        enabled,
-		// updateInterval: updateInterval,
+		startTime:      now,
+		lastUpdate:     now,
+		enabled:        enabled,
+		updateInterval: updateInterval,
+		waitTimeByBand: make(map[string]*waitTimeStat),
+		byType:         make(map[string]*typeStat),
+		byTenant:       make(map[string]*tenantStat),
 	}
 }
 
 // Start begins metrics collection
 func (m *Metrics) Start() {
-	// TODO: Implement metrics collection startup
-	// Hints:
-	// - Check if metrics are enabled
-	// - Start background goroutine for periodic updates
-	// - Handle graceful shutdown
-
 	if !m.enabled {
 		return
 	}
 
-	// TODO: Add implementation
-	// Example structure:
-	// go m.updateLoop()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.done != nil {
+		return
+	}
+	m.done = make(chan struct{})
+	go m.updateLoop(m.done)
 }
 
 // Stop stops metrics collection
 func (m *Metrics) Stop() {
-	// TODO: Implement metrics collection shutdown
-	// Hints:
-	// - Set enabled flag to false
-	// - Signal background goroutine to stop
-	// - Wait for cleanup if needed
-
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	m.enabled = false
-}
 
-// updateLoop runs periodic metrics updates
-func (m *Metrics) updateLoop() {
-	// TODO: Implement periodic metrics updates
-	// Hints:
-	// - Use ticker for regular intervals
-	// - Update calculated metrics (rates, averages)
-	// - Continue until metrics are disabled
+	if m.done != nil {
+		close(m.done)
+		m.done = nil
+	}
+}
 
+// updateLoop runs periodic metrics updates until done is closed
+func (m *Metrics) updateLoop(done <-chan struct{}) {
 	ticker := time.NewTicker(m.updateInterval)
 	defer ticker.Stop()
 
-	// TODO: Add implementation
-	// Example structure:
-	// for {
-	//     select {
-	//     case <-ticker.C:
-	//         if !m.enabled {
-	//             return
-	//         }
-	//         m.updateCalculatedMetrics()
-	//     }
-	// }
+	for {
+		select {
+		case <-ticker.C:
+			m.updateCalculatedMetrics()
+		case <-done:
+			return
+		}
+	}
 }
 
 // updateCalculatedMetrics updates derived metrics
 func (m *Metrics) updateCalculatedMetrics() {
-	// TODO: Implement calculated metrics updates
-	// Hints:
-	// - Calculate rates (jobs per second)
-	// - Update average latency
-	// - Calculate success/failure rates
-	// - Update other derived metrics
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	processed := atomic.LoadInt64(&m.jobsProcessed)
+	elapsed := now.Sub(m.lastUpdate).Seconds()
+	if elapsed > 0 {
+		m.jobsPerSecond = float64(processed-m.lastProcessed) / elapsed
+	}
+	m.lastProcessed = processed
+	m.lastUpdate = now
 }
 
 // IncrementJobsSubmitted increments the jobs submitted counter
 func (m *Metrics) IncrementJobsSubmitted() {
-	// TODO: Implement jobs submitted increment
-	// Hints:
-	// - Use atomic operation for thread safety
-	// - Only increment if metrics are enabled
-
 	if !m.enabled {
 		return
 	}
-
-	// TODO: Add implementation
-	// Example: atomic.AddInt64(&m.jobsSubmitted, 1)
+	atomic.AddInt64(&m.jobsSubmitted, 1)
 }
 
 // IncrementJobsProcessed increments the jobs processed counter
 func (m *Metrics) IncrementJobsProcessed() {
-	// TODO: Implement jobs processed increment
-	// Hints:
-	// - Use atomic operation for thread safety
-	// - Only increment if metrics are enabled
-
 	if !m.enabled {
 		return
 	}
-
-	// TODO: Add implementation
-	// Example: atomic.AddInt64(&m.jobsProcessed, 1)
+	atomic.AddInt64(&m.jobsProcessed, 1)
 }
 
 // IncrementJobsSucceeded increments the jobs succeeded counter
 func (m *Metrics) IncrementJobsSucceeded() {
-	// TODO: Implement jobs succeeded increment
-	// Hints:
-	// - Use atomic operation for thread safety
-	// - Also increment jobs processed
-	// - Only increment if metrics are enabled
-
 	if !m.enabled {
 		return
 	}
-
-	// TODO: Add implementation
-	// Example:
-	// atomic.AddInt64(&m.jobsSucceeded, 1)
-	// m.IncrementJobsProcessed()
+	atomic.AddInt64(&m.jobsSucceeded, 1)
+	m.IncrementJobsProcessed()
 }
 
 // IncrementJobsFailed increments the jobs failed counter
 func (m *Metrics) IncrementJobsFailed() {
-	// TODO: Implement jobs failed increment
-	// Hints:
-	// - Use atomic operation for thread safety
-	// - Also increment jobs processed
-	// - Only increment if metrics are enabled
+	if !m.enabled {
+		return
+	}
+	atomic.AddInt64(&m.jobsFailed, 1)
+	m.IncrementJobsProcessed()
+}
+
+// IncrementJobsRetried increments the counter of attempts scheduled for
+// retry
+func (m *Metrics) IncrementJobsRetried() {
+	if !m.enabled {
+		return
+	}
+	atomic.AddInt64(&m.jobsRetried, 1)
+}
+
+// IncrementJobsCancelled increments the counter of cancelled jobs
+func (m *Metrics) IncrementJobsCancelled() {
+	if !m.enabled {
+		return
+	}
+	atomic.AddInt64(&m.jobsCancelled, 1)
+}
+
+// IncrementWorkerRestarts increments the counter of workers restarted
+// after a crash
+func (m *Metrics) IncrementWorkerRestarts() {
+	if !m.enabled {
+		return
+	}
+	atomic.AddInt64(&m.restarts, 1)
+}
+
+// IncrementJobsAbandoned increments the counter of jobs abandoned by the
+// watchdog
+func (m *Metrics) IncrementJobsAbandoned() {
+	if !m.enabled {
+		return
+	}
+	atomic.AddInt64(&m.jobsAbandoned, 1)
+}
+
+// IncrementJobsRedelivered increments the counter of jobs handed back to
+// the queue after their lease expired
+func (m *Metrics) IncrementJobsRedelivered() {
+	if !m.enabled {
+		return
+	}
+	atomic.AddInt64(&m.redelivered, 1)
+}
+
+// IncrementSubmitsBlocked counts a submission that waited for queue space
+func (m *Metrics) IncrementSubmitsBlocked() {
+	if !m.enabled {
+		return
+	}
+	atomic.AddInt64(&m.submitsBlocked, 1)
+}
+
+// IncrementSubmitsRejected counts a submission refused because the queue
+// was full
+func (m *Metrics) IncrementSubmitsRejected() {
+	if !m.enabled {
+		return
+	}
+	atomic.AddInt64(&m.submitsRejected, 1)
+}
+
+// IncrementJobsDroppedOldest counts a queued job evicted for a newer one
+func (m *Metrics) IncrementJobsDroppedOldest() {
+	if !m.enabled {
+		return
+	}
+	atomic.AddInt64(&m.jobsDroppedOldest, 1)
+}
+
+// IncrementJobsDroppedNewest counts a new job dropped because the queue
+// was full
+func (m *Metrics) IncrementJobsDroppedNewest() {
+	if !m.enabled {
+		return
+	}
+	atomic.AddInt64(&m.jobsDroppedNewest, 1)
+}
 
+// IncrementJobsRunByCaller counts a job run by its submitter because the
+// queue was full
+func (m *Metrics) IncrementJobsRunByCaller() {
 	if !m.enabled {
 		return
 	}
+	atomic.AddInt64(&m.jobsRunByCaller, 1)
+}
 
-	// TODO: Add implementation
-	// Example:
-	// atomic.AddInt64(&m.jobsFailed, 1)
-	// m.IncrementJobsProcessed()
+// IncrementSubmitsDeduplicated counts a submission suppressed because its
+// idempotency key was already used
+func (m *Metrics) IncrementSubmitsDeduplicated() {
+	if !m.enabled {
+		return
+	}
+	atomic.AddInt64(&m.deduplicated, 1)
 }
 
 // AddLatency adds a job latency measurement
 func (m *Metrics) AddLatency(duration time.Duration) {
-	// TODO: Implement latency tracking
-	// Hints:
-	// - Use atomic operation for thread safety
-	// - Convert duration to nanoseconds
-	// - Add to total latency for average calculation
-	// - Only track if metrics are enabled
+	if !m.enabled {
+		return
+	}
+	atomic.AddInt64(&m.totalLatency, int64(duration))
+}
 
+// RecordAttempt records one handler call and how long it took
+func (m *Metrics) RecordAttempt(duration time.Duration, err error) {
 	if !m.enabled {
 		return
 	}
+	atomic.AddInt64(&m.attempts, 1)
+	atomic.AddInt64(&m.handlerTime, int64(duration))
+	if err != nil {
+		atomic.AddInt64(&m.attemptErrors, 1)
+	}
+}
+
+// RecordWaitTime records how long a job of the given priority waited in
+// the queue before a worker picked it up
+func (m *Metrics) RecordWaitTime(priority int, wait time.Duration) {
+	if !m.enabled {
+		return
+	}
+
+	band := types.PriorityBand(priority)
+
+	m.waitMu.Lock()
+	defer m.waitMu.Unlock()
+
+	stat, ok := m.waitTimeByBand[band]
+	if !ok {
+		stat = &waitTimeStat{}
+		m.waitTimeByBand[band] = stat
+	}
+	stat.jobs++
+	stat.total += wait
+	if wait > stat.max {
+		stat.max = wait
+	}
+}
+
+// IncrementTypeSubmitted counts a submitted job of the given type.
+// Untyped jobs are not broken down.
+func (m *Metrics) IncrementTypeSubmitted(jobType string) {
+	if !m.enabled || jobType == "" {
+		return
+	}
+
+	m.typeMu.Lock()
+	defer m.typeMu.Unlock()
+	m.typeStatLocked(jobType).submitted++
+}
+
+// RecordTypeResult records the final outcome and latency of a job of the
+// given type
+func (m *Metrics) RecordTypeResult(jobType string, latency time.Duration, succeeded bool) {
+	if !m.enabled || jobType == "" {
+		return
+	}
+
+	m.typeMu.Lock()
+	defer m.typeMu.Unlock()
+
+	stat := m.typeStatLocked(jobType)
+	if succeeded {
+		stat.succeeded++
+	} else {
+		stat.failed++
+	}
+	stat.totalLatency += latency
+}
+
+// typeStatLocked returns the stats for a job type. Caller holds typeMu.
+func (m *Metrics) typeStatLocked(jobType string) *typeStat {
+	stat, ok := m.byType[jobType]
+	if !ok {
+		stat = &typeStat{}
+		m.byType[jobType] = stat
+	}
+	return stat
+}
+
+// IncrementTenantSubmitted counts a submitted job of the given tenant.
+// Jobs without a tenant are not broken down.
+func (m *Metrics) IncrementTenantSubmitted(tenant string) {
+	m.updateTenant(tenant, func(stat *tenantStat) { stat.submitted++ })
+}
+
+// IncrementTenantProcessed counts a finished job of the given tenant
+func (m *Metrics) IncrementTenantProcessed(tenant string) {
+	m.updateTenant(tenant, func(stat *tenantStat) { stat.processed++ })
+}
+
+// IncrementTenantRejected counts a refused submission of the given tenant
+func (m *Metrics) IncrementTenantRejected(tenant string) {
+	m.updateTenant(tenant, func(stat *tenantStat) { stat.rejected++ })
+}
+
+// updateTenant applies fn to a tenant's stats under tenantMu
+func (m *Metrics) updateTenant(tenant string, fn func(stat *tenantStat)) {
+	if !m.enabled || tenant == "" {
+		return
+	}
 
-	// TODO: Add implementation
-	// Example: atomic.AddInt64(&m.totalLatency, int64(duration))
+	m.tenantMu.Lock()
+	defer m.tenantMu.Unlock()
+
+	stat, ok := m.byTenant[tenant]
+	if !ok {
+		stat = &tenantStat{}
+		m.byTenant[tenant] = stat
+	}
+	fn(stat)
 }
 
 // SetActiveWorkers sets the current number of active workers
 func (m *Metrics) SetActiveWorkers(count int32) {
-	// TODO: Implement active workers setter
-	// Hints:
-	// - Use atomic operation for thread safety
-	// - Only set if metrics are enabled
-
 	if !m.enabled {
 		return
 	}
-
-	// TODO: Add implementation
-	// Example: atomic.StoreInt32(&m.activeWorkers, count)
+	atomic.StoreInt32(&m.activeWorkers, count)
 }
 
 // SetQueueLength sets the current queue length
 func (m *Metrics) SetQueueLength(length int32) {
-	// TODO: Implement queue length setter
-	// Hints:
-	// - Use atomic operation for thread safety
-	// - Only set if metrics are enabled
-
 	if !m.enabled {
 		return
 	}
-
-	// TODO: Add implementation
-	// Example: atomic.StoreInt32(&m.queueLength, length)
+	atomic.StoreInt32(&m.queueLength, length)
 }
 
 // SetTotalWorkers sets the total number of workers
 func (m *Metrics) SetTotalWorkers(count int32) {
-	// TODO: Implement total workers setter
-	// Hints:
-	// - Use atomic operation for thread safety
-	// - Only set if metrics are enabled
-
 	if !m.enabled {
 		return
 	}
-
-	// TODO: Add implementation
-	// Example: atomic.StoreInt32(&m.totalWorkers, count)
+	atomic.StoreInt32(&m.totalWorkers, count)
 }
 
 // GetSnapshot returns a snapshot of current metrics
 func (m *Metrics) GetSnapshot() types.PoolMetrics {
-	// TODO: Implement metrics snapshot
-	// Hints:
-	// - Read all metrics atomically
-	// - Calculate derived values (averages, rates)
-	// - Return structured metrics
-	// - Handle case where metrics are disabled
-
 	if !m.enabled {
 		return types.PoolMetrics{}
 	}
 
-	// TODO: Add implementation
-	// Example structure:
-	// return types.PoolMetrics{
-	//     JobsSubmitted:  atomic.LoadInt64(&m.jobsSubmitted),
-	//     JobsProcessed:  atomic.LoadInt64(&m.jobsProcessed),
-	//     JobsSucceeded:  atomic.LoadInt64(&m.jobsSucceeded),
-	//     JobsFailed:     atomic.LoadInt64(&m.jobsFailed),
-	//     AverageLatency: m.calculateAverageLatency(),
-	//     ActiveWorkers:  atomic.LoadInt32(&m.activeWorkers),
-	//     QueueLength:    atomic.LoadInt32(&m.queueLength),
-	//     TotalWorkers:   atomic.LoadInt32(&m.totalWorkers),
-	// }
+	m.mu.RLock()
+	jobsPerSecond := m.jobsPerSecond
+	startTime := m.startTime
+	m.mu.RUnlock()
+
+	return types.PoolMetrics{
+		JobsSubmitted:       atomic.LoadInt64(&m.jobsSubmitted),
+		JobsProcessed:       atomic.LoadInt64(&m.jobsProcessed),
+		JobsSucceeded:       atomic.LoadInt64(&m.jobsSucceeded),
+		JobsFailed:          atomic.LoadInt64(&m.jobsFailed),
+		JobsRetried:         atomic.LoadInt64(&m.jobsRetried),
+		JobsCancelled:       atomic.LoadInt64(&m.jobsCancelled),
+		WorkerRestarts:      atomic.LoadInt64(&m.restarts),
+		JobsAbandoned:       atomic.LoadInt64(&m.jobsAbandoned),
+		JobsRedelivered:     atomic.LoadInt64(&m.redelivered),
+		SubmitsBlocked:      atomic.LoadInt64(&m.submitsBlocked),
+		SubmitsRejected:     atomic.LoadInt64(&m.submitsRejected),
+		JobsDroppedOldest:   atomic.LoadInt64(&m.jobsDroppedOldest),
+		JobsDroppedNewest:   atomic.LoadInt64(&m.jobsDroppedNewest),
+		JobsRunByCaller:     atomic.LoadInt64(&m.jobsRunByCaller),
+		SubmitsDeduplicated: atomic.LoadInt64(&m.deduplicated),
+		AverageLatency:      m.calculateAverageLatency(),
+		Attempts:            atomic.LoadInt64(&m.attempts),
+		AttemptFailures:     atomic.LoadInt64(&m.attemptErrors),
+		AverageHandlerTime:  m.calculateAverageHandlerTime(),
+		JobsPerSecond:       jobsPerSecond,
+		ActiveWorkers:       atomic.LoadInt32(&m.activeWorkers),
+		QueueLength:         atomic.LoadInt32(&m.queueLength),
+		TotalWorkers:        atomic.LoadInt32(&m.totalWorkers),
+		WaitTimeByPriority:  m.waitTimeSnapshot(),
+		JobTypes:            m.typeSnapshot(),
+		Tenants:             m.tenantSnapshot(time.Since(startTime)),
+	}
+}
+
+// tenantSnapshot copies the per-tenant statistics, computing throughput
+// over elapsed
+func (m *Metrics) tenantSnapshot(elapsed time.Duration) map[string]types.TenantMetrics {
+	m.tenantMu.Lock()
+	defer m.tenantMu.Unlock()
+
+	snapshot := make(map[string]types.TenantMetrics, len(m.byTenant))
+	for tenant, stat := range m.byTenant {
+		stats := types.TenantMetrics{
+			Submitted: stat.submitted,
+			Processed: stat.processed,
+			Rejected:  stat.rejected,
+		}
+		if seconds := elapsed.Seconds(); seconds > 0 {
+			stats.JobsPerSecond = float64(stat.processed) / seconds
+		}
+		snapshot[tenant] = stats
+	}
+	return snapshot
+}
+
+// typeSnapshot copies the per-type statistics
+func (m *Metrics) typeSnapshot() map[string]types.JobTypeMetrics {
+	m.typeMu.Lock()
+	defer m.typeMu.Unlock()
+
+	snapshot := make(map[string]types.JobTypeMetrics, len(m.byType))
+	for jobType, stat := range m.byType {
+		stats := types.JobTypeMetrics{
+			Submitted: stat.submitted,
+			Succeeded: stat.succeeded,
+			Failed:    stat.failed,
+		}
+		if finished := stat.succeeded + stat.failed; finished > 0 {
+			stats.AverageLatency = stat.totalLatency / time.Duration(finished)
+		}
+		snapshot[jobType] = stats
+	}
+	return snapshot
+}
 
-	return types.PoolMetrics{} // TODO: Remove this line when implementing
+// waitTimeSnapshot copies the per-band wait time statistics
+func (m *Metrics) waitTimeSnapshot() map[string]types.WaitTimeStats {
+	m.waitMu.Lock()
+	defer m.waitMu.Unlock()
+
+	snapshot := make(map[string]types.WaitTimeStats, len(m.waitTimeByBand))
+	for band, stat := range m.waitTimeByBand {
+		snapshot[band] = types.WaitTimeStats{
+			Jobs:    stat.jobs,
+			Average: stat.total / time.Duration(stat.jobs),
+			Max:     stat.max,
+		}
+	}
+	return snapshot
 }
 
 // calculateAverageLatency calculates the average job latency
 func (m *Metrics) calculateAverageLatency() time.Duration {
-	// TODO: Implement average latency calculation
-	// Hints:
-	// - Read total latency and jobs processed atomically
-	// - Handle division by zero
-	// - Return average as time.Duration
-
 	processed := atomic.LoadInt64(&m.jobsProcessed)
 	if processed == 0 {
 		return 0
@@ -287,15 +537,22 @@ func (m *Metrics) calculateAverageLatency() time.Duration {
 	return time.Duration(totalLatency / processed)
 }
 
+// calculateAverageHandlerTime calculates the average handler call time
+func (m *Metrics) calculateAverageHandlerTime() time.Duration {
+	attempts := atomic.LoadInt64(&m.attempts)
+	if attempts == 0 {
+		return 0
+	}
+	return time.Duration(atomic.LoadInt64(&m.handlerTime) / attempts)
+}
+
 // GetJobsPerSecond calculates the current jobs per second rate
 func (m *Metrics) GetJobsPerSecond() float64 {
-	// TODO: Implement jobs per second calculation
-	// Hints:
-	// - Calculate based on jobs processed and elapsed time
-	// - Handle edge cases (zero time, zero jobs)
-	// - Return rate as float64
+	m.mu.RLock()
+	startTime := m.startTime
+	m.mu.RUnlock()
 
-	elapsed := time.Since(m.startTime).Seconds()
+	elapsed := time.Since(startTime).Seconds()
 	if elapsed == 0 {
 		return 0
 	}
@@ -306,12 +563,6 @@ func (m *Metrics) GetJobsPerSecond() float64 {
 
 // GetSuccessRate calculates the job success rate as a percentage
 func (m *Metrics) GetSuccessRate() float64 {
-	// TODO: Implement success rate calculation
-	// Hints:
-	// - Calculate based on succeeded vs processed jobs
-	// - Handle division by zero
-	// - Return percentage (0.0 to 100.0)
-
 	processed := atomic.LoadInt64(&m.jobsProcessed)
 	if processed == 0 {
 		return 0
@@ -323,12 +574,6 @@ func (m *Metrics) GetSuccessRate() float64 {
 
 // GetFailureRate calculates the job failure rate as a percentage
 func (m *Metrics) GetFailureRate() float64 {
-	// TODO: Implement failure rate calculation
-	// Hints:
-	// - Calculate based on failed vs processed jobs
-	// - Handle division by zero
-	// - Return percentage (0.0 to 100.0)
-
 	processed := atomic.LoadInt64(&m.jobsProcessed)
 	if processed == 0 {
 		return 0
@@ -340,24 +585,49 @@ func (m *Metrics) GetFailureRate() float64 {
 
 // Reset resets all metrics to zero
 func (m *Metrics) Reset() {
-	// TODO: Implement metrics reset
-	// Hints:
-	// - Reset all atomic counters to zero
-	// - Reset start time to current time
-	// - Only reset if metrics are enabled
-
 	if !m.enabled {
 		return
 	}
 
-	// TODO: Add implementation
-	// Example:
-	// atomic.StoreInt64(&m.jobsSubmitted, 0)
-	// atomic.StoreInt64(&m.jobsProcessed, 0)
-	// atomic.StoreInt64(&m.jobsSucceeded, 0)
-	// atomic.StoreInt64(&m.jobsFailed, 0)
-	// atomic.StoreInt64(&m.totalLatency, 0)
-	// m.startTime = time.Now()
+	atomic.StoreInt64(&m.jobsSubmitted, 0)
+	atomic.StoreInt64(&m.jobsProcessed, 0)
+	atomic.StoreInt64(&m.jobsSucceeded, 0)
+	atomic.StoreInt64(&m.jobsFailed, 0)
+	atomic.StoreInt64(&m.jobsRetried, 0)
+	atomic.StoreInt64(&m.jobsCancelled, 0)
+	atomic.StoreInt64(&m.restarts, 0)
+	atomic.StoreInt64(&m.jobsAbandoned, 0)
+	atomic.StoreInt64(&m.redelivered, 0)
+	atomic.StoreInt64(&m.submitsBlocked, 0)
+	atomic.StoreInt64(&m.submitsRejected, 0)
+	atomic.StoreInt64(&m.jobsDroppedOldest, 0)
+	atomic.StoreInt64(&m.jobsDroppedNewest, 0)
+	atomic.StoreInt64(&m.jobsRunByCaller, 0)
+	atomic.StoreInt64(&m.deduplicated, 0)
+	atomic.StoreInt64(&m.totalLatency, 0)
+	atomic.StoreInt64(&m.attempts, 0)
+	atomic.StoreInt64(&m.attemptErrors, 0)
+	atomic.StoreInt64(&m.handlerTime, 0)
+
+	m.waitMu.Lock()
+	m.waitTimeByBand = make(map[string]*waitTimeStat)
+	m.waitMu.Unlock()
+
+	m.typeMu.Lock()
+	m.byType = make(map[string]*typeStat)
+	m.typeMu.Unlock()
+
+	m.tenantMu.Lock()
+	m.byTenant = make(map[string]*tenantStat)
+	m.tenantMu.Unlock()
+
+	m.mu.Lock()
+	now := time.Now()
+	m.startTime = now
+	m.lastUpdate = now
+	m.lastProcessed = 0
+	m.jobsPerSecond = 0
+	m.mu.Unlock()
 }
 
 // IsEnabled returns whether metrics collection is enabled
@@ -0,0 +1,119 @@
+package pool
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/cs-mastery/worker-pool/pkg/types"
+)
+
+// Middleware wraps a job handler, e.g. to add logging or tracing
+type Middleware = types.Middleware
+
+// Chain wraps handler with middlewares. The first middleware is the
+// outermost, so Chain(h, A, B) returns A(B(h)): A runs first and sees the
+// result last.
+func Chain(handler types.JobHandler, middlewares ...Middleware) types.JobHandler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
+
+// Use appends middlewares to the pool's chain. It is safe to call while
+// the pool is running: every worker picks up the new chain with its next
+// job, and jobs already running finish with the old one.
+func (p *Pool) Use(middlewares ...Middleware) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.config.Middlewares = append(p.config.Middlewares, middlewares...)
+	p.setChain(p.config.Middlewares)
+}
+
+// setChain wraps the pool's handler in middlewares and publishes the
+// result to the workers
+func (p *Pool) setChain(middlewares []Middleware) {
+	chain := Chain(p.handle, middlewares...)
+	p.chain.Store(&chain)
+}
+
+// runChain runs a job through the current middleware chain. Pool workers
+// use it as their handler, so the chain is loaded once per job.
+func (p *Pool) runChain(ctx context.Context, job types.Job) (interface{}, error) {
+	return (*p.chain.Load())(ctx, job)
+}
+
+// RecoverMiddleware turns a panic in the handler into a *PanicError, so
+// middlewares outside it see the failure. Workers recover panics that
+// reach them in the same way.
+func RecoverMiddleware() Middleware {
+	return func(next types.JobHandler) types.JobHandler {
+		return func(ctx context.Context, job types.Job) (data interface{}, err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					data, err = nil, newPanicError(job.ID, r)
+				}
+			}()
+			return next(ctx, job)
+		}
+	}
+}
+
+// LoggingMiddleware logs the start and outcome of every attempt. A nil
+// logger uses slog.Default().
+func LoggingMiddleware(logger *slog.Logger) Middleware {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return func(next types.JobHandler) types.JobHandler {
+		return func(ctx context.Context, job types.Job) (interface{}, error) {
+			attrs := []any{
+				slog.String("job_id", job.ID),
+				slog.String("job_type", job.Type),
+				slog.Int("attempt", job.Attempt),
+			}
+			logger.InfoContext(ctx, "job started", attrs...)
+
+			start := time.Now()
+			data, err := next(ctx, job)
+			attrs = append(attrs, slog.Duration("duration", time.Since(start)))
+
+			if err != nil {
+				logger.ErrorContext(ctx, "job failed", append(attrs, slog.String("error", err.Error()))...)
+			} else {
+				logger.InfoContext(ctx, "job succeeded", attrs...)
+			}
+			return data, err
+		}
+	}
+}
+
+// TimeoutMiddleware bounds each attempt at d, on top of the job timeout
+func TimeoutMiddleware(d time.Duration) Middleware {
+	return func(next types.JobHandler) types.JobHandler {
+		return func(ctx context.Context, job types.Job) (interface{}, error) {
+			if d <= 0 {
+				return next(ctx, job)
+			}
+
+			ctx, cancel := context.WithTimeout(ctx, d)
+			defer cancel()
+			return next(ctx, job)
+		}
+	}
+}
+
+// MetricsMiddleware records the count, failures and duration of handler
+// calls in m (see Pool.Metrics)
+func MetricsMiddleware(m *Metrics) Middleware {
+	return func(next types.JobHandler) types.JobHandler {
+		return func(ctx context.Context, job types.Job) (interface{}, error) {
+			start := time.Now()
+			data, err := next(ctx, job)
+			m.RecordAttempt(time.Since(start), err)
+			return data, err
+		}
+	}
+}
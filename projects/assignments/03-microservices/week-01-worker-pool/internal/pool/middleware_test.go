@@ -0,0 +1,81 @@
+package pool
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/cs-mastery/worker-pool/pkg/types"
+)
+
+// tag returns a middleware that appends name to a string result
+func tag(name string) Middleware {
+	return func(next types.JobHandler) types.JobHandler {
+		return func(ctx context.Context, job types.Job) (interface{}, error) {
+			data, err := next(ctx, job)
+			return fmt.Sprintf("%v+%s", data, name), err
+		}
+	}
+}
+
+func TestChainOrder(t *testing.T) {
+	handler := Chain(func(ctx context.Context, job types.Job) (interface{}, error) {
+		return "h", nil
+	}, tag("inner"), tag("outer"))
+
+	// The last middleware is the innermost, so it sees the result first
+	data, _ := handler(context.Background(), types.Job{})
+	if data != "h+outer+inner" {
+		t.Errorf("result = %v, want h+outer+inner", data)
+	}
+}
+
+func TestUseWhileRunning(t *testing.T) {
+	const jobs = 200
+
+	p := NewPool(types.PoolConfig{
+		WorkerCount: 4,
+		QueueSize:   jobs,
+		Handler: func(ctx context.Context, job types.Job) (interface{}, error) {
+			return "h", nil
+		},
+	})
+	if err := p.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer p.Stop()
+
+	// Add middlewares while the workers are running jobs
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 10; i++ {
+			p.Use(tag(fmt.Sprint(i)))
+		}
+	}()
+	for i := 0; i < jobs; i++ {
+		if err := p.Submit(types.Job{ID: fmt.Sprint(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 0; i < jobs; i++ {
+		if _, err := p.GetResult(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wg.Wait()
+
+	// Once Use has returned every new job runs through the full chain
+	if err := p.Submit(types.Job{ID: "after"}); err != nil {
+		t.Fatal(err)
+	}
+	result, err := p.GetResult()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "h+9+8+7+6+5+4+3+2+1+0"; result.Data != want {
+		t.Errorf("result = %v, want %v", result.Data, want)
+	}
+}
@@ -0,0 +1,146 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cs-mastery/worker-pool/pkg/types"
+)
+
+// Overflow errors
+var (
+	ErrQueueFull  = errors.New("job queue is full")
+	ErrJobDropped = errors.New("job dropped from full queue")
+)
+
+// callerWorkerID is the worker ID reported for jobs run by the submitter
+// under OverflowCallerRuns
+const callerWorkerID = -1
+
+// reserveSlot takes a queue slot for a new job, applying the overflow
+// policy when the queue is full. It reports whether the job holds a slot
+// and should be queued; false means the submitter runs the job itself
+// (CallerRuns).
+func (p *Pool) reserveSlot(ctx, poolCtx context.Context) (bool, error) {
+	select {
+	case p.slots <- struct{}{}:
+		return true, nil
+	default:
+	}
+
+	switch p.config.OverflowPolicy {
+	case types.OverflowFailFast:
+		p.metrics.IncrementSubmitsRejected()
+		return false, ErrQueueFull
+	case types.OverflowDropOldest:
+		if !p.dropOldest() {
+			// Nothing can be evicted, e.g. the only queued job is being
+			// handed to a worker
+			p.metrics.IncrementSubmitsRejected()
+			return false, ErrQueueFull
+		}
+		return true, nil
+	case types.OverflowDropNewest:
+		p.metrics.IncrementJobsDroppedNewest()
+		return false, ErrJobDropped
+	case types.OverflowCallerRuns:
+		return false, nil
+	}
+
+	p.metrics.IncrementSubmitsBlocked()
+
+	var timeout <-chan time.Time
+	if p.config.SubmitTimeout > 0 {
+		timer := time.NewTimer(p.config.SubmitTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case p.slots <- struct{}{}:
+		return true, nil
+	case <-ctx.Done():
+		return false, ctx.Err()
+	case <-poolCtx.Done():
+		return false, ErrPoolNotRunning
+	case <-timeout:
+		p.metrics.IncrementSubmitsRejected()
+		return false, ErrSubmitTimeout
+	}
+}
+
+// dropOldest evicts the longest-queued job to make room for a new one.
+// The evicted job's slot passes to the new job.
+func (p *Pool) dropOldest() bool {
+	p.queueMu.Lock()
+	var oldest *queueItem
+	parked := false
+	p.queue.each(func(item *queueItem) {
+		if item != p.offering && (oldest == nil || item.seq < oldest.seq) {
+			oldest = item
+		}
+	})
+	p.eachParkedLocked(func(item *queueItem) {
+		if oldest == nil || item.seq < oldest.seq {
+			oldest, parked = item, true
+		}
+	})
+	switch {
+	case parked:
+		p.removeParkedLocked(oldest)
+	case oldest != nil:
+		p.queue.remove(oldest)
+		p.releaseKeyLocked(oldest.job)
+	}
+	p.queueMu.Unlock()
+
+	if oldest == nil {
+		return false
+	}
+	p.metrics.IncrementJobsDroppedOldest()
+	p.drop(oldest.job)
+	return true
+}
+
+// drop finishes a job that was never run as dropped. It runs in the
+// submitter's goroutine, so the result is published without waiting for
+// room in the result queue.
+func (p *Pool) drop(job types.Job) {
+	err := fmt.Errorf("%w: %w", ErrJobDropped, context.Canceled)
+	result := types.JobResult{
+		JobID:   job.ID,
+		Error:   err,
+		Attempt: job.Attempt,
+		Errors:  append(append([]error(nil), job.AttemptErrors...), err),
+	}
+	ctx := p.poolContext()
+	if p.record(ctx, result) {
+		return
+	}
+
+	select {
+	case p.resultQueue <- result:
+	default:
+		go p.publish(ctx, result)
+	}
+}
+
+// runInCaller runs a job in the submitting goroutine. The job counts
+// against its type's concurrency and its tenant's in-flight jobs but is
+// not held back by them. The caller has already admitted it on its
+// partition key with waitForKey.
+func (p *Pool) runInCaller(job types.Job) {
+	p.metrics.IncrementJobsRunByCaller()
+
+	p.queueMu.Lock()
+	p.acquireTypeSlotLocked(job)
+	p.queue.acquire(job.Tenant)
+	p.queueMu.Unlock()
+
+	w := NewWorker(callerWorkerID, nil, p, p.poolContext(), p.runChain, p.metrics)
+	// The pool never waits for this worker
+	w.released.Store(true)
+	w.processJob(job)
+}
@@ -0,0 +1,180 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cs-mastery/worker-pool/pkg/types"
+)
+
+// fullPool starts a one-worker pool with a queue of one, busy with a
+// blocking job and with "queued" waiting behind it. Calling the returned
+// function lets the blocking job finish.
+func fullPool(t *testing.T, config types.PoolConfig) (*Pool, func()) {
+	t.Helper()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var once sync.Once
+	unblock := func() { once.Do(func() { close(release) }) }
+	config.WorkerCount = 1
+	config.QueueSize = 1
+	config.Handler = func(ctx context.Context, job types.Job) (interface{}, error) {
+		if job.ID == "blocker" {
+			close(started)
+			<-release
+		}
+		return job.ID, nil
+	}
+
+	p := NewPool(config)
+	if err := p.Start(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		unblock()
+		p.Stop()
+	})
+
+	submitAll(t, p, types.Job{ID: "blocker", PartitionKey: "key"})
+	<-started
+	// The dispatcher gives back the blocker's queue slot just after
+	// handing it over
+	for len(p.slots) > 0 {
+		time.Sleep(time.Millisecond)
+	}
+	submitAll(t, p, types.Job{ID: "queued"})
+	return p, unblock
+}
+
+func TestOverflowFailFast(t *testing.T) {
+	p, _ := fullPool(t, types.PoolConfig{OverflowPolicy: types.OverflowFailFast})
+
+	if err := p.Submit(types.Job{ID: "new"}); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("Submit = %v, want ErrQueueFull", err)
+	}
+}
+
+func TestOverflowBlockTimesOut(t *testing.T) {
+	p, _ := fullPool(t, types.PoolConfig{SubmitTimeout: 10 * time.Millisecond})
+
+	if err := p.Submit(types.Job{ID: "new"}); !errors.Is(err, ErrSubmitTimeout) {
+		t.Errorf("Submit = %v, want ErrSubmitTimeout", err)
+	}
+	if _, err := p.GetJobStatus("new"); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("timed out job is still tracked: %v", err)
+	}
+}
+
+func TestOverflowDropNewest(t *testing.T) {
+	p, release := fullPool(t, types.PoolConfig{
+		OverflowPolicy: types.OverflowDropNewest,
+		EnableMetrics:  true,
+	})
+
+	if err := p.Submit(types.Job{ID: "new"}); !errors.Is(err, ErrJobDropped) {
+		t.Fatalf("Submit = %v, want ErrJobDropped", err)
+	}
+	if _, err := p.GetJobStatus("new"); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("dropped job is still tracked: %v", err)
+	}
+	if n := p.GetMetrics().JobsDroppedNewest; n != 1 {
+		t.Errorf("JobsDroppedNewest = %d, want 1", n)
+	}
+
+	// The jobs already accepted are unaffected
+	release()
+	for _, want := range []string{"blocker", "queued"} {
+		result, err := p.GetResult()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.JobID != want || result.Error != nil {
+			t.Errorf("result = %s %v, want %s succeeding", result.JobID, result.Error, want)
+		}
+	}
+}
+
+func TestOverflowDropOldestDoesNotBlock(t *testing.T) {
+	p, release := fullPool(t, types.PoolConfig{OverflowPolicy: types.OverflowDropOldest})
+
+	// Pausing withdraws the job the dispatcher is offering, which could
+	// not be evicted. Fill the result queue so the evicted job's result
+	// has nowhere to go.
+	if err := p.Pause(); err != nil {
+		t.Fatal(err)
+	}
+	p.resultQueue <- types.JobResult{JobID: "unread"}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	done := make(chan error)
+	go func() { done <- p.SubmitWithContext(ctx, types.Job{ID: "new"}) }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-ctx.Done():
+		t.Fatal("Submit blocked publishing the evicted job's result")
+	}
+
+	release()
+	if err := p.Resume(); err != nil {
+		t.Fatal(err)
+	}
+	results := make(map[string]error)
+	for i := 0; i < 4; i++ {
+		result, err := p.GetResult()
+		if err != nil {
+			t.Fatal(err)
+		}
+		results[result.JobID] = result.Error
+	}
+	if err := results["queued"]; !errors.Is(err, ErrJobDropped) || !errors.Is(err, context.Canceled) {
+		t.Errorf("evicted job error = %v, want a dropped cancellation", err)
+	}
+	for _, id := range []string{"blocker", "new"} {
+		if err, ok := results[id]; !ok || err != nil {
+			t.Errorf("%s result = %v (delivered %v), want success", id, err, ok)
+		}
+	}
+}
+
+func TestOverflowCallerRuns(t *testing.T) {
+	p, _ := fullPool(t, types.PoolConfig{OverflowPolicy: types.OverflowCallerRuns})
+
+	if err := p.Submit(types.Job{ID: "new"}); err != nil {
+		t.Fatal(err)
+	}
+	// Submit returns once the job has run, so its result is already there
+	select {
+	case result := <-p.resultQueue:
+		if result.JobID != "new" || result.WorkerID != callerWorkerID {
+			t.Errorf("result = %s on worker %d, want new on the caller", result.JobID, result.WorkerID)
+		}
+	default:
+		t.Fatal("job did not run in the caller")
+	}
+}
+
+func TestOverflowCallerRunsKeyWaitIsCancellable(t *testing.T) {
+	p, _ := fullPool(t, types.PoolConfig{
+		OverflowPolicy:      types.OverflowCallerRuns,
+		MaxConcurrentPerKey: 1,
+	})
+
+	// The blocking job holds the key, so the submitter waits for it
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := p.SubmitWithContext(ctx, types.Job{ID: "keyed", PartitionKey: "key"})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Submit = %v, want context.DeadlineExceeded", err)
+	}
+	if _, err := p.GetJobStatus("keyed"); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("abandoned submission is still tracked: %v", err)
+	}
+}
@@ -0,0 +1,146 @@
+package pool
+
+import (
+	"context"
+
+	"github.com/cs-mastery/worker-pool/pkg/types"
+)
+
+// keySlot tracks the jobs admitted for one partition key. A keyed job is
+// admitted when it joins the ready queue and stays admitted until its
+// attempt finishes, so at most MaxConcurrentPerKey jobs of a key are ever
+// queued for dispatch or running. Further jobs wait in the backlog in
+// submission order.
+type keySlot struct {
+	admitted int
+	backlog  []*queueItem // Oldest first
+}
+
+// keyLimited reports whether a job is subject to MaxConcurrentPerKey
+func (p *Pool) keyLimited(job types.Job) bool {
+	return job.PartitionKey != "" && p.config.MaxConcurrentPerKey > 0
+}
+
+// keyHasRoomLocked reports whether another job of key may be admitted
+// without overtaking its backlog. Caller holds queueMu.
+func (p *Pool) keyHasRoomLocked(key string) bool {
+	slot, ok := p.keySlots[key]
+	return !ok || (slot.admitted < p.config.MaxConcurrentPerKey && len(slot.backlog) == 0)
+}
+
+// keySlotLocked returns the slot for a key. Caller holds queueMu.
+func (p *Pool) keySlotLocked(key string) *keySlot {
+	slot, ok := p.keySlots[key]
+	if !ok {
+		slot = &keySlot{}
+		p.keySlots[key] = slot
+	}
+	return slot
+}
+
+// admitKeyLocked admits a newly queued job for dispatch if its key has
+// room, and otherwise adds it to the key's backlog. It reports whether the
+// job was admitted. Caller holds queueMu.
+func (p *Pool) admitKeyLocked(item *queueItem) bool {
+	if !p.keyLimited(item.job) {
+		return true
+	}
+
+	key := item.job.PartitionKey
+	if !p.keyHasRoomLocked(key) {
+		slot := p.keySlotLocked(key)
+		slot.backlog = append(slot.backlog, item)
+		return false
+	}
+	p.keySlotLocked(key).admitted++
+	return true
+}
+
+// waitForKey blocks until a job's key has room and admits the job. It is
+// used for jobs that skip the queue, and gives up when ctx or poolCtx is
+// done.
+func (p *Pool) waitForKey(ctx, poolCtx context.Context, job types.Job) error {
+	if !p.keyLimited(job) {
+		return nil
+	}
+
+	// Wake the wait below when either context is done
+	for _, c := range []context.Context{ctx, poolCtx} {
+		stop := context.AfterFunc(c, func() {
+			p.queueMu.Lock()
+			p.keyFreed.Broadcast()
+			p.queueMu.Unlock()
+		})
+		defer stop()
+	}
+
+	p.queueMu.Lock()
+	defer p.queueMu.Unlock()
+
+	for !p.keyHasRoomLocked(job.PartitionKey) {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if poolCtx.Err() != nil {
+			return ErrPoolNotRunning
+		}
+		p.keyFreed.Wait()
+	}
+	p.keySlotLocked(job.PartitionKey).admitted++
+	return nil
+}
+
+// releaseKey marks an admitted job as done with its key
+func (p *Pool) releaseKey(job types.Job) {
+	p.queueMu.Lock()
+	defer p.queueMu.Unlock()
+	p.releaseKeyLocked(job)
+}
+
+// releaseKeyLocked marks an admitted job as done with its key and admits
+// the oldest job in the key's backlog. Caller holds queueMu.
+func (p *Pool) releaseKeyLocked(job types.Job) {
+	if !p.keyLimited(job) {
+		return
+	}
+
+	key := job.PartitionKey
+	slot, ok := p.keySlots[key]
+	if !ok {
+		return
+	}
+	slot.admitted--
+
+	if len(slot.backlog) > 0 {
+		next := slot.backlog[0]
+		slot.backlog[0] = nil
+		slot.backlog = slot.backlog[1:]
+		slot.admitted++
+		p.queue.push(next)
+		p.wakeDispatcher()
+	} else if slot.admitted <= 0 {
+		delete(p.keySlots, key)
+	}
+	p.keyFreed.Broadcast()
+}
+
+// removeBacklogLocked removes a job waiting in its key's backlog. Caller
+// holds queueMu.
+func (p *Pool) removeBacklogLocked(item *queueItem) bool {
+	key := item.job.PartitionKey
+	slot, ok := p.keySlots[key]
+	if !ok {
+		return false
+	}
+
+	for i, waiting := range slot.backlog {
+		if waiting == item {
+			slot.backlog = append(slot.backlog[:i], slot.backlog[i+1:]...)
+			if slot.admitted <= 0 && len(slot.backlog) == 0 {
+				delete(p.keySlots, key)
+			}
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,136 @@
+package pool
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cs-mastery/worker-pool/pkg/types"
+)
+
+func TestPartitionKeyRunsInOrder(t *testing.T) {
+	otherDone := make(chan struct{})
+	var mu sync.Mutex
+	var order []string
+	running := make(map[string]int)
+	overlapped := false
+
+	p := NewPool(types.PoolConfig{
+		WorkerCount:         4,
+		MaxConcurrentPerKey: 1,
+		Handler: func(ctx context.Context, job types.Job) (interface{}, error) {
+			mu.Lock()
+			running[job.PartitionKey]++
+			overlapped = overlapped || running[job.PartitionKey] > 1
+			if job.PartitionKey == "a" {
+				order = append(order, job.ID)
+			}
+			mu.Unlock()
+
+			// The first job of a holds its key until every job of b has
+			// finished, so b must not wait behind a
+			if job.ID == "a-0" {
+				select {
+				case <-otherDone:
+				case <-time.After(time.Second):
+					return nil, fmt.Errorf("jobs of another key were held back")
+				}
+			}
+			time.Sleep(time.Millisecond)
+
+			mu.Lock()
+			running[job.PartitionKey]--
+			mu.Unlock()
+			return nil, nil
+		},
+	})
+	if err := p.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer p.Stop()
+
+	var want []string
+	for i := 0; i < 5; i++ {
+		id := fmt.Sprintf("a-%d", i)
+		want = append(want, id)
+		submitAll(t, p, types.Job{ID: id, PartitionKey: "a"})
+	}
+	for i := 0; i < 3; i++ {
+		submitAll(t, p, types.Job{ID: fmt.Sprintf("b-%d", i), PartitionKey: "b"})
+	}
+
+	doneB := 0
+	for i := 0; i < 8; i++ {
+		result, err := p.GetResult()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Error != nil {
+			t.Fatalf("job %s: %v", result.JobID, result.Error)
+		}
+		if result.JobID[0] == 'b' {
+			if doneB++; doneB == 3 {
+				close(otherDone)
+			}
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if overlapped {
+		t.Error("jobs of one key ran at the same time")
+	}
+	if !reflect.DeepEqual(order, want) {
+		t.Errorf("jobs of a ran in order %v, want %v", order, want)
+	}
+}
+
+func TestAutoscaleIgnoresKeyBacklog(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	p := NewPool(types.PoolConfig{
+		WorkerCount:         1,
+		MaxConcurrentPerKey: 1,
+		Autoscale:           types.AutoscaleConfig{MinWorkers: 1, MaxWorkers: 8, Interval: time.Hour},
+		Handler: func(ctx context.Context, job types.Job) (interface{}, error) {
+			if job.ID == "holder" {
+				close(started)
+			}
+			<-release
+			return nil, nil
+		},
+	})
+	if err := p.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer p.Stop()
+	defer close(release)
+
+	submitAll(t, p, types.Job{ID: "holder", PartitionKey: "k"})
+	<-started
+	// The dispatcher takes the holder off the queue just after handing
+	// it over
+	for len(p.slots) > 0 {
+		time.Sleep(time.Millisecond)
+	}
+	for i := 0; i < 5; i++ {
+		submitAll(t, p, types.Job{ID: fmt.Sprintf("k-%d", i), PartitionKey: "k"})
+	}
+
+	// Only the holder can free the backlog, so workers would not help
+	p.autoscale(p.poolContext(), time.Now())
+	if n := p.GetWorkerCount(); n != 1 {
+		t.Fatalf("worker count with only a key backlog = %d, want 1", n)
+	}
+
+	for i := 0; i < 3; i++ {
+		submitAll(t, p, types.Job{ID: fmt.Sprintf("free-%d", i)})
+	}
+	p.autoscale(p.poolContext(), time.Now())
+	if n := p.GetWorkerCount(); n != 3 {
+		t.Errorf("worker count with 3 dispatchable jobs = %d, want 3", n)
+	}
+}
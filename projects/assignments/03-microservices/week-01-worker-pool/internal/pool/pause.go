@@ -0,0 +1,80 @@
+package pool
+
+import "time"
+
+// Pause stops the dispatcher handing queued jobs to workers. Running jobs
+// finish normally and submissions are still accepted until the queue is
+// full. A job already being handed to a worker when Pause is called may
+// still start. Pausing a paused pool has no effect.
+func (p *Pool) Pause() error {
+	if !p.IsRunning() {
+		return ErrPoolNotRunning
+	}
+
+	p.queueMu.Lock()
+	if !p.paused {
+		p.paused = true
+		p.pausedAt = time.Now()
+	}
+	p.queueMu.Unlock()
+
+	// Withdraw any job the dispatcher is currently offering, and jobs
+	// already handed to worker deques
+	p.wakeDispatcher()
+	if p.stealer != nil {
+		p.requeueLocal(p.stealer.takeAll())
+	}
+	return nil
+}
+
+// Resume lets the dispatcher hand queued jobs to workers again. Resuming
+// a pool that is not paused has no effect.
+func (p *Pool) Resume() error {
+	if !p.IsRunning() {
+		return ErrPoolNotRunning
+	}
+
+	p.queueMu.Lock()
+	if p.paused {
+		p.paused = false
+		p.pausedTotal += time.Since(p.pausedAt)
+		p.pausedAt = time.Time{}
+	}
+	p.queueMu.Unlock()
+
+	p.wakeDispatcher()
+	return nil
+}
+
+// IsPaused returns whether the pool is paused
+func (p *Pool) IsPaused() bool {
+	p.queueMu.Lock()
+	defer p.queueMu.Unlock()
+	return p.paused
+}
+
+// PausedFor returns how long the pool has been paused, or zero if it is
+// not paused
+func (p *Pool) PausedFor() time.Duration {
+	p.queueMu.Lock()
+	defer p.queueMu.Unlock()
+
+	if !p.paused {
+		return 0
+	}
+	return time.Since(p.pausedAt)
+}
+
+// pauseState returns whether the pool is paused, for how long, and the
+// total time spent paused including the current pause
+func (p *Pool) pauseState() (paused bool, current, total time.Duration) {
+	p.queueMu.Lock()
+	defer p.queueMu.Unlock()
+
+	total = p.pausedTotal
+	if p.paused {
+		current = time.Since(p.pausedAt)
+		total += current
+	}
+	return p.paused, current, total
+}
@@ -0,0 +1,104 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cs-mastery/worker-pool/pkg/types"
+)
+
+func TestPauseHoldsQueuedJobs(t *testing.T) {
+	p := NewPool(types.PoolConfig{
+		WorkerCount: 2,
+		Handler: func(ctx context.Context, job types.Job) (interface{}, error) {
+			return nil, nil
+		},
+	})
+	if err := p.Pause(); !errors.Is(err, ErrPoolNotRunning) {
+		t.Errorf("Pause before Start = %v, want ErrPoolNotRunning", err)
+	}
+	if err := p.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer p.Stop()
+
+	if err := p.Pause(); err != nil {
+		t.Fatal(err)
+	}
+	if !p.IsPaused() {
+		t.Fatal("IsPaused = false after Pause")
+	}
+
+	// Submissions are accepted but nothing runs
+	submitAll(t, p, types.Job{ID: "a"}, types.Job{ID: "b"})
+	time.Sleep(20 * time.Millisecond)
+	if n := p.GetQueueLength(); n != 2 {
+		t.Errorf("queue length while paused = %d, want 2", n)
+	}
+	if d := p.PausedFor(); d < 20*time.Millisecond {
+		t.Errorf("PausedFor = %v, want at least 20ms", d)
+	}
+
+	if err := p.Resume(); err != nil {
+		t.Fatal(err)
+	}
+	if p.IsPaused() || p.PausedFor() != 0 {
+		t.Error("pool still reports paused after Resume")
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := p.GetResult(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := p.GetQueueLength(); n != 0 {
+		t.Errorf("queue length after Resume = %d, want 0", n)
+	}
+}
+
+func TestPauseLetsRunningJobsFinish(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	p := NewPool(types.PoolConfig{
+		WorkerCount: 1,
+		Handler: func(ctx context.Context, job types.Job) (interface{}, error) {
+			if job.ID == "running" {
+				close(started)
+				<-release
+			}
+			return nil, nil
+		},
+	})
+	if err := p.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer p.Stop()
+
+	submitAll(t, p, types.Job{ID: "running"})
+	<-started
+	if err := p.Pause(); err != nil {
+		t.Fatal(err)
+	}
+	submitAll(t, p, types.Job{ID: "held"})
+	close(release)
+
+	result, err := p.GetResult()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.JobID != "running" {
+		t.Fatalf("first result = %s, want running", result.JobID)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if record, _ := p.GetJobStatus("held"); record.Status != types.JobPending {
+		t.Errorf("held job status = %s while paused, want pending", record.Status)
+	}
+
+	if err := p.Resume(); err != nil {
+		t.Fatal(err)
+	}
+	if result, err = p.GetResult(); err != nil || result.JobID != "held" {
+		t.Fatalf("result after Resume = %s %v, want held", result.JobID, err)
+	}
+}
@@ -1,304 +1,748 @@
 package pool
 
 import (
+	"container/heap"
 	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/cs-mastery/worker-pool/pkg/types"
 )
 
-// Pool implements the WorkerPool interface
+// Errors returned by the pool
+var (
+	ErrPoolNotRunning  = errors.New("pool is not running")
+	ErrPoolRunning     = errors.New("pool is already running")
+	ErrSubmitTimeout   = errors.New("job submission timeout")
+	ErrShutdownTimeout = errors.New("shutdown timeout exceeded")
+	ErrMissingJobID    = errors.New("job ID is required")
+)
+
+// Pool implements the WorkerPool interface.
+//
+// Submitted jobs wait in per-tenant priority queues (see fairQueue). A
+// dispatcher goroutine hands the next job to an idle worker over the
+// unbuffered jobQueue, so no job is committed to a worker before a worker
+// is actually free to run it.
 type Pool struct {
-	// TODO: Add pool fields
-	// Hints:
-	// - config: pool configuration
-	// - workers: slice of workers
-	// - jobQueue: channel for incoming jobs
-	// - resultQueue: channel for job results
-	// - ctx: context for cancellation
-	// - cancel: cancel function
-	// - wg: wait group for graceful shutdown
-	// - mu: mutex for thread safety
-	// - metrics: performance metrics
-	// - running: flag to indicate if pool is running
-
-	// Example implementation:
-	// config      types.PoolConfig
-	// workers     []*Worker
-	// jobQueue    chan types.Job
-	// resultQueue chan types.JobResult
-	// ctx         context.Context
-	// cancel      context.CancelFunc
-	// wg          sync.WaitGroup
-	// mu          sync.RWMutex
-	// metrics     *Metrics
-	// running     bool
+	config       types.PoolConfig
+	workers      []*Worker // Guarded by workersMu
+	nextWorkerID int       // ID for the next worker started, guarded by workersMu
+	workersMu    sync.Mutex
+	autoscaler   *autoscaler     // Nil unless PoolConfig.Autoscale is enabled
+	stealer      *stealScheduler // Nil unless PoolConfig.Scheduler is SchedulerWorkStealing
+	jobQueue     chan types.Job
+	resultQueue  chan types.JobResult
+	ctx          context.Context
+	cancel       context.CancelFunc
+	wg           sync.WaitGroup
+	mu           sync.RWMutex
+	metrics      *Metrics
+	running      bool
+	draining     bool
+	deadLetters  *deadLetterQueue
+	jobs         *jobRegistry
+	idempotency  *idempotencyStore // Nil unless PoolConfig.IdempotencyWindow is set
+	handlers     *HandlerRegistry
+
+	// chain is handle wrapped in the configured middlewares. Use replaces
+	// it while workers load it for every job.
+	chain atomic.Pointer[types.JobHandler]
+
+	// backend records accepted jobs; replayed is set once the first Start
+	// has read them back. retainUnfinished keeps jobs cancelled by Drain
+	// in the backend; jobs cancelled by Stop are kept through the pool's
+	// context.
+	backend          types.QueueBackend
+	replayed         bool // Guarded by mu
+	retainUnfinished atomic.Bool
+
+	// Queued jobs, guarded by queueMu. slots holds one token per queued
+	// job and bounds the queue at QueueSize; notify wakes the dispatcher
+	// when a job is enqueued. offering is the item the dispatcher is
+	// currently handing to a worker. typeSlots counts running jobs per
+	// type and parks queued jobs whose type is at its concurrency cap;
+	// keySlots holds back jobs whose partition key is at its cap, and
+	// keyFreed is signalled when a key has room. While paused the
+	// dispatcher hands out no jobs.
+	queue       *fairQueue
+	queueMu     sync.Mutex
+	seq         uint64
+	slots       chan struct{}
+	notify      chan struct{}
+	offering    *queueItem
+	typeSlots   map[string]*typeSlot
+	keySlots    map[string]*keySlot
+	keyFreed    *sync.Cond
+	paused      bool
+	pausedAt    time.Time
+	pausedTotal time.Duration
+
+	// Jobs waiting for their run time (SubmitAt, retries), guarded by
+	// scheduleMu. scheduleWake wakes the scheduler when one is added.
+	scheduled    scheduleQueue
+	scheduleMu   sync.Mutex
+	scheduleSeq  uint64
+	scheduleWake chan struct{}
+
+	// Registered recurring jobs by name, guarded by recurringMu
+	recurring     map[string]*recurringEntry
+	recurringMu   sync.Mutex
+	recurringWake chan struct{}
+
+	// Running attempts by job ID, guarded by inflightMu
+	inflight   map[string]*attempt
+	inflightMu sync.Mutex
+
+	// Graphs submitted with SubmitGraph by ID, guarded by graphsMu.
+	// finishedGraphs orders finished graphs for eviction.
+	graphs         map[string]*GraphRun
+	finishedGraphs []finishedJob
+	graphsMu       sync.Mutex
+
+	// Groups submitted with SubmitBatch by ID, guarded by groupsMu.
+	// finishedGroups orders finished groups for eviction. batchMu lets one
+	// batch at a time reserve queue slots.
+	groups         map[string]*JobGroup
+	finishedGroups []finishedJob
+	groupSeq       uint64
+	groupsMu       sync.Mutex
+	batchMu        sync.Mutex
+
+	// Workflows submitted with SubmitWorkflow by ID, guarded by
+	// workflowsMu. finishedWorkflows orders finished workflows for
+	// eviction.
+	workflows         map[string]*WorkflowRun
+	finishedWorkflows []finishedJob
+	workflowsMu       sync.Mutex
+
+	// Final-result watchers by job ID, guarded by watchMu
+	watchers map[string]*watcher
+	watchMu  sync.Mutex
 }
 
 // NewPool creates a new worker pool with the given configuration
 func NewPool(config types.PoolConfig) *Pool {
-	// TODO: Implement pool creation
-	// Hints:
-	// - Validate the configuration
-	// - Create context with cancel function
-	// - Initialize channels with appropriate buffer sizes
-	// - Create metrics collector
-	// - Initialize workers slice
+	defaults := types.DefaultPoolConfig()
+	if config.WorkerCount <= 0 {
+		config.WorkerCount = defaults.WorkerCount
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = defaults.QueueSize
+	}
+	if config.ShutdownTimeout <= 0 {
+		config.ShutdownTimeout = defaults.ShutdownTimeout
+	}
+	if config.AgingInterval < 0 {
+		config.AgingInterval = 0
+	}
+	if config.MaxJobRecords <= 0 {
+		config.MaxJobRecords = defaults.MaxJobRecords
+	}
+	if config.MaxIdempotencyKeys <= 0 {
+		config.MaxIdempotencyKeys = defaults.MaxIdempotencyKeys
+	}
+
+	scaler := newAutoscaler(config.Autoscale)
+	if scaler != nil {
+		config.WorkerCount = scaler.clamp(config.WorkerCount)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var deadLetters *deadLetterQueue
+	if config.DeadLetterSize > 0 {
+		deadLetters = newDeadLetterQueue(config.DeadLetterSize)
+	}
+
+	backend := config.QueueBackend
+	if backend == nil {
+		backend = NewMemoryBackend()
+	}
+
+	var idempotency *idempotencyStore
+	if config.IdempotencyWindow > 0 {
+		idempotency = newIdempotencyStore(config.IdempotencyWindow, config.MaxIdempotencyKeys)
+	}
 
-	return &Pool{
-		// TODO: Initialize all fields
+	p := &Pool{
+		config:      config,
+		jobQueue:    make(chan types.Job),
+		resultQueue: make(chan types.JobResult, config.QueueSize),
+		ctx:         ctx,
+		cancel:      cancel,
+		metrics:     NewMetrics(config.EnableMetrics, config.MetricsInterval),
+		queue:       newFairQueue(config.Tenants, config.DefaultTenant, config.AgingInterval),
+		slots:       make(chan struct{}, config.QueueSize),
+		notify:      make(chan struct{}, 1),
+		deadLetters: deadLetters,
+		jobs:        newJobRegistry(config.JobRetention, config.MaxJobRecords),
+		idempotency: idempotency,
+		handlers:    NewHandlerRegistry(),
+		backend:     backend,
+		typeSlots:   make(map[string]*typeSlot),
+		keySlots:    make(map[string]*keySlot),
+
+		scheduleWake: make(chan struct{}, 1),
+
+		recurring:     make(map[string]*recurringEntry),
+		recurringWake: make(chan struct{}, 1),
+		watchers:      make(map[string]*watcher),
+		graphs:        make(map[string]*GraphRun),
+		groups:        make(map[string]*JobGroup),
+		workflows:     make(map[string]*WorkflowRun),
+		inflight:      make(map[string]*attempt),
+		autoscaler:    scaler,
 	}
+	p.keyFreed = sync.NewCond(&p.queueMu)
+	p.setChain(config.Middlewares)
+	if config.Scheduler == types.SchedulerWorkStealing {
+		p.stealer = newStealScheduler(p)
+	}
+	return p
 }
 
-// Start starts the worker pool
+// Start starts the worker pool. The first Start replays the unfinished
+// jobs recorded by PoolConfig.QueueBackend; if they cannot be read back
+// the pool is not started.
 func (p *Pool) Start() error {
-	// TODO: Implement pool startup
-	// Hints:
-	// - Check if pool is already running
-	// - Start metrics collection (if enabled)
-	// - Create and start workers
-	// - Set running flag to true
-	// - Handle any startup errors gracefully
-
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
-	// TODO: Add implementation
-	// Example structure:
-	// if p.running {
-	//     return fmt.Errorf("pool is already running")
-	// }
-	//
-	// // Start metrics if enabled
-	// if p.config.EnableMetrics {
-	//     p.metrics.Start()
-	// }
-	//
-	// // Create and start workers
-	// for i := 0; i < p.config.WorkerCount; i++ {
-	//     worker := NewWorker(i, p.jobQueue, p.resultQueue, p.ctx)
-	//     p.workers = append(p.workers, worker)
-	//     p.wg.Add(1)
-	//     go worker.Start(&p.wg)
-	// }
-	//
-	// p.running = true
-	// return nil
-
-	return nil // TODO: Remove this line when implementing
-}
-
-// Stop gracefully stops the worker pool
-func (p *Pool) Stop() error {
-	// TODO: Implement graceful shutdown
-	// Hints:
-	// - Check if pool is running
-	// - Cancel context to signal workers to stop
-	// - Close job queue to prevent new submissions
-	// - Wait for workers to finish with timeout
-	// - Stop metrics collection
-	// - Set running flag to false
+	if p.running {
+		return ErrPoolRunning
+	}
+
+	var unfinished []types.QueueEntry
+	if !p.replayed {
+		entries, err := p.backend.Replay()
+		if err != nil {
+			return fmt.Errorf("replaying queue backend: %w", err)
+		}
+		unfinished, p.replayed = entries, true
+	}
+
+	// A stopped pool gets a fresh context so it can be restarted
+	if p.ctx.Err() != nil {
+		p.ctx, p.cancel = context.WithCancel(context.Background())
+	}
+
+	// Start metrics if enabled
+	if p.config.EnableMetrics {
+		p.metrics.Start()
+	}
+
+	// Create and start workers
+	p.workersMu.Lock()
+	for i := 0; i < p.config.WorkerCount; i++ {
+		p.workers = append(p.workers, p.startWorker(p.ctx, i))
+	}
+	p.nextWorkerID = len(p.workers)
+	p.metrics.SetTotalWorkers(int32(len(p.workers)))
+	p.workersMu.Unlock()
+
+	p.wg.Add(4)
+	if p.stealer != nil {
+		go p.dispatchLocal(p.ctx)
+	} else {
+		go p.dispatch(p.ctx)
+	}
+	go p.runScheduler(p.ctx)
+	go p.runRecurring(p.ctx)
+	go p.watchdog(p.ctx)
+	if p.config.VisibilityTimeout > 0 {
+		p.wg.Add(1)
+		go p.reapLeases(p.ctx)
+	}
+	if p.autoscaler != nil {
+		p.wg.Add(1)
+		go p.runAutoscaler(p.ctx)
+	}
+
+	p.running = true
+	p.restore(unfinished)
+	return nil
+}
 
+// Stop gracefully stops the worker pool. Jobs still waiting in the queue
+// are kept and dispatched if the pool is started again. Scheduled jobs
+// that have not come due are kept too and reported through an
+// *UndeliveredJobsError. Running jobs are cancelled; the queue backend
+// keeps them, with the queued ones, for the next process to replay.
+func (p *Pool) Stop() error {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
-	// TODO: Add implementation
-	// Example structure:
-	// if !p.running {
-	//     return fmt.Errorf("pool is not running")
-	// }
-	//
-	// // Signal workers to stop
-	// p.cancel()
-	//
-	// // Close job queue
-	// close(p.jobQueue)
-	//
-	// // Wait for workers to finish
-	// done := make(chan struct{})
-	// go func() {
-	//     p.wg.Wait()
-	//     close(done)
-	// }()
-	//
-	// select {
-	// case <-done:
-	//     // All workers stopped
-	// case <-time.After(p.config.ShutdownTimeout):
-	//     return fmt.Errorf("shutdown timeout exceeded")
-	// }
-	//
-	// // Stop metrics
-	// if p.metrics != nil {
-	//     p.metrics.Stop()
-	// }
-	//
-	// p.running = false
-	// return nil
-
-	return nil // TODO: Remove this line when implementing
+	if !p.running {
+		return ErrPoolNotRunning
+	}
+
+	// Signal workers and the dispatcher to stop
+	p.cancel()
+	p.running = false
+
+	// Wait for workers to finish
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+
+	var err error
+	select {
+	case <-done:
+		// All workers stopped
+	case <-time.After(p.config.ShutdownTimeout):
+		err = ErrShutdownTimeout
+	}
+
+	// Stop metrics
+	p.metrics.Stop()
+
+	p.workersMu.Lock()
+	p.workers = nil
+	p.workersMu.Unlock()
+	p.metrics.SetTotalWorkers(0)
+
+	if err == nil {
+		if pending := p.GetScheduledJobs(); len(pending) > 0 {
+			err = &UndeliveredJobsError{Jobs: pending}
+		}
+	}
+	return err
 }
 
 // Submit submits a job to the worker pool
 func (p *Pool) Submit(job types.Job) error {
-	// TODO: Implement job submission
-	// Hints:
-	// - Check if pool is running
-	// - Validate job data
-	// - Set job creation timestamp
-	// - Submit to job queue with timeout
-	// - Update metrics
-	// - Handle queue full scenarios
-
 	return p.SubmitWithContext(context.Background(), job)
 }
 
-// SubmitWithContext submits a job with a context
+// SubmitWithContext submits a job with a context. When the queue is full
+// PoolConfig.OverflowPolicy decides what happens; under OverflowBlock ctx
+// bounds how long the caller waits for space. ctx does not cancel the job
+// once queued (set Job.Context for that).
 func (p *Pool) SubmitWithContext(ctx context.Context, job types.Job) error {
-	// TODO: Implement context-aware job submission
-	// Hints:
-	// - Check if pool is running
-	// - Validate job and context
-	// - Use select statement to handle context cancellation
-	// - Submit to job queue or timeout
-	// - Update submission metrics
+	return p.submit(ctx, job, nil)
+}
+
+// submit queues a job, registering w (if not nil) to receive its final
+// result
+func (p *Pool) submit(ctx context.Context, job types.Job, w *watcher) error {
+	if p.idempotency != nil && job.IdempotencyKey != "" {
+		return p.submitOnce(ctx, job, w, func(w *watcher) error {
+			return p.submitNew(ctx, job, w)
+		})
+	}
+	return p.submitNew(ctx, job, w)
+}
 
+// submitNew queues a job without checking its idempotency key
+func (p *Pool) submitNew(ctx context.Context, job types.Job, w *watcher) error {
+	p.mu.RLock()
+	running, draining, poolCtx := p.running, p.draining, p.ctx
+	p.mu.RUnlock()
+
+	if !running {
+		return ErrPoolNotRunning
+	}
+	if draining {
+		return ErrPoolDraining
+	}
+
+	job, err := p.prepare(job)
+	if err != nil {
+		return err
+	}
+	job.EnqueuedAt = job.CreatedAt
+
+	job, err = p.track(job, types.JobPending, w)
+	if err != nil {
+		return err
+	}
+
+	if err := p.admitTenant(job); err != nil {
+		p.untrack(job)
+		p.metrics.IncrementTenantRejected(job.Tenant)
+		return err
+	}
+
+	queued, err := p.reserveSlot(ctx, poolCtx)
+	switch {
+	case err != nil:
+	case queued:
+		if err = p.persist(job, time.Time{}); err != nil {
+			<-p.slots
+		}
+	default:
+		// The submitter runs the job itself once its key has room
+		err = p.waitForKey(ctx, poolCtx, job)
+	}
+	if err != nil {
+		p.releaseAdmission(job)
+		p.untrack(job)
+		if errors.Is(err, ErrQueueFull) || errors.Is(err, ErrSubmitTimeout) || errors.Is(err, ErrJobDropped) {
+			p.metrics.IncrementTenantRejected(job.Tenant)
+		}
+		return err
+	}
+
+	p.metrics.IncrementJobsSubmitted()
+	p.metrics.IncrementTypeSubmitted(job.Type)
+	p.metrics.IncrementTenantSubmitted(job.Tenant)
+	if queued {
+		p.enqueue(job, true)
+	} else {
+		p.releaseAdmission(job)
+		p.runInCaller(job)
+	}
+	return nil
+}
+
+// prepare validates a new job and fills in its metadata and defaults. A
+// job with an unregistered type is rejected with *UnknownJobTypeError.
+func (p *Pool) prepare(job types.Job) (types.Job, error) {
+	if job.ID == "" {
+		return job, ErrMissingJobID
+	}
+	typeConfig, err := p.typeConfig(job)
+	if err != nil {
+		return job, err
+	}
+
+	job.CreatedAt = time.Now()
+	if job.Attempt < 1 {
+		job.Attempt = 1
+	}
+	if job.Timeout == 0 {
+		job.Timeout = typeConfig.Timeout
+	}
+	if job.Timeout == 0 {
+		job.Timeout = p.config.JobTimeout
+	}
+	return job, nil
+}
+
+// enqueue adds a job to its tenant's queue, or to its partition key's
+// backlog if the key is at its cap, and wakes the dispatcher. The caller
+// must already hold a queue slot; admitted means it also holds a
+// reservation from admitTenant, which the queued job takes over.
+func (p *Pool) enqueue(job types.Job, admitted bool) {
+	p.queueMu.Lock()
+	p.seq++
+	item := &queueItem{job: job, seq: p.seq, enqueuedAt: job.EnqueuedAt}
+	if p.admitKeyLocked(item) {
+		p.queue.push(item)
+	}
+	if admitted {
+		p.unreserveLocked(job.Tenant)
+	}
+	p.queueMu.Unlock()
+
+	p.wakeDispatcher()
+}
+
+// wakeDispatcher tells the dispatcher the head of the queue may have
+// changed
+func (p *Pool) wakeDispatcher() {
+	select {
+	case p.notify <- struct{}{}:
+	default:
+	}
+}
+
+// dispatch hands queued jobs to idle workers, taking turns between
+// tenants and in priority order within each. It only removes a job from
+// the queue once a worker has accepted it, and re-evaluates the next job
+// whenever a new one arrives. Jobs
+// whose type is at its concurrency cap are parked until a job of that
+// type finishes, so they do not hold up other types. Nothing is handed
+// out while the pool is paused.
+func (p *Pool) dispatch(ctx context.Context) {
+	defer p.wg.Done()
+
+	for {
+		p.queueMu.Lock()
+		var item *queueItem
+		if !p.paused {
+			item = p.nextDispatchableLocked()
+		}
+		p.offering = item
+		p.queueMu.Unlock()
+
+		if item == nil {
+			select {
+			case <-p.notify:
+				continue
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		select {
+		case p.jobQueue <- item.job:
+			p.queueMu.Lock()
+			p.queue.take(item)
+			p.acquireTypeSlotLocked(item.job)
+			p.offering = nil
+			p.queueMu.Unlock()
+			<-p.slots
+		case <-p.notify:
+			// A new job may now be at the head of the queue, or the
+			// pool was paused
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// nextDispatchableLocked returns the next job to dispatch after parking
+// any jobs whose type is at its concurrency cap. Caller holds queueMu.
+func (p *Pool) nextDispatchableLocked() *queueItem {
+	for {
+		item := p.queue.next()
+		if item == nil || !p.atTypeLimitLocked(item.job) {
+			return item
+		}
+		p.queue.remove(item)
+		heap.Push(p.typeSlotLocked(item.job.Type).parked, item)
+	}
+}
+
+// started records that a worker picked up an attempt of a job, leasing
+// the job to the worker if PoolConfig.VisibilityTimeout is set
+func (p *Pool) started(a *attempt) {
+	if p.config.VisibilityTimeout > 0 {
+		a.leaseUntil.Store(a.startedAt.Add(p.config.VisibilityTimeout).UnixNano())
+	}
+
+	p.inflightMu.Lock()
+	p.inflight[a.job.ID] = a
+	p.inflightMu.Unlock()
+
+	if p.autoscaler != nil {
+		p.autoscaler.observeWait(a.startedAt.Sub(a.job.EnqueuedAt))
+	}
+
+	p.jobs.started(a.job.ID, a.job.Attempt, a.worker.id)
+	p.recordState(a.job, types.JobProcessing, time.Time{})
+}
+
+// complete handles the outcome of one attempt. Failed attempts are
+// retried while the job's retry policy allows it; a job that fails for
+// good is dead-lettered. Cancelled jobs are neither retried nor
+// dead-lettered.
+func (p *Pool) complete(ctx context.Context, job types.Job, result types.JobResult) {
+	p.inflightMu.Lock()
+	delete(p.inflight, job.ID)
+	p.inflightMu.Unlock()
+	p.releaseTypeSlot(job)
+	p.releaseTenant(job)
+	p.releaseKey(job)
+
+	result.Errors = job.AttemptErrors
+	if result.Error != nil {
+		result.Errors = append(append([]error(nil), job.AttemptErrors...), result.Error)
+
+		policy := p.retryPolicy(job)
+		if job.Context.Err() == nil && shouldRetry(policy, job, result.Error) {
+			p.scheduleRetry(job, result, policy)
+			return
+		}
+
+		if errors.Is(result.Error, context.Canceled) {
+			p.metrics.IncrementJobsCancelled()
+		} else {
+			p.metrics.AddLatency(result.Duration)
+			p.metrics.IncrementJobsFailed()
+			p.metrics.RecordTypeResult(job.Type, result.Duration, false)
+			p.metrics.IncrementTenantProcessed(job.Tenant)
+			p.deadLetter(job, result)
+		}
+	} else {
+		p.metrics.AddLatency(result.Duration)
+		p.metrics.IncrementJobsSucceeded()
+		p.metrics.RecordTypeResult(job.Type, result.Duration, true)
+		p.metrics.IncrementTenantProcessed(job.Tenant)
+	}
+
+	p.finish(ctx, result)
+}
+
+// finish records a job's final result, notifies its watcher and
+// publishes the result on the result queue unless the watcher took it
+func (p *Pool) finish(ctx context.Context, result types.JobResult) {
+	if p.record(ctx, result) {
+		return
+	}
+	p.publish(ctx, result)
+}
+
+// record marks a job finished under ctx and hands its result to the
+// job's watcher. It reports whether the watcher took the result
+// exclusively.
+func (p *Pool) record(ctx context.Context, result types.JobResult) bool {
+	p.jobs.finish(result)
+	p.acknowledge(ctx, result)
+	return p.notifyWatcher(result)
+}
+
+// publish sends a final result to the shared result queue, waiting for
+// room until ctx is done
+func (p *Pool) publish(ctx context.Context, result types.JobResult) {
+	select {
+	case p.resultQueue <- result:
+	case <-ctx.Done():
+		// Pool shutting down
+	}
+}
+
+// poolContext returns the context of the current run of the pool
+func (p *Pool) poolContext() context.Context {
 	p.mu.RLock()
 	defer p.mu.RUnlock()
+	return p.ctx
+}
 
-	// TODO: Add implementation
-	// Example structure:
-	// if !p.running {
-	//     return fmt.Errorf("pool is not running")
-	// }
-	//
-	// // Set job metadata
-	// job.CreatedAt = time.Now()
-	// if job.Context == nil {
-	//     job.Context = ctx
-	// }
-	//
-	// // Submit job with context awareness
-	// select {
-	// case p.jobQueue <- job:
-	//     p.metrics.IncrementJobsSubmitted()
-	//     return nil
-	// case <-ctx.Done():
-	//     return ctx.Err()
-	// case <-time.After(5 * time.Second):
-	//     return fmt.Errorf("job submission timeout")
-	// }
-
-	return nil // TODO: Remove this line when implementing
-}
-
-// GetResult retrieves a job result from the pool
-func (p *Pool) GetResult() (types.JobResult, error) {
-	// TODO: Implement result retrieval
-	// Hints:
-	// - Check if pool is running
-	// - Read from result queue
-	// - Handle timeouts and cancellation
-	// - Return appropriate errors
+// watcher receives the final result of one job. An exclusive watcher
+// takes the result instead of the shared result queue.
+type watcher struct {
+	fn        func(types.JobResult)
+	exclusive bool
+	future    *JobFuture // Resolved by fn, if fn resolves a future
+}
+
+// watch registers w to receive the final result of a job
+func (p *Pool) watch(id string, w *watcher) {
+	p.watchMu.Lock()
+	defer p.watchMu.Unlock()
+	p.watchers[id] = w
+}
 
+// unwatch removes the watcher registered for a job
+func (p *Pool) unwatch(id string) {
+	p.watchMu.Lock()
+	defer p.watchMu.Unlock()
+	delete(p.watchers, id)
+}
+
+// notifyWatcher hands a final result to the job's watcher, if any, and
+// reports whether the watcher took the result exclusively
+func (p *Pool) notifyWatcher(result types.JobResult) bool {
+	p.watchMu.Lock()
+	w, ok := p.watchers[result.JobID]
+	delete(p.watchers, result.JobID)
+	p.watchMu.Unlock()
+
+	if !ok {
+		return false
+	}
+	w.fn(result)
+	return w.exclusive
+}
+
+// GetResult retrieves a job result from the pool. Results of jobs
+// submitted with SubmitAsync go to their futures instead.
+func (p *Pool) GetResult() (types.JobResult, error) {
 	return p.GetResultWithContext(context.Background())
 }
 
 // GetResultWithContext retrieves a job result with context
 func (p *Pool) GetResultWithContext(ctx context.Context) (types.JobResult, error) {
-	// TODO: Implement context-aware result retrieval
-	// Hints:
-	// - Check if pool is running
-	// - Use select statement for context handling
-	// - Read from result queue or timeout
-	// - Handle graceful shutdown scenarios
-
 	p.mu.RLock()
-	defer p.mu.RUnlock()
+	running, poolCtx := p.running, p.ctx
+	p.mu.RUnlock()
 
-	// TODO: Add implementation
-	// Example structure:
-	// if !p.running {
-	//     return types.JobResult{}, fmt.Errorf("pool is not running")
-	// }
-	//
-	// select {
-	// case result := <-p.resultQueue:
-	//     return result, nil
-	// case <-ctx.Done():
-	//     return types.JobResult{}, ctx.Err()
-	// case <-p.ctx.Done():
-	//     return types.JobResult{}, fmt.Errorf("pool is shutting down")
-	// }
+	if !running {
+		return types.JobResult{}, ErrPoolNotRunning
+	}
 
-	return types.JobResult{}, nil // TODO: Remove this line when implementing
+	select {
+	case result := <-p.resultQueue:
+		return result, nil
+	case <-ctx.Done():
+		return types.JobResult{}, ctx.Err()
+	case <-poolCtx.Done():
+		return types.JobResult{}, fmt.Errorf("pool is shutting down")
+	}
 }
 
 // GetMetrics returns current pool metrics
 func (p *Pool) GetMetrics() types.PoolMetrics {
-	// TODO: Implement metrics retrieval
-	// Hints:
-	// - Return current metrics snapshot
-	// - Handle case where metrics are disabled
-	// - Ensure thread-safe access to metrics
-
 	p.mu.RLock()
 	defer p.mu.RUnlock()
 
-	// TODO: Add implementation
-	// Example structure:
-	// if p.metrics == nil {
-	//     return types.PoolMetrics{}
-	// }
-	//
-	// return p.metrics.GetSnapshot()
+	var active int32
+	p.workersMu.Lock()
+	for _, worker := range p.workers {
+		if worker.IsBusy() {
+			active++
+		}
+	}
+	p.workersMu.Unlock()
+	p.metrics.SetActiveWorkers(active)
+	p.metrics.SetQueueLength(int32(p.GetQueueLength()))
+
+	snapshot := p.metrics.GetSnapshot()
+	if p.metrics.IsEnabled() {
+		snapshot.ScheduledJobs = int32(p.getScheduledLength())
+		snapshot.StuckJobs = int32(len(p.StuckJobs()))
+		snapshot.Paused, snapshot.PausedFor, snapshot.TotalPaused = p.pauseState()
+		if p.autoscaler != nil {
+			snapshot.ScaleUps, snapshot.ScaleDowns, snapshot.ScaleEvents = p.autoscaler.snapshot()
+		}
+		snapshot.Schedules = p.ListRecurringJobs()
+		if p.deadLetters != nil {
+			snapshot.DeadLetters = int64(p.deadLetters.len())
+		}
+		for jobType, usage := range p.typeUsage() {
+			stats := snapshot.JobTypes[jobType]
+			stats.Running = usage.running
+			stats.Waiting = usage.parked.Len()
+			snapshot.JobTypes[jobType] = stats
+		}
+		for tenant, usage := range p.tenantUsage() {
+			if tenant == "" {
+				continue
+			}
+			stats := snapshot.Tenants[tenant]
+			stats.Queued = usage.queued
+			stats.InFlight = usage.inFlight
+			snapshot.Tenants[tenant] = stats
+		}
+	}
+	return snapshot
+}
 
-	return types.PoolMetrics{} // TODO: Remove this line when implementing
+// Metrics returns the pool's metrics collector, e.g. for
+// MetricsMiddleware
+func (p *Pool) Metrics() *Metrics {
+	return p.metrics
 }
 
-// SetWorkerCount dynamically adjusts the number of workers
+// SetWorkerCount dynamically adjusts the number of workers. New workers
+// start at once; surplus workers are retired, idle ones first, after
+// finishing their current job. With autoscaling enabled the count must
+// lie within its bounds and the autoscaler may change it again later.
 func (p *Pool) SetWorkerCount(count int) error {
-	// TODO: Implement dynamic worker scaling
-	// Hints:
-	// - Validate new worker count
-	// - Compare with current worker count
-	// - Scale up by creating new workers
-	// - Scale down by stopping excess workers
-	// - Update pool configuration
-
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
-	// TODO: Add implementation
-	// Example structure:
-	// if count <= 0 {
-	//     return fmt.Errorf("worker count must be positive")
-	// }
-	//
-	// currentCount := len(p.workers)
-	// if count == currentCount {
-	//     return nil // No change needed
-	// }
-	//
-	// if count > currentCount {
-	//     // Scale up
-	//     for i := currentCount; i < count; i++ {
-	//         worker := NewWorker(i, p.jobQueue, p.resultQueue, p.ctx)
-	//         p.workers = append(p.workers, worker)
-	//         p.wg.Add(1)
-	//         go worker.Start(&p.wg)
-	//     }
-	// } else {
-	//     // Scale down
-	//     for i := count; i < currentCount; i++ {
-	//         p.workers[i].Stop()
-	//     }
-	//     p.workers = p.workers[:count]
-	// }
-	//
-	// p.config.WorkerCount = count
-	// return nil
-
-	return nil // TODO: Remove this line when implementing
+	if count <= 0 {
+		return fmt.Errorf("worker count must be positive, got %d", count)
+	}
+	if p.autoscaler != nil && p.autoscaler.clamp(count) != count {
+		return fmt.Errorf("worker count %d outside autoscale bounds [%d, %d]",
+			count, p.autoscaler.config.MinWorkers, p.autoscaler.config.MaxWorkers)
+	}
+
+	p.config.WorkerCount = count
+	if p.running {
+		p.resize(p.ctx, count, "manual")
+	}
+	return nil
 }
 
 // IsRunning returns whether the pool is currently running
@@ -308,28 +752,40 @@ func (p *Pool) IsRunning() bool {
 	return p.running
 }
 
-// GetQueueLength returns the current number of jobs in the queue
+// GetQueueLength returns the current number of jobs in the queue,
+// including scheduled jobs that have not come due yet
 func (p *Pool) GetQueueLength() int {
-	// TODO: Implement queue length retrieval
-	// Hints:
-	// - Return length of job queue channel
-	// - Handle case where pool is not running
+	p.queueMu.Lock()
+	ready := p.queue.Len() + p.parkedLengthLocked()
+	p.queueMu.Unlock()
+	if p.stealer != nil {
+		ready += int(p.stealer.size.Load())
+	}
 
-	return len(p.jobQueue)
+	return ready + p.getScheduledLength()
 }
 
 // GetWorkerCount returns the current number of workers
 func (p *Pool) GetWorkerCount() int {
-	p.mu.RLock()
-	defer p.mu.RUnlock()
+	p.workersMu.Lock()
+	defer p.workersMu.Unlock()
 	return len(p.workers)
 }
 
-// TODO: Add any additional methods you think would be useful
-// Consider:
-// - Health check methods
-// - Job cancellation by ID
-// - Priority job submission
-// - Batch job submission
-// - Worker status inspection
-// - Configuration updates
+// GetWorkers returns a snapshot of every worker's state
+func (p *Pool) GetWorkers() []types.Worker {
+	p.workersMu.Lock()
+	defer p.workersMu.Unlock()
+
+	workers := make([]types.Worker, 0, len(p.workers))
+	for _, w := range p.workers {
+		workers = append(workers, types.Worker{
+			ID:            w.GetID(),
+			Status:        w.GetStatus(),
+			JobsProcessed: w.GetJobsProcessed(),
+			LastJobTime:   w.GetLastJobTime(),
+			StartTime:     w.GetStartTime(),
+		})
+	}
+	return workers
+}
@@ -0,0 +1,88 @@
+package pool
+
+import (
+	"time"
+
+	"github.com/cs-mastery/worker-pool/pkg/types"
+)
+
+// queueItem wraps a job waiting in the pool's priority queue
+type queueItem struct {
+	job        types.Job
+	seq        uint64 // submission order, breaks ties FIFO
+	enqueuedAt time.Time
+	index      int // position in the heap, maintained by Swap
+}
+
+// priorityQueue is a container/heap of queued jobs ordered by effective
+// priority.
+//
+// With aging enabled a job gains one priority level for every
+// agingInterval it waits. Every queued job ages at the same rate, so the
+// relative order of two jobs never changes while they wait: comparing
+// enqueuedAt - priority*agingInterval gives the same answer as
+// recomputing effective priorities at dequeue time, and the heap stays
+// valid without periodic re-sorting.
+type priorityQueue struct {
+	items         []*queueItem
+	agingInterval time.Duration
+}
+
+// newPriorityQueue creates an empty priority queue
+func newPriorityQueue(agingInterval time.Duration) *priorityQueue {
+	return &priorityQueue{agingInterval: agingInterval}
+}
+
+func (pq *priorityQueue) Len() int { return len(pq.items) }
+
+func (pq *priorityQueue) Less(i, j int) bool {
+	a, b := pq.items[i], pq.items[j]
+
+	if pq.agingInterval > 0 {
+		va := pq.virtualTime(a)
+		vb := pq.virtualTime(b)
+		if va != vb {
+			return va < vb
+		}
+	} else if a.job.Priority != b.job.Priority {
+		return a.job.Priority > b.job.Priority
+	}
+
+	return a.seq < b.seq
+}
+
+func (pq *priorityQueue) Swap(i, j int) {
+	pq.items[i], pq.items[j] = pq.items[j], pq.items[i]
+	pq.items[i].index = i
+	pq.items[j].index = j
+}
+
+func (pq *priorityQueue) Push(x interface{}) {
+	item := x.(*queueItem)
+	item.index = len(pq.items)
+	pq.items = append(pq.items, item)
+}
+
+func (pq *priorityQueue) Pop() interface{} {
+	old := pq.items
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = nil
+	item.index = -1
+	pq.items = old[:n-1]
+	return item
+}
+
+// peek returns the next job to dispatch without removing it
+func (pq *priorityQueue) peek() *queueItem {
+	if len(pq.items) == 0 {
+		return nil
+	}
+	return pq.items[0]
+}
+
+// virtualTime returns the enqueue time shifted earlier by the job's
+// priority, in nanoseconds
+func (pq *priorityQueue) virtualTime(item *queueItem) int64 {
+	return item.enqueuedAt.UnixNano() - int64(item.job.Priority)*int64(pq.agingInterval)
+}
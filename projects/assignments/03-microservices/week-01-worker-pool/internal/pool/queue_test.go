@@ -0,0 +1,179 @@
+package pool
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cs-mastery/worker-pool/pkg/types"
+)
+
+// runInOrder holds the only worker of a pool with a blocking job while
+// submit queues n jobs, then releases it and returns the IDs of the
+// queued jobs in the order they ran
+func runInOrder(t *testing.T, agingInterval time.Duration, n int, submit func(p *Pool)) []string {
+	t.Helper()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var mu sync.Mutex
+	var order []string
+
+	p := NewPool(types.PoolConfig{
+		WorkerCount:   1,
+		QueueSize:     n + 1,
+		AgingInterval: agingInterval,
+		Handler: func(ctx context.Context, job types.Job) (interface{}, error) {
+			if job.ID == "blocker" {
+				close(started)
+				<-release
+				return nil, nil
+			}
+			mu.Lock()
+			order = append(order, job.ID)
+			mu.Unlock()
+			return nil, nil
+		},
+	})
+	if err := p.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer p.Stop()
+
+	if err := p.Submit(types.Job{ID: "blocker"}); err != nil {
+		t.Fatal(err)
+	}
+	<-started
+
+	submit(p)
+	close(release)
+
+	for i := 0; i <= n; i++ {
+		if _, err := p.GetResult(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	return order
+}
+
+// startPool starts a pool and stops it when the test ends
+func startPool(t *testing.T, config types.PoolConfig) *Pool {
+	t.Helper()
+	p := NewPool(config)
+	if err := p.Start(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { p.Stop() })
+	return p
+}
+
+// submitAll submits jobs to p, failing the test on error
+func submitAll(t *testing.T, p *Pool, jobs ...types.Job) {
+	t.Helper()
+	for _, job := range jobs {
+		if err := p.Submit(job); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestPriorityOrdering(t *testing.T) {
+	jobs := []types.Job{
+		{ID: "low-1", Priority: 1},
+		{ID: "high-1", Priority: 9},
+		{ID: "medium-1", Priority: 5},
+		{ID: "low-2", Priority: 1},
+		{ID: "high-2", Priority: 9},
+		{ID: "medium-2", Priority: 5},
+	}
+
+	got := runInOrder(t, 0, len(jobs), func(p *Pool) { submitAll(t, p, jobs...) })
+	want := []string{"high-1", "high-2", "medium-1", "medium-2", "low-1", "low-2"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("run order = %v, want %v", got, want)
+	}
+}
+
+func TestPriorityAging(t *testing.T) {
+	// With a 1ms aging interval the starved job gains a level every
+	// millisecond, so after 50ms it overtakes a job 8 levels above it
+	got := runInOrder(t, time.Millisecond, 2, func(p *Pool) {
+		submitAll(t, p, types.Job{ID: "starved", Priority: 1})
+		time.Sleep(50 * time.Millisecond)
+		submitAll(t, p, types.Job{ID: "urgent", Priority: 9})
+	})
+	want := []string{"starved", "urgent"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("run order = %v, want %v", got, want)
+	}
+}
+
+func TestPriorityAgingKeepsOrderOfFreshJobs(t *testing.T) {
+	// Jobs that waited about as long have aged about as much, so a long
+	// aging interval leaves them in strict priority order
+	jobs := []types.Job{
+		{ID: "low", Priority: 1},
+		{ID: "high", Priority: 9},
+		{ID: "medium", Priority: 5},
+	}
+
+	got := runInOrder(t, time.Hour, len(jobs), func(p *Pool) {
+		submitAll(t, p, jobs...)
+		time.Sleep(10 * time.Millisecond)
+	})
+	want := []string{"high", "medium", "low"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("run order = %v, want %v", got, want)
+	}
+}
+
+func TestWaitTimeByPriority(t *testing.T) {
+	p := startPool(t, types.PoolConfig{
+		WorkerCount:     1,
+		EnableMetrics:   true,
+		MetricsInterval: time.Hour,
+		Handler: func(ctx context.Context, job types.Job) (interface{}, error) {
+			time.Sleep(5 * time.Millisecond)
+			return nil, nil
+		},
+	})
+
+	// Paused, every job waits at least 20ms; the low jobs also wait for
+	// the high ones to run
+	if err := p.Pause(); err != nil {
+		t.Fatal(err)
+	}
+	submitAll(t, p,
+		types.Job{ID: "low-1", Priority: 1},
+		types.Job{ID: "high-1", Priority: 9},
+		types.Job{ID: "low-2", Priority: 2},
+		types.Job{ID: "high-2", Priority: types.PriorityHigh},
+		types.Job{ID: "low-3", Priority: types.PriorityMedium - 1},
+	)
+	time.Sleep(20 * time.Millisecond)
+	if err := p.Resume(); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 5; i++ {
+		if _, err := p.GetResult(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	waits := p.GetMetrics().WaitTimeByPriority
+	high, low := waits["high"], waits["low"]
+	if len(waits) != 2 || high.Jobs != 2 || low.Jobs != 3 {
+		t.Fatalf("wait times = %+v, want 2 high and 3 low jobs", waits)
+	}
+	if high.Average < 20*time.Millisecond || high.Max < high.Average {
+		t.Errorf("high wait = %+v, want an average of at least 20ms and a max no lower", high)
+	}
+	if low.Average <= high.Average || low.Max <= high.Max {
+		t.Errorf("low wait = %+v, want longer than high %+v", low, high)
+	}
+}
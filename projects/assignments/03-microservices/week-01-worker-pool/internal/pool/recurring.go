@@ -0,0 +1,204 @@
+package pool
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/cs-mastery/worker-pool/pkg/types"
+)
+
+// recurringEntry is a registered recurring job and its run state
+type recurringEntry struct {
+	def      types.RecurringJob
+	schedule cronSchedule
+	next     time.Time
+	lastRun  time.Time
+	runs     int64
+	skipped  int64
+	running  map[string]context.CancelFunc // run job ID -> cancel
+}
+
+// info returns the public view of the entry. Caller holds recurringMu.
+func (e *recurringEntry) info() types.ScheduleInfo {
+	return types.ScheduleInfo{
+		Name:    e.def.Name,
+		Spec:    e.def.Spec,
+		Overlap: e.def.Overlap,
+		NextRun: e.next,
+		LastRun: e.lastRun,
+		Runs:    e.runs,
+		Skipped: e.skipped,
+		Running: len(e.running),
+	}
+}
+
+// AddRecurringJob registers a job that is submitted to the pool on a cron
+// schedule. Schedules can be registered before or after Start; they only
+// fire while the pool is running.
+func (p *Pool) AddRecurringJob(def types.RecurringJob) error {
+	if def.Name == "" {
+		return fmt.Errorf("recurring job name is required")
+	}
+
+	schedule, err := parseCron(def.Spec)
+	if err != nil {
+		return err
+	}
+
+	next := schedule.next(time.Now())
+	if next.IsZero() {
+		return fmt.Errorf("cron spec %q never fires", def.Spec)
+	}
+
+	p.recurringMu.Lock()
+	if _, exists := p.recurring[def.Name]; exists {
+		p.recurringMu.Unlock()
+		return fmt.Errorf("recurring job %q already registered", def.Name)
+	}
+	p.recurring[def.Name] = &recurringEntry{
+		def:      def,
+		schedule: schedule,
+		next:     next,
+		running:  make(map[string]context.CancelFunc),
+	}
+	p.recurringMu.Unlock()
+
+	p.wakeRecurring()
+	return nil
+}
+
+// RemoveRecurringJob unregisters a recurring job. Runs that were already
+// submitted are not affected.
+func (p *Pool) RemoveRecurringJob(name string) bool {
+	p.recurringMu.Lock()
+	_, ok := p.recurring[name]
+	delete(p.recurring, name)
+	p.recurringMu.Unlock()
+
+	if ok {
+		p.wakeRecurring()
+	}
+	return ok
+}
+
+// ListRecurringJobs returns the registered recurring jobs, soonest first
+func (p *Pool) ListRecurringJobs() []types.ScheduleInfo {
+	p.recurringMu.Lock()
+	infos := make([]types.ScheduleInfo, 0, len(p.recurring))
+	for _, entry := range p.recurring {
+		infos = append(infos, entry.info())
+	}
+	p.recurringMu.Unlock()
+
+	sort.Slice(infos, func(i, j int) bool {
+		if !infos[i].NextRun.Equal(infos[j].NextRun) {
+			return infos[i].NextRun.Before(infos[j].NextRun)
+		}
+		return infos[i].Name < infos[j].Name
+	})
+	return infos
+}
+
+// wakeRecurring makes runRecurring re-read the schedule
+func (p *Pool) wakeRecurring() {
+	select {
+	case p.recurringWake <- struct{}{}:
+	default:
+	}
+}
+
+// runRecurring fires recurring jobs as they come due. Runs missed while
+// the pool was stopped fire once on restart rather than all at once.
+func (p *Pool) runRecurring(ctx context.Context) {
+	defer p.wg.Done()
+
+	timer := time.NewTimer(time.Hour)
+	defer timer.Stop()
+
+	for {
+		now := time.Now()
+		var earliest time.Time
+
+		p.recurringMu.Lock()
+		for _, entry := range p.recurring {
+			if !entry.next.After(now) {
+				p.fireRecurring(ctx, entry, now)
+				entry.next = entry.schedule.next(now)
+			}
+			if !entry.next.IsZero() && (earliest.IsZero() || entry.next.Before(earliest)) {
+				earliest = entry.next
+			}
+		}
+		p.recurringMu.Unlock()
+
+		var wait <-chan time.Time
+		if !earliest.IsZero() {
+			if !timer.Stop() {
+				select {
+				case <-timer.C:
+				default:
+				}
+			}
+			timer.Reset(time.Until(earliest))
+			wait = timer.C
+		}
+
+		select {
+		case <-wait:
+		case <-p.recurringWake:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// fireRecurring submits one run of a recurring job, applying its overlap
+// policy. Caller holds recurringMu.
+func (p *Pool) fireRecurring(ctx context.Context, entry *recurringEntry, now time.Time) {
+	switch entry.def.Overlap {
+	case types.OverlapSkip:
+		if len(entry.running) > 0 {
+			entry.skipped++
+			return
+		}
+	case types.OverlapCancelPrevious:
+		for _, cancel := range entry.running {
+			cancel()
+		}
+	}
+
+	parent := entry.def.Job.Context
+	if parent == nil {
+		parent = context.Background()
+	}
+	runCtx, cancel := context.WithCancel(parent)
+
+	job := entry.def.Job
+	job.ID = fmt.Sprintf("%s-%d", entry.def.Name, now.UnixNano())
+	job.Context = runCtx
+	// Every run shares the template's key, which would deduplicate all
+	// runs after the first
+	job.IdempotencyKey, job.IdempotencyFingerprint = "", ""
+
+	entry.running[job.ID] = cancel
+	entry.runs++
+	entry.lastRun = now
+
+	release := func() {
+		p.recurringMu.Lock()
+		delete(entry.running, job.ID)
+		p.recurringMu.Unlock()
+		cancel()
+	}
+	w := &watcher{fn: func(types.JobResult) { release() }}
+
+	// Submit outside the scheduler loop so a full queue does not delay
+	// other schedules
+	go func() {
+		if err := p.submit(ctx, job, w); err != nil {
+			release()
+		}
+	}()
+}
@@ -0,0 +1,366 @@
+package pool
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/cs-mastery/worker-pool/pkg/types"
+)
+
+// Registry errors
+var (
+	ErrJobNotFound    = errors.New("job not found")
+	ErrDuplicateJobID = errors.New("job ID already in use")
+	ErrJobFinished    = errors.New("job already finished")
+)
+
+// jobEntry is a registry record plus the job's cancel function
+type jobEntry struct {
+	record     types.JobRecord
+	cancel     context.CancelFunc
+	finishedAt time.Time
+}
+
+// finishedJob remembers when a job finished, for eviction in finish order
+type finishedJob struct {
+	id string
+	at time.Time
+}
+
+// jobRegistry tracks every submitted job by ID. Jobs that are queued,
+// scheduled or running are always kept; finished jobs are kept for ttl
+// and at most max of them.
+type jobRegistry struct {
+	mu       sync.Mutex
+	entries  map[string]*jobEntry
+	finished []finishedJob
+	ttl      time.Duration
+	max      int
+	changed  chan struct{} // Signalled when an active job finishes or is dropped
+}
+
+// newJobRegistry creates an empty registry
+func newJobRegistry(ttl time.Duration, max int) *jobRegistry {
+	return &jobRegistry{
+		entries: make(map[string]*jobEntry),
+		ttl:     ttl,
+		max:     max,
+		changed: make(chan struct{}, 1),
+	}
+}
+
+// register starts tracking a job. It fails if a job with the same ID is
+// still active; a finished record with the same ID is replaced.
+func (r *jobRegistry) register(job types.Job, status types.JobStatus, cancel context.CancelFunc) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if entry, ok := r.entries[job.ID]; ok && entry.finishedAt.IsZero() {
+		return fmt.Errorf("%w: %s", ErrDuplicateJobID, job.ID)
+	}
+
+	now := time.Now()
+	r.entries[job.ID] = &jobEntry{
+		record: types.JobRecord{
+			ID:          job.ID,
+			Status:      status,
+			Attempt:     job.Attempt,
+			SubmittedAt: now,
+			UpdatedAt:   now,
+			History:     []types.StatusChange{{Status: status, At: now}},
+		},
+		cancel: cancel,
+	}
+	return nil
+}
+
+// unregister forgets a job that never made it into the pool and releases
+// its context
+func (r *jobRegistry) unregister(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if entry, ok := r.entries[id]; ok {
+		delete(r.entries, id)
+		entry.cancel()
+		r.signalLocked()
+	}
+}
+
+// transition records a status change for an active job
+func (r *jobRegistry) transition(id string, status types.JobStatus, attempt int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry, ok := r.entries[id]
+	if !ok || !entry.finishedAt.IsZero() {
+		return
+	}
+	entry.record.Attempt = attempt
+	r.setStatusLocked(entry, status, time.Now())
+}
+
+// started records that a worker picked up an attempt of a job
+func (r *jobRegistry) started(id string, attempt, workerID int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry, ok := r.entries[id]
+	if !ok || !entry.finishedAt.IsZero() {
+		return
+	}
+	entry.record.Attempt = attempt
+	entry.record.WorkerID = workerID
+	r.setStatusLocked(entry, types.JobProcessing, time.Now())
+}
+
+// finish records a job's final result and releases its context
+func (r *jobRegistry) finish(result types.JobResult) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry, ok := r.entries[result.JobID]
+	if !ok || !entry.finishedAt.IsZero() {
+		return
+	}
+
+	now := time.Now()
+	entry.record.Attempt = result.Attempt
+	entry.record.WorkerID = result.WorkerID
+	entry.record.Error = result.Error
+	r.setStatusLocked(entry, statusForError(result.Error), now)
+	entry.finishedAt = now
+	entry.cancel()
+
+	r.finished = append(r.finished, finishedJob{id: result.JobID, at: now})
+	r.evictLocked(now)
+	r.signalLocked()
+}
+
+// active returns the IDs of jobs that are queued, scheduled or running,
+// split by whether any attempt has started
+func (r *jobRegistry) active() (started, notStarted []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, entry := range r.entries {
+		if !entry.finishedAt.IsZero() {
+			continue
+		}
+		if entry.record.Attempt > 1 || hasStatus(entry.record.History, types.JobProcessing) {
+			started = append(started, id)
+		} else {
+			notStarted = append(notStarted, id)
+		}
+	}
+	return started, notStarted
+}
+
+// hasStatus reports whether a job's history includes status
+func hasStatus(history []types.StatusChange, status types.JobStatus) bool {
+	for _, change := range history {
+		if change.Status == status {
+			return true
+		}
+	}
+	return false
+}
+
+// signalLocked wakes a goroutine waiting on changed. Caller holds mu.
+func (r *jobRegistry) signalLocked() {
+	select {
+	case r.changed <- struct{}{}:
+	default:
+	}
+}
+
+// get returns a copy of a job's record
+func (r *jobRegistry) get(id string) (types.JobRecord, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.evictLocked(time.Now())
+
+	entry, ok := r.entries[id]
+	if !ok {
+		return types.JobRecord{}, false
+	}
+
+	record := entry.record
+	record.History = append([]types.StatusChange(nil), entry.record.History...)
+	return record, true
+}
+
+// cancelFunc returns the cancel function of an active job
+func (r *jobRegistry) cancelFunc(id string) (context.CancelFunc, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry, ok := r.entries[id]
+	if !ok {
+		return nil, ErrJobNotFound
+	}
+	if !entry.finishedAt.IsZero() {
+		return nil, fmt.Errorf("%w: %s is %s", ErrJobFinished, id, entry.record.Status)
+	}
+	return entry.cancel, nil
+}
+
+func (r *jobRegistry) setStatusLocked(entry *jobEntry, status types.JobStatus, at time.Time) {
+	entry.record.Status = status
+	entry.record.UpdatedAt = at
+	entry.record.History = append(entry.record.History, types.StatusChange{Status: status, At: at})
+}
+
+// evictLocked drops finished records past their TTL or beyond max
+func (r *jobRegistry) evictLocked(now time.Time) {
+	for len(r.finished) > 0 {
+		oldest := r.finished[0]
+		expired := r.ttl > 0 && now.Sub(oldest.at) > r.ttl
+		if !expired && len(r.finished) <= r.max {
+			return
+		}
+
+		r.finished = r.finished[1:]
+		// The ID may have been resubmitted since; only drop the record
+		// this entry refers to
+		if entry, ok := r.entries[oldest.id]; ok && entry.finishedAt.Equal(oldest.at) {
+			delete(r.entries, oldest.id)
+		}
+	}
+}
+
+// statusForError maps a job's final error to its status
+func statusForError(err error) types.JobStatus {
+	switch {
+	case err == nil:
+		return types.JobCompleted
+	case errors.Is(err, context.DeadlineExceeded):
+		return types.JobTimedOut
+	case errors.Is(err, context.Canceled):
+		return types.JobCancelled
+	default:
+		return types.JobFailed
+	}
+}
+
+// track gives a job its own cancellable context and registers it, along
+// with w (if not nil) to receive its final result. The returned job must
+// be used in place of the original.
+func (p *Pool) track(job types.Job, status types.JobStatus, w *watcher) (types.Job, error) {
+	parent := job.Context
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx, cancel := context.WithCancel(parent)
+	job.Context = ctx
+
+	if err := p.jobs.register(job, status, cancel); err != nil {
+		cancel()
+		return job, err
+	}
+	if w != nil {
+		p.watch(job.ID, w)
+	}
+	return job, nil
+}
+
+// untrack forgets a job whose submission failed
+func (p *Pool) untrack(job types.Job) {
+	p.unwatch(job.ID)
+	p.jobs.unregister(job.ID)
+}
+
+// GetJobStatus returns the tracked state of a job. Finished jobs remain
+// available for PoolConfig.JobRetention.
+func (p *Pool) GetJobStatus(id string) (types.JobRecord, error) {
+	record, ok := p.jobs.get(id)
+	if !ok {
+		return types.JobRecord{}, fmt.Errorf("%w: %s", ErrJobNotFound, id)
+	}
+	return record, nil
+}
+
+// CancelJob cancels a job that is scheduled, queued or running. Waiting
+// jobs are removed from the queue straight away; a running job has its
+// context cancelled and finishes once its handler returns.
+func (p *Pool) CancelJob(id string) error {
+	cancel, err := p.jobs.cancelFunc(id)
+	if err != nil {
+		return err
+	}
+	cancel()
+
+	job, removed := p.removeQueued(id)
+	if !removed && p.stealer != nil {
+		if job, removed = p.stealer.removeJob(id); removed {
+			<-p.slots
+			p.releaseTypeSlot(job)
+			p.releaseTenant(job)
+			p.releaseKey(job)
+		}
+	}
+	if !removed {
+		job, removed = p.removeScheduled(id)
+	}
+	if removed {
+		p.metrics.IncrementJobsCancelled()
+		p.finish(p.poolContext(), types.JobResult{
+			JobID:   id,
+			Error:   context.Canceled,
+			Attempt: job.Attempt,
+			Errors:  append(append([]error(nil), job.AttemptErrors...), context.Canceled),
+		})
+	}
+	return nil
+}
+
+// removeQueued removes a job from the ready queue unless the dispatcher is
+// already handing it to a worker
+func (p *Pool) removeQueued(id string) (types.Job, bool) {
+	p.queueMu.Lock()
+	defer p.queueMu.Unlock()
+
+	var found *queueItem
+	p.queue.each(func(item *queueItem) {
+		if item.job.ID == id && item != p.offering {
+			found = item
+		}
+	})
+	if found != nil {
+		p.queue.remove(found)
+		p.releaseKeyLocked(found.job)
+		<-p.slots
+		return found.job, true
+	}
+
+	p.eachParkedLocked(func(item *queueItem) {
+		if item.job.ID == id {
+			found = item
+		}
+	})
+	if found != nil {
+		p.removeParkedLocked(found)
+		<-p.slots
+		return found.job, true
+	}
+	return types.Job{}, false
+}
+
+// removeScheduled removes a job that has not come due yet
+func (p *Pool) removeScheduled(id string) (types.Job, bool) {
+	p.scheduleMu.Lock()
+	defer p.scheduleMu.Unlock()
+
+	for _, item := range p.scheduled {
+		if item.job.ID == id {
+			heap.Remove(&p.scheduled, item.index)
+			return item.job, true
+		}
+	}
+	return types.Job{}, false
+}
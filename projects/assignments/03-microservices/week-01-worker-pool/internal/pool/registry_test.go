@@ -0,0 +1,200 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cs-mastery/worker-pool/pkg/types"
+)
+
+func TestJobStatusLifecycle(t *testing.T) {
+	p := NewPool(types.PoolConfig{
+		WorkerCount: 1,
+		Handler: func(ctx context.Context, job types.Job) (interface{}, error) {
+			return "done", nil
+		},
+	})
+	if err := p.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer p.Stop()
+
+	if err := p.Submit(types.Job{ID: "job-1"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.GetResult(); err != nil {
+		t.Fatal(err)
+	}
+
+	record, err := p.GetJobStatus("job-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if record.Status != types.JobCompleted || record.Attempt != 1 {
+		t.Errorf("record = %s attempt %d, want completed attempt 1", record.Status, record.Attempt)
+	}
+	var history []types.JobStatus
+	for _, change := range record.History {
+		history = append(history, change.Status)
+	}
+	want := []types.JobStatus{types.JobPending, types.JobProcessing, types.JobCompleted}
+	if len(history) != len(want) {
+		t.Fatalf("history = %v, want %v", history, want)
+	}
+	for i := range want {
+		if history[i] != want[i] {
+			t.Fatalf("history = %v, want %v", history, want)
+		}
+	}
+
+	if _, err := p.GetJobStatus("missing"); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("GetJobStatus(missing) = %v, want ErrJobNotFound", err)
+	}
+	if err := p.CancelJob("job-1"); !errors.Is(err, ErrJobFinished) {
+		t.Errorf("CancelJob(finished) = %v, want ErrJobFinished", err)
+	}
+	if err := p.CancelJob("missing"); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("CancelJob(missing) = %v, want ErrJobNotFound", err)
+	}
+
+	// A finished ID can be reused
+	if err := p.Submit(types.Job{ID: "job-1"}); err != nil {
+		t.Errorf("resubmitting a finished ID: %v", err)
+	}
+}
+
+func TestCancelQueuedAndRunningJobs(t *testing.T) {
+	started := make(chan struct{})
+	p := NewPool(types.PoolConfig{
+		WorkerCount: 1,
+		Handler: func(ctx context.Context, job types.Job) (interface{}, error) {
+			if job.ID == "running" {
+				close(started)
+			}
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+	})
+	if err := p.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer p.Stop()
+
+	if err := p.Submit(types.Job{ID: "running"}); err != nil {
+		t.Fatal(err)
+	}
+	<-started
+	// The dispatcher takes the running job off the queue just after
+	// handing it over
+	for p.GetQueueLength() > 0 {
+		time.Sleep(time.Millisecond)
+	}
+	if err := p.Submit(types.Job{ID: "queued"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Submit(types.Job{ID: "queued"}); !errors.Is(err, ErrDuplicateJobID) {
+		t.Errorf("submitting an active ID = %v, want ErrDuplicateJobID", err)
+	}
+
+	// The queued job is removed straight away, without a worker
+	if err := p.CancelJob("queued"); err != nil {
+		t.Fatal(err)
+	}
+	result, err := p.GetResult()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.JobID != "queued" || !errors.Is(result.Error, context.Canceled) {
+		t.Fatalf("first result = %s %v, want queued cancelled", result.JobID, result.Error)
+	}
+	if n := p.GetQueueLength(); n != 0 {
+		t.Errorf("queue length = %d after cancelling, want 0", n)
+	}
+
+	// The running job sees its context cancelled
+	if err := p.CancelJob("running"); err != nil {
+		t.Fatal(err)
+	}
+	result, err = p.GetResult()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.JobID != "running" || !errors.Is(result.Error, context.Canceled) {
+		t.Fatalf("second result = %s %v, want running cancelled", result.JobID, result.Error)
+	}
+
+	for _, id := range []string{"queued", "running"} {
+		record, err := p.GetJobStatus(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if record.Status != types.JobCancelled {
+			t.Errorf("%s status = %s, want cancelled", id, record.Status)
+		}
+	}
+}
+
+func TestCancelledJobNeverStarts(t *testing.T) {
+	var calls atomic.Int32
+	p := NewPool(types.PoolConfig{
+		WorkerCount: 1,
+		Handler: func(ctx context.Context, job types.Job) (interface{}, error) {
+			calls.Add(1)
+			return nil, nil
+		},
+	})
+	if err := p.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer p.Stop()
+
+	// The job is already cancelled when a worker picks it up
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for i := 0; i < 20; i++ {
+		if err := p.Submit(types.Job{ID: fmt.Sprint(i), Context: ctx}); err != nil {
+			t.Fatal(err)
+		}
+		result, err := p.GetResult()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !errors.Is(result.Error, context.Canceled) {
+			t.Fatalf("result error = %v, want context.Canceled", result.Error)
+		}
+	}
+	if n := calls.Load(); n != 0 {
+		t.Errorf("handler ran %d cancelled jobs", n)
+	}
+}
+
+func TestJobRegistryEvictsFinishedRecords(t *testing.T) {
+	r := newJobRegistry(0, 2)
+	for _, id := range []string{"a", "b", "c"} {
+		if err := r.register(types.Job{ID: id, Attempt: 1}, types.JobPending, func() {}); err != nil {
+			t.Fatal(err)
+		}
+		r.finish(types.JobResult{JobID: id})
+	}
+
+	if _, ok := r.get("a"); ok {
+		t.Error("oldest finished record was kept beyond the limit")
+	}
+	for _, id := range []string{"b", "c"} {
+		if _, ok := r.get(id); !ok {
+			t.Errorf("record %s was evicted", id)
+		}
+	}
+
+	r = newJobRegistry(time.Millisecond, 10)
+	r.register(types.Job{ID: "old", Attempt: 1}, types.JobPending, func() {})
+	r.finish(types.JobResult{JobID: "old"})
+	time.Sleep(5 * time.Millisecond)
+	if _, ok := r.get("old"); ok {
+		t.Error("record was kept past its retention")
+	}
+}
@@ -0,0 +1,135 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/cs-mastery/worker-pool/pkg/types"
+)
+
+// ExponentialBackoff multiplies the delay by Multiplier on every retry,
+// starting at Initial and capped at Max
+type ExponentialBackoff struct {
+	Initial    time.Duration
+	Max        time.Duration
+	Multiplier float64 // Defaults to 2 when <= 1
+}
+
+// Next implements types.BackoffPolicy
+func (b ExponentialBackoff) Next(attempt int, previous time.Duration) time.Duration {
+	multiplier := b.Multiplier
+	if multiplier <= 1 {
+		multiplier = 2
+	}
+
+	// attempt 2 is the first retry and waits Initial
+	delay := float64(b.Initial) * math.Pow(multiplier, float64(attempt-2))
+	if b.Max > 0 && delay > float64(b.Max) {
+		return b.Max
+	}
+	return time.Duration(delay)
+}
+
+// DecorrelatedJitterBackoff picks a random delay between Base and three
+// times the previous delay, capped at Max. Spreading retries this way
+// keeps many failing jobs from retrying in lockstep.
+type DecorrelatedJitterBackoff struct {
+	Base time.Duration
+	Max  time.Duration
+}
+
+// Next implements types.BackoffPolicy
+func (b DecorrelatedJitterBackoff) Next(attempt int, previous time.Duration) time.Duration {
+	if previous < b.Base {
+		previous = b.Base
+	}
+
+	upper := previous * 3
+	delay := b.Base
+	if upper > b.Base {
+		delay += time.Duration(rand.Int63n(int64(upper - b.Base)))
+	}
+	if b.Max > 0 && delay > b.Max {
+		return b.Max
+	}
+	return delay
+}
+
+// permanentError marks an error that must not be retried
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent wraps err so the default classifier never retries it
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsRetryable is the default error classifier. Errors wrapped with
+// Permanent, handler panics, abandoned jobs, expired leases and
+// cancellations are not retried; everything else, including timeouts, is.
+func IsRetryable(err error) bool {
+	var permanent *permanentError
+	if errors.As(err, &permanent) {
+		return false
+	}
+	var panicked *PanicError
+	if errors.As(err, &panicked) || errors.Is(err, ErrJobAbandoned) ||
+		errors.Is(err, ErrLeaseExpired) {
+		return false
+	}
+	return !errors.Is(err, context.Canceled)
+}
+
+// retryPolicy returns the policy that applies to job
+func (p *Pool) retryPolicy(job types.Job) types.RetryPolicy {
+	if job.RetryPolicy != nil {
+		return *job.RetryPolicy
+	}
+	if config, ok := p.handlers.Lookup(job.Type); ok && config.RetryPolicy != nil {
+		return *config.RetryPolicy
+	}
+	return p.config.RetryPolicy
+}
+
+// shouldRetry reports whether a job that just failed with err gets
+// another attempt under policy
+func shouldRetry(policy types.RetryPolicy, job types.Job, err error) bool {
+	if job.Attempt >= policy.MaxAttempts {
+		return false
+	}
+
+	retryable := policy.Retryable
+	if retryable == nil {
+		retryable = IsRetryable
+	}
+	return retryable(err)
+}
+
+// scheduleRetry reschedules a failed job to run again after its backoff
+// delay
+func (p *Pool) scheduleRetry(job types.Job, result types.JobResult, policy types.RetryPolicy) {
+	job.Attempt++
+	job.AttemptErrors = result.Errors
+	job.Deliveries = 0
+	if policy.Backoff != nil {
+		job.RetryDelay = policy.Backoff.Next(job.Attempt, job.RetryDelay)
+	} else {
+		job.RetryDelay = 0
+	}
+
+	p.metrics.IncrementJobsRetried()
+	runAt := time.Now().Add(job.RetryDelay)
+	p.jobs.transition(job.ID, types.JobScheduled, job.Attempt)
+	p.recordState(job, types.JobScheduled, runAt)
+	p.schedule(job, runAt)
+}
@@ -0,0 +1,211 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cs-mastery/worker-pool/pkg/types"
+)
+
+func TestExponentialBackoff(t *testing.T) {
+	backoff := ExponentialBackoff{Initial: 10 * time.Millisecond, Max: 50 * time.Millisecond}
+
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{2, 10 * time.Millisecond},
+		{3, 20 * time.Millisecond},
+		{4, 40 * time.Millisecond},
+		{5, 50 * time.Millisecond},
+		{10, 50 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		if got := backoff.Next(tt.attempt, 0); got != tt.want {
+			t.Errorf("Next(%d) = %v, want %v", tt.attempt, got, tt.want)
+		}
+	}
+}
+
+func TestDecorrelatedJitterBackoff(t *testing.T) {
+	backoff := DecorrelatedJitterBackoff{Base: 10 * time.Millisecond, Max: 100 * time.Millisecond}
+
+	previous := time.Duration(0)
+	for attempt := 2; attempt < 50; attempt++ {
+		delay := backoff.Next(attempt, previous)
+		if delay < backoff.Base || delay > backoff.Max {
+			t.Fatalf("Next(%d, %v) = %v, want within [%v, %v]", attempt, previous, delay, backoff.Base, backoff.Max)
+		}
+		previous = delay
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"plain error", errors.New("boom"), true},
+		{"timeout", context.DeadlineExceeded, true},
+		{"cancelled", context.Canceled, false},
+		{"permanent", Permanent(errors.New("bad input")), false},
+		{"wrapped permanent", fmt.Errorf("step: %w", Permanent(errors.New("bad input"))), false},
+		{"panic", &PanicError{Value: "boom"}, false},
+		{"abandoned", fmt.Errorf("%w: %w", ErrJobAbandoned, context.DeadlineExceeded), false},
+		{"lease expired", ErrLeaseExpired, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRetryable(tt.err); got != tt.want {
+				t.Errorf("IsRetryable(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryUntilSuccess(t *testing.T) {
+	var calls atomic.Int32
+	p := NewPool(types.PoolConfig{
+		WorkerCount: 1,
+		RetryPolicy: types.RetryPolicy{
+			MaxAttempts: 3,
+			Backoff:     ExponentialBackoff{Initial: time.Millisecond},
+		},
+		Handler: func(ctx context.Context, job types.Job) (interface{}, error) {
+			if calls.Add(1) < 3 {
+				return nil, fmt.Errorf("attempt %d failed", job.Attempt)
+			}
+			return "ok", nil
+		},
+	})
+	if err := p.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer p.Stop()
+
+	if err := p.Submit(types.Job{ID: "flaky"}); err != nil {
+		t.Fatal(err)
+	}
+	result, err := p.GetResult()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Error != nil || result.Data != "ok" {
+		t.Fatalf("result = %v, %v; want ok", result.Data, result.Error)
+	}
+	if result.Attempt != 3 {
+		t.Errorf("Attempt = %d, want 3", result.Attempt)
+	}
+	if len(result.Errors) != 2 {
+		t.Errorf("Errors = %v, want the 2 failed attempts", result.Errors)
+	}
+}
+
+func TestRetryExhaustedDeadLetters(t *testing.T) {
+	var calls atomic.Int32
+	var fixed atomic.Bool
+	p := NewPool(types.PoolConfig{
+		WorkerCount:    1,
+		DeadLetterSize: 10,
+		RetryPolicy:    types.RetryPolicy{MaxAttempts: 2},
+		Handler: func(ctx context.Context, job types.Job) (interface{}, error) {
+			if fixed.Load() {
+				return "ok", nil
+			}
+			calls.Add(1)
+			return nil, errors.New("always fails")
+		},
+	})
+	if err := p.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer p.Stop()
+
+	if err := p.Submit(types.Job{ID: "doomed"}); err != nil {
+		t.Fatal(err)
+	}
+	result, err := p.GetResult()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Error == nil || result.Attempt != 2 || calls.Load() != 2 {
+		t.Fatalf("result error %v after attempt %d and %d calls, want a failure after 2", result.Error, result.Attempt, calls.Load())
+	}
+	letter, ok := p.GetDeadLetter("doomed")
+	if !ok {
+		t.Fatal("job was not dead-lettered")
+	}
+	if len(letter.Result.Errors) != 2 {
+		t.Errorf("dead letter errors = %v, want 2", letter.Result.Errors)
+	}
+
+	// Requeueing starts over with a fresh attempt count
+	fixed.Store(true)
+	if err := p.RequeueDeadLetter(context.Background(), "doomed"); err != nil {
+		t.Fatal(err)
+	}
+	result, err = p.GetResult()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Error != nil || result.Attempt != 1 || result.Deliveries != 1 {
+		t.Errorf("requeued result = attempt %d, delivery %d, %v; want attempt 1, delivery 1 succeeding",
+			result.Attempt, result.Deliveries, result.Error)
+	}
+	if n := len(p.ListDeadLetters()); n != 0 {
+		t.Errorf("%d dead letters left after requeue, want 0", n)
+	}
+}
+
+func TestPermanentErrorIsNotRetried(t *testing.T) {
+	var calls atomic.Int32
+	p := NewPool(types.PoolConfig{
+		WorkerCount:    1,
+		DeadLetterSize: 10,
+		RetryPolicy:    types.RetryPolicy{MaxAttempts: 5},
+		Handler: func(ctx context.Context, job types.Job) (interface{}, error) {
+			calls.Add(1)
+			return nil, Permanent(errors.New("bad input"))
+		},
+	})
+	if err := p.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer p.Stop()
+
+	if err := p.Submit(types.Job{ID: "bad"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.GetResult(); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := calls.Load(); n != 1 {
+		t.Errorf("handler called %d times, want 1", n)
+	}
+	if _, ok := p.GetDeadLetter("bad"); !ok {
+		t.Error("job was not dead-lettered")
+	}
+}
+
+func TestDeadLetterQueueEvictsOldest(t *testing.T) {
+	q := newDeadLetterQueue(2)
+	for _, id := range []string{"a", "b", "c"} {
+		q.add(types.DeadLetter{Job: types.Job{ID: id}})
+	}
+
+	letters := q.list()
+	if len(letters) != 2 || letters[0].Job.ID != "b" || letters[1].Job.ID != "c" {
+		t.Fatalf("dead letters = %v, want b and c", letters)
+	}
+	if n := q.purge(); n != 2 || q.len() != 0 {
+		t.Errorf("purge removed %d, %d left; want 2 and 0", n, q.len())
+	}
+}
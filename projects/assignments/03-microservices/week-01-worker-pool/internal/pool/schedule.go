@@ -0,0 +1,237 @@
+package pool
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/cs-mastery/worker-pool/pkg/types"
+)
+
+// UndeliveredJobsError is returned by Stop when scheduled jobs had not
+// come due yet. The jobs stay scheduled and are delivered if the pool is
+// started again.
+type UndeliveredJobsError struct {
+	Jobs []types.ScheduledJob
+}
+
+func (e *UndeliveredJobsError) Error() string {
+	return fmt.Sprintf("%d scheduled jobs not delivered", len(e.Jobs))
+}
+
+// scheduledItem wraps a job waiting for its run time
+type scheduledItem struct {
+	job   types.Job
+	runAt time.Time
+	seq   uint64
+	index int
+}
+
+// scheduleQueue is a container/heap of scheduled jobs ordered by run time,
+// then submission order
+type scheduleQueue []*scheduledItem
+
+func (sq scheduleQueue) Len() int { return len(sq) }
+
+func (sq scheduleQueue) Less(i, j int) bool {
+	if !sq[i].runAt.Equal(sq[j].runAt) {
+		return sq[i].runAt.Before(sq[j].runAt)
+	}
+	return sq[i].seq < sq[j].seq
+}
+
+func (sq scheduleQueue) Swap(i, j int) {
+	sq[i], sq[j] = sq[j], sq[i]
+	sq[i].index = i
+	sq[j].index = j
+}
+
+func (sq *scheduleQueue) Push(x interface{}) {
+	item := x.(*scheduledItem)
+	item.index = len(*sq)
+	*sq = append(*sq, item)
+}
+
+func (sq *scheduleQueue) Pop() interface{} {
+	old := *sq
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = nil
+	item.index = -1
+	*sq = old[:n-1]
+	return item
+}
+
+// SubmitAt submits a job that is queued once runAt has passed. A runAt in
+// the past queues the job immediately. The job's tenant must have room
+// under MaxQueued when it is submitted, as with Submit; once due it is
+// queued regardless. An IdempotencyKey is claimed when the job is
+// scheduled, so duplicates fail with *DuplicateSubmissionError as they
+// do with Submit.
+func (p *Pool) SubmitAt(job types.Job, runAt time.Time) error {
+	if !runAt.After(time.Now()) {
+		return p.Submit(job)
+	}
+	if p.idempotency != nil && job.IdempotencyKey != "" {
+		return p.submitOnce(context.Background(), job, nil, func(w *watcher) error {
+			return p.scheduleNew(job, runAt, w)
+		})
+	}
+	return p.scheduleNew(job, runAt, nil)
+}
+
+// scheduleNew schedules a job for runAt without checking its idempotency
+// key, registering w (if not nil) to receive its final result
+func (p *Pool) scheduleNew(job types.Job, runAt time.Time, w *watcher) error {
+	p.mu.RLock()
+	running, draining := p.running, p.draining
+	p.mu.RUnlock()
+
+	if !running {
+		return ErrPoolNotRunning
+	}
+	if draining {
+		return ErrPoolDraining
+	}
+
+	job, err := p.prepare(job)
+	if err != nil {
+		return err
+	}
+
+	job, err = p.track(job, types.JobScheduled, w)
+	if err != nil {
+		return err
+	}
+
+	// A scheduled job holds no queue room until it is due, so the
+	// reservation only checks the tenant's limit
+	if err := p.admitTenant(job); err != nil {
+		p.untrack(job)
+		p.metrics.IncrementTenantRejected(job.Tenant)
+		return err
+	}
+	p.releaseAdmission(job)
+
+	if err := p.persist(job, runAt); err != nil {
+		p.untrack(job)
+		return err
+	}
+
+	p.schedule(job, runAt)
+	p.metrics.IncrementJobsSubmitted()
+	p.metrics.IncrementTypeSubmitted(job.Type)
+	p.metrics.IncrementTenantSubmitted(job.Tenant)
+	return nil
+}
+
+// SubmitAfter submits a job that is queued once delay has elapsed
+func (p *Pool) SubmitAfter(job types.Job, delay time.Duration) error {
+	return p.SubmitAt(job, time.Now().Add(delay))
+}
+
+// GetScheduledJobs returns the jobs waiting for their run time, earliest
+// first
+func (p *Pool) GetScheduledJobs() []types.ScheduledJob {
+	// Copy the items under the lock: the scheduler updates an item once
+	// it has popped it, and popping a copy of the heap would rewrite the
+	// shared items' indexes
+	p.scheduleMu.Lock()
+	items := make([]scheduledItem, len(p.scheduled))
+	for i, item := range p.scheduled {
+		items[i] = *item
+	}
+	p.scheduleMu.Unlock()
+
+	sort.Slice(items, func(i, j int) bool {
+		if !items[i].runAt.Equal(items[j].runAt) {
+			return items[i].runAt.Before(items[j].runAt)
+		}
+		return items[i].seq < items[j].seq
+	})
+
+	jobs := make([]types.ScheduledJob, 0, len(items))
+	for _, item := range items {
+		jobs = append(jobs, types.ScheduledJob{Job: item.job, RunAt: item.runAt})
+	}
+	return jobs
+}
+
+// getScheduledLength returns the number of jobs waiting for their run time
+func (p *Pool) getScheduledLength() int {
+	p.scheduleMu.Lock()
+	defer p.scheduleMu.Unlock()
+	return len(p.scheduled)
+}
+
+// schedule adds a job to the schedule and wakes the scheduler
+func (p *Pool) schedule(job types.Job, runAt time.Time) {
+	p.scheduleMu.Lock()
+	p.scheduleSeq++
+	heap.Push(&p.scheduled, &scheduledItem{job: job, runAt: runAt, seq: p.scheduleSeq})
+	p.scheduleMu.Unlock()
+
+	select {
+	case p.scheduleWake <- struct{}{}:
+	default:
+	}
+}
+
+// runScheduler moves scheduled jobs onto the ready queue as they come due.
+// A due job waits for a free queue slot; if the pool stops meanwhile it
+// stays scheduled.
+func (p *Pool) runScheduler(ctx context.Context) {
+	defer p.wg.Done()
+
+	timer := time.NewTimer(time.Hour)
+	defer timer.Stop()
+
+	for {
+		p.scheduleMu.Lock()
+		var next *scheduledItem
+		due := false
+		if len(p.scheduled) > 0 {
+			next = p.scheduled[0]
+			if due = !next.runAt.After(time.Now()); due {
+				heap.Pop(&p.scheduled)
+			}
+		}
+		p.scheduleMu.Unlock()
+
+		if due {
+			select {
+			case p.slots <- struct{}{}:
+				next.job.EnqueuedAt = time.Now()
+				p.jobs.transition(next.job.ID, types.JobPending, next.job.Attempt)
+				p.enqueue(next.job, false)
+			case <-ctx.Done():
+				p.scheduleMu.Lock()
+				heap.Push(&p.scheduled, next)
+				p.scheduleMu.Unlock()
+				return
+			}
+			continue
+		}
+
+		var wait <-chan time.Time
+		if next != nil {
+			if !timer.Stop() {
+				select {
+				case <-timer.C:
+				default:
+				}
+			}
+			timer.Reset(time.Until(next.runAt))
+			wait = timer.C
+		}
+
+		select {
+		case <-wait:
+		case <-p.scheduleWake:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cs-mastery/worker-pool/pkg/types"
+)
+
+func TestSubmitAtRunsWhenDue(t *testing.T) {
+	p := NewPool(types.PoolConfig{
+		WorkerCount: 1,
+		Handler: func(ctx context.Context, job types.Job) (interface{}, error) {
+			return nil, nil
+		},
+	})
+	if err := p.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer p.Stop()
+
+	submitted := time.Now()
+	if err := p.SubmitAfter(types.Job{ID: "later"}, 30*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if jobs := p.GetScheduledJobs(); len(jobs) != 1 || jobs[0].Job.ID != "later" {
+		t.Fatalf("scheduled jobs = %v, want later", jobs)
+	}
+
+	result, err := p.GetResult()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if waited := result.StartTime.Sub(submitted); waited < 30*time.Millisecond {
+		t.Errorf("job started after %v, want at least 30ms", waited)
+	}
+}
+
+func TestSubmitAtRespectsTenantLimit(t *testing.T) {
+	p := NewPool(types.PoolConfig{
+		WorkerCount:   1,
+		EnableMetrics: true,
+		Tenants:       map[string]types.TenantConfig{"small": {MaxQueued: 1}},
+		Handler: func(ctx context.Context, job types.Job) (interface{}, error) {
+			return nil, nil
+		},
+	})
+	if err := p.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer p.Stop()
+
+	// Paused, the first job stays queued and fills the tenant
+	if err := p.Pause(); err != nil {
+		t.Fatal(err)
+	}
+	submitAll(t, p, types.Job{ID: "queued", Tenant: "small"})
+
+	err := p.SubmitAfter(types.Job{ID: "later", Tenant: "small"}, time.Hour)
+	if !errors.Is(err, ErrTenantQueueFull) {
+		t.Fatalf("SubmitAfter over MaxQueued = %v, want ErrTenantQueueFull", err)
+	}
+	if _, err := p.GetJobStatus("later"); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("rejected job is still tracked: %v", err)
+	}
+	if err := p.SubmitAfter(types.Job{ID: "other", Tenant: "big"}, time.Hour); err != nil {
+		t.Errorf("SubmitAfter for another tenant: %v", err)
+	}
+
+	stats := p.GetMetrics().Tenants
+	if stats["small"].Submitted != 1 || stats["small"].Rejected != 1 || stats["big"].Submitted != 1 {
+		t.Errorf("tenant metrics = %+v, want small 1 submitted and 1 rejected, big 1 submitted", stats)
+	}
+}
+
+func TestScheduledJobsAreReported(t *testing.T) {
+	p := NewPool(types.PoolConfig{
+		WorkerCount: 1,
+		Handler: func(ctx context.Context, job types.Job) (interface{}, error) {
+			return nil, nil
+		},
+	})
+	if err := p.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Paused, the ready job stays queued alongside the scheduled ones
+	if err := p.Pause(); err != nil {
+		t.Fatal(err)
+	}
+	submitAll(t, p, types.Job{ID: "ready"})
+	if err := p.SubmitAfter(types.Job{ID: "later"}, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.SubmitAfter(types.Job{ID: "soon"}, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	if record, err := p.GetJobStatus("later"); err != nil || record.Status != types.JobScheduled {
+		t.Errorf("status of later = %v, %v; want scheduled", record.Status, err)
+	}
+	if n := p.GetQueueLength(); n != 3 {
+		t.Errorf("queue length = %d, want 1 ready and 2 scheduled", n)
+	}
+
+	err := p.Stop()
+	var undelivered *UndeliveredJobsError
+	if !errors.As(err, &undelivered) {
+		t.Fatalf("Stop = %v, want *UndeliveredJobsError", err)
+	}
+	var ids []string
+	for _, job := range undelivered.Jobs {
+		ids = append(ids, job.Job.ID)
+	}
+	if len(ids) != 2 || ids[0] != "soon" || ids[1] != "later" {
+		t.Errorf("undelivered jobs = %v, want [soon later]", ids)
+	}
+}
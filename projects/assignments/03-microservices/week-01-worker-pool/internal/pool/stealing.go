@@ -0,0 +1,345 @@
+package pool
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+
+	"github.com/cs-mastery/worker-pool/pkg/types"
+)
+
+// localQueueSize is how many jobs the work-stealing dispatcher keeps in
+// each worker's deque. Small deques keep priority order close to the
+// shared queue's while letting workers take jobs without a hand-off.
+const localQueueSize = 4
+
+// jobSource supplies jobs to a worker in place of the shared jobQueue
+type jobSource interface {
+	// take returns the next job for w without blocking
+	take(w *Worker) (types.Job, bool)
+	// wait returns a channel signalled when w may find a job
+	wait(w *Worker) <-chan struct{}
+	// idle marks w as waiting for work, or not
+	idle(w *Worker, idle bool)
+}
+
+// deque is one worker's local run queue. Its owner takes jobs from the
+// front; thieves steal from the back.
+type deque struct {
+	mu     sync.Mutex
+	jobs   []types.Job
+	signal chan struct{} // Wakes the owner when jobs are pushed
+	idle   atomic.Bool   // Owner is waiting for work
+}
+
+// stealScheduler distributes dispatched jobs over per-worker deques.
+// Workers take from their own deque and, when it is empty, steal half of
+// a randomly chosen victim's jobs. Jobs in deques still hold their queue
+// slot until a worker takes them.
+type stealScheduler struct {
+	pool *Pool
+
+	mu     sync.RWMutex
+	deques map[*Worker]*deque
+	order  []*Worker // For random victim selection
+
+	size atomic.Int64 // Jobs across all deques
+}
+
+// newStealScheduler creates a work-stealing scheduler for p
+func newStealScheduler(p *Pool) *stealScheduler {
+	return &stealScheduler{pool: p, deques: make(map[*Worker]*deque)}
+}
+
+// add gives a worker its own deque
+func (s *stealScheduler) add(w *Worker) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.deques[w] = &deque{signal: make(chan struct{}, 1)}
+	s.order = append(s.order, w)
+}
+
+// remove drops a worker's deque and returns the jobs left in it
+func (s *stealScheduler) remove(w *Worker) []types.Job {
+	s.mu.Lock()
+	d, ok := s.deques[w]
+	if ok {
+		delete(s.deques, w)
+		for i, worker := range s.order {
+			if worker == w {
+				s.order = append(s.order[:i], s.order[i+1:]...)
+				break
+			}
+		}
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		return nil
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	jobs := d.jobs
+	d.jobs = nil
+	s.size.Add(-int64(len(jobs)))
+	return jobs
+}
+
+// hasRoom reports whether the deques can take another job
+func (s *stealScheduler) hasRoom() bool {
+	s.mu.RLock()
+	workers := len(s.order)
+	s.mu.RUnlock()
+	return s.size.Load() < int64(workers*localQueueSize)
+}
+
+// push adds a job to the deque of an idle worker if there is one,
+// otherwise to the shortest deque, and wakes its owner. It reports false
+// if there are no workers.
+func (s *stealScheduler) push(job types.Job) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var target *deque
+	shortest := -1
+	for _, w := range s.order {
+		d := s.deques[w]
+		if d.idle.Load() {
+			target = d
+			break
+		}
+		d.mu.Lock()
+		n := len(d.jobs)
+		d.mu.Unlock()
+		if shortest < 0 || n < shortest {
+			target, shortest = d, n
+		}
+	}
+	if target == nil {
+		return false
+	}
+
+	target.mu.Lock()
+	target.jobs = append(target.jobs, job)
+	s.size.Add(1)
+	target.mu.Unlock()
+	notify(target.signal)
+
+	// A worker that went idle after the target was chosen must not sleep
+	// while the job waits behind a busy owner
+	if !target.idle.Load() {
+		for _, w := range s.order {
+			if d := s.deques[w]; d.idle.Load() {
+				notify(d.signal)
+				break
+			}
+		}
+	}
+	return true
+}
+
+// take pops w's next job, stealing when its own deque is empty
+func (s *stealScheduler) take(w *Worker) (types.Job, bool) {
+	s.mu.RLock()
+	own := s.deques[w]
+	job, ok := s.popFront(own)
+	if !ok {
+		job, ok = s.steal(w, own)
+	}
+	s.mu.RUnlock()
+
+	if ok {
+		// The job leaves the queue: free its slot and let the
+		// dispatcher refill the deques
+		<-s.pool.slots
+		s.pool.wakeDispatcher()
+	}
+	return job, ok
+}
+
+// popFront takes the oldest job from d. Caller holds s.mu.
+func (s *stealScheduler) popFront(d *deque) (types.Job, bool) {
+	if d == nil {
+		return types.Job{}, false
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if len(d.jobs) == 0 {
+		return types.Job{}, false
+	}
+	job := d.jobs[0]
+	d.jobs = d.jobs[1:]
+	s.size.Add(-1)
+	return job, true
+}
+
+// steal moves half of a random victim's jobs into own and returns one of
+// them. Caller holds s.mu.
+func (s *stealScheduler) steal(w *Worker, own *deque) (types.Job, bool) {
+	n := len(s.order)
+	if own == nil || n < 2 {
+		return types.Job{}, false
+	}
+
+	start := rand.Intn(n)
+	for i := 0; i < n; i++ {
+		victim := s.order[(start+i)%n]
+		if victim == w {
+			continue
+		}
+
+		stolen := s.deques[victim].stealHalf()
+		if len(stolen) == 0 {
+			continue
+		}
+		if len(stolen) > 1 {
+			own.mu.Lock()
+			own.jobs = append(own.jobs, stolen[1:]...)
+			own.mu.Unlock()
+		}
+		s.size.Add(-1)
+		return stolen[0], true
+	}
+	return types.Job{}, false
+}
+
+// stealHalf removes the newer half of d's jobs, rounded up
+func (d *deque) stealHalf() []types.Job {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	n := (len(d.jobs) + 1) / 2
+	if n == 0 {
+		return nil
+	}
+	split := len(d.jobs) - n
+	stolen := append([]types.Job(nil), d.jobs[split:]...)
+	d.jobs = d.jobs[:split]
+	return stolen
+}
+
+// wait returns the channel that wakes w
+func (s *stealScheduler) wait(w *Worker) <-chan struct{} {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if d, ok := s.deques[w]; ok {
+		return d.signal
+	}
+	return nil
+}
+
+// idle marks w as waiting for work, or not
+func (s *stealScheduler) idle(w *Worker, idle bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if d, ok := s.deques[w]; ok {
+		d.idle.Store(idle)
+	}
+}
+
+// removeJob takes a job out of whichever deque holds it
+func (s *stealScheduler) removeJob(id string) (types.Job, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, d := range s.deques {
+		d.mu.Lock()
+		for i, job := range d.jobs {
+			if job.ID == id {
+				d.jobs = append(d.jobs[:i], d.jobs[i+1:]...)
+				s.size.Add(-1)
+				d.mu.Unlock()
+				return job, true
+			}
+		}
+		d.mu.Unlock()
+	}
+	return types.Job{}, false
+}
+
+// takeAll empties every deque
+func (s *stealScheduler) takeAll() []types.Job {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var jobs []types.Job
+	for _, d := range s.deques {
+		d.mu.Lock()
+		jobs = append(jobs, d.jobs...)
+		s.size.Add(-int64(len(d.jobs)))
+		d.jobs = nil
+		d.mu.Unlock()
+	}
+	return jobs
+}
+
+// notify wakes the receiver of a one-slot signal channel
+func notify(signal chan struct{}) {
+	select {
+	case signal <- struct{}{}:
+	default:
+	}
+}
+
+// dispatchLocal is the dispatcher for the work-stealing scheduler. It
+// moves jobs from the tenant queues into worker deques while they have
+// room, observing pauses, tenant turns and concurrency caps like dispatch.
+func (p *Pool) dispatchLocal(ctx context.Context) {
+	defer p.wg.Done()
+
+	for {
+		var job *types.Job
+		if p.stealer.hasRoom() {
+			p.queueMu.Lock()
+			if !p.paused {
+				if item := p.nextDispatchableLocked(); item != nil {
+					p.queue.take(item)
+					p.acquireTypeSlotLocked(item.job)
+					job = &item.job
+				}
+			}
+			p.queueMu.Unlock()
+		}
+
+		if job == nil {
+			select {
+			case <-p.notify:
+				continue
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		if !p.stealer.push(*job) {
+			p.requeueLocal([]types.Job{*job})
+		}
+	}
+}
+
+// requeueLocal returns jobs taken from worker deques to their tenant
+// queues. They still hold their queue slots.
+func (p *Pool) requeueLocal(jobs []types.Job) {
+	if len(jobs) == 0 {
+		return
+	}
+
+	for _, job := range jobs {
+		p.releaseTypeSlot(job)
+		p.releaseTenant(job)
+	}
+
+	p.queueMu.Lock()
+	for _, job := range jobs {
+		p.seq++
+		p.queue.push(&queueItem{job: job, seq: p.seq, enqueuedAt: job.EnqueuedAt})
+	}
+	p.queueMu.Unlock()
+	p.wakeDispatcher()
+}
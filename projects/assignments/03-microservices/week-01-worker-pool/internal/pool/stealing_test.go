@@ -0,0 +1,156 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cs-mastery/worker-pool/pkg/types"
+)
+
+func TestWorkStealingRunsEveryJobOnce(t *testing.T) {
+	const jobs = 200
+
+	var mu sync.Mutex
+	runs := make(map[string]int)
+	workers := make(map[int]bool)
+
+	p := NewPool(types.PoolConfig{
+		WorkerCount: 4,
+		QueueSize:   jobs,
+		Scheduler:   types.SchedulerWorkStealing,
+		Handler: func(ctx context.Context, job types.Job) (interface{}, error) {
+			// Uneven job lengths leave some deques empty while others
+			// still hold work
+			if job.Priority%7 == 0 {
+				time.Sleep(time.Millisecond)
+			}
+			mu.Lock()
+			runs[job.ID]++
+			mu.Unlock()
+			return nil, nil
+		},
+	})
+	if err := p.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer p.Stop()
+
+	for i := 0; i < jobs; i++ {
+		submitAll(t, p, types.Job{ID: fmt.Sprint(i), Priority: i % 10})
+	}
+	for i := 0; i < jobs; i++ {
+		result, err := p.GetResult()
+		if err != nil {
+			t.Fatal(err)
+		}
+		workers[result.WorkerID] = true
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(runs) != jobs {
+		t.Errorf("%d distinct jobs ran, want %d", len(runs), jobs)
+	}
+	for id, n := range runs {
+		if n != 1 {
+			t.Errorf("job %s ran %d times", id, n)
+		}
+	}
+	if len(workers) < 2 {
+		t.Errorf("jobs ran on workers %v, want several", workers)
+	}
+	if n := p.stealer.size.Load(); n != 0 {
+		t.Errorf("%d jobs left in deques", n)
+	}
+}
+
+func TestStealSchedulerStealsNewerHalf(t *testing.T) {
+	p := NewPool(types.PoolConfig{QueueSize: 10, Scheduler: types.SchedulerWorkStealing})
+	s := p.stealer
+	busy, thief := &Worker{}, &Worker{}
+	s.add(busy)
+	s.add(thief)
+
+	// Neither worker is idle, so pushes alternate between the deques:
+	// busy holds a, c, e and thief holds b, d
+	for _, id := range []string{"a", "b", "c", "d", "e"} {
+		p.slots <- struct{}{}
+		if !s.push(types.Job{ID: id}) {
+			t.Fatal("push found no worker")
+		}
+	}
+
+	var got []string
+	for i := 0; i < 4; i++ {
+		job, ok := s.take(thief)
+		if !ok {
+			t.Fatalf("take %d found no job", i)
+		}
+		got = append(got, job.ID)
+	}
+	// Thief runs its own jobs, then steals the newer half of busy's,
+	// leaving busy with a
+	want := []string{"b", "d", "c", "e"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("thief took %v, want %v", got, want)
+	}
+	if job, ok := s.take(busy); !ok || job.ID != "a" {
+		t.Errorf("busy took %s %v, want a", job.ID, ok)
+	}
+	if _, ok := s.take(thief); ok {
+		t.Error("took a job from empty deques")
+	}
+	if n := s.size.Load(); n != 0 || len(p.slots) != 0 {
+		t.Errorf("size %d with %d slots held, want 0 and 0", n, len(p.slots))
+	}
+}
+
+func TestWorkStealingCancelJobInDeque(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	p := NewPool(types.PoolConfig{
+		WorkerCount: 1,
+		Scheduler:   types.SchedulerWorkStealing,
+		Handler: func(ctx context.Context, job types.Job) (interface{}, error) {
+			if job.ID == "blocker" {
+				close(started)
+				<-release
+			}
+			return nil, nil
+		},
+	})
+	if err := p.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer p.Stop()
+
+	submitAll(t, p, types.Job{ID: "blocker"})
+	<-started
+	submitAll(t, p, types.Job{ID: "waiting"})
+	for p.stealer.size.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	if err := p.CancelJob("waiting"); err != nil {
+		t.Fatal(err)
+	}
+	result, err := p.GetResult()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.JobID != "waiting" || !errors.Is(result.Error, context.Canceled) {
+		t.Fatalf("result = %s %v, want waiting cancelled", result.JobID, result.Error)
+	}
+	if n := p.stealer.size.Load(); n != 0 {
+		t.Errorf("%d jobs left in deques after cancelling", n)
+	}
+
+	close(release)
+	if result, err = p.GetResult(); err != nil || result.JobID != "blocker" {
+		t.Fatalf("result = %s %v, want blocker", result.JobID, err)
+	}
+}
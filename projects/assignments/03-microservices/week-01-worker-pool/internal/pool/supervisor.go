@@ -0,0 +1,78 @@
+package pool
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+)
+
+// PanicError is the error of a job whose handler panicked
+type PanicError struct {
+	JobID string      // Empty when a worker crashed outside a handler
+	Value interface{} // Value passed to panic
+	Stack []byte      // Stack trace of the panicking goroutine
+}
+
+func (e *PanicError) Error() string {
+	if e.JobID == "" {
+		return fmt.Sprintf("worker panicked: %v", e.Value)
+	}
+	return fmt.Sprintf("job %s panicked: %v", e.JobID, e.Value)
+}
+
+// newPanicError captures a recovered panic value and the current stack.
+// It must be called from the deferred function that recovered.
+func newPanicError(jobID string, value interface{}) *PanicError {
+	return &PanicError{JobID: jobID, Value: value, Stack: debug.Stack()}
+}
+
+// startWorker creates a worker with its own stop signal and runs it under
+// supervise. Caller holds workersMu.
+func (p *Pool) startWorker(ctx context.Context, id int) *Worker {
+	workerCtx, cancel := context.WithCancel(ctx)
+	w := NewWorker(id, p.jobQueue, p, workerCtx, p.runChain, p.metrics)
+	w.cancel = cancel
+	if p.stealer != nil {
+		p.stealer.add(w)
+		w.source = p.stealer
+	}
+
+	p.wg.Add(1)
+	go p.supervise(w)
+	return w
+}
+
+// releaseWorker marks a worker's goroutine as done for the pool's
+// WaitGroup. It is called when the goroutine returns or when the watchdog
+// abandons it, whichever comes first.
+func (p *Pool) releaseWorker(w *Worker) {
+	if w.released.CompareAndSwap(false, true) {
+		p.wg.Done()
+	}
+}
+
+// supervise runs a worker's loop and restarts it whenever a panic escapes
+// job processing, so a crash never shrinks the pool. It returns once the
+// worker stops normally.
+func (p *Pool) supervise(w *Worker) {
+	defer p.releaseWorker(w)
+	if p.stealer != nil {
+		// Jobs left in the worker's deque go back to the shared queue
+		defer func() { p.requeueLocal(p.stealer.remove(w)) }()
+	}
+
+	for {
+		crash := w.run()
+		if crash == nil || w.ctx.Err() != nil || w.IsRetired() {
+			return
+		}
+
+		w.restarted()
+		p.metrics.IncrementWorkerRestarts()
+		slog.Error("worker crashed, restarting",
+			slog.Int("worker_id", w.id),
+			slog.Any("panic", crash),
+			slog.String("stack", string(crash.Stack)))
+	}
+}
@@ -0,0 +1,112 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cs-mastery/worker-pool/pkg/types"
+)
+
+func TestHandlerPanicFailsJob(t *testing.T) {
+	p := startPool(t, types.PoolConfig{
+		WorkerCount: 1,
+		Handler: func(ctx context.Context, job types.Job) (interface{}, error) {
+			if job.ID == "panics" {
+				panic("boom")
+			}
+			return job.ID, nil
+		},
+	})
+
+	submitAll(t, p, types.Job{ID: "panics"})
+	result, err := p.GetResult()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var panicErr *PanicError
+	if !errors.As(result.Error, &panicErr) {
+		t.Fatalf("result error = %v, want a *PanicError", result.Error)
+	}
+	if panicErr.JobID != "panics" || panicErr.Value != "boom" || len(panicErr.Stack) == 0 {
+		t.Errorf("PanicError = job %q, value %v, %d stack bytes", panicErr.JobID, panicErr.Value, len(panicErr.Stack))
+	}
+	record, err := p.GetJobStatus("panics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if record.Status != types.JobFailed {
+		t.Errorf("status = %s, want failed", record.Status)
+	}
+
+	// The panic was contained in the job; the worker carries on
+	submitAll(t, p, types.Job{ID: "after"})
+	if result, err := p.GetResult(); err != nil || result.Data != "after" {
+		t.Errorf("next result = %v, %v; want after", result.Data, err)
+	}
+	p.workersMu.Lock()
+	restarts := p.workers[0].GetRestarts()
+	p.workersMu.Unlock()
+	if restarts != 0 {
+		t.Errorf("worker restarted %d times after a handler panic, want 0", restarts)
+	}
+}
+
+func TestCrashedWorkerIsRestarted(t *testing.T) {
+	// The retry classifier runs on the worker goroutine outside the
+	// handler, so its panic crashes the worker
+	var crash atomic.Bool
+	crash.Store(true)
+	p := startPool(t, types.PoolConfig{
+		WorkerCount:     2,
+		EnableMetrics:   true,
+		MetricsInterval: time.Hour,
+		RetryPolicy: types.RetryPolicy{
+			MaxAttempts: 2,
+			Retryable: func(err error) bool {
+				if crash.CompareAndSwap(true, false) {
+					panic("classifier")
+				}
+				return false
+			},
+		},
+		Handler: func(ctx context.Context, job types.Job) (interface{}, error) {
+			if job.ID == "fails" {
+				return nil, errors.New("boom")
+			}
+			return job.ID, nil
+		},
+	})
+
+	submitAll(t, p, types.Job{ID: "fails"})
+	deadline := time.Now().Add(time.Second)
+	for p.GetMetrics().WorkerRestarts == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("the crashed worker was never restarted")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	restarts := 0
+	p.workersMu.Lock()
+	for _, w := range p.workers {
+		restarts += w.GetRestarts()
+	}
+	p.workersMu.Unlock()
+	if restarts != 1 {
+		t.Errorf("GetRestarts adds up to %d, want 1", restarts)
+	}
+	if n := p.GetWorkerCount(); n != 2 {
+		t.Errorf("worker count = %d after a crash, want 2", n)
+	}
+
+	// Both workers still take jobs
+	submitAll(t, p, types.Job{ID: "a"}, types.Job{ID: "b"}, types.Job{ID: "c"})
+	for i := 0; i < 3; i++ {
+		if result, err := p.GetResult(); err != nil || result.Error != nil {
+			t.Fatalf("result after the crash = %+v, %v", result, err)
+		}
+	}
+}
@@ -0,0 +1,218 @@
+package pool
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/cs-mastery/worker-pool/pkg/types"
+)
+
+// TypedHandler processes a statically typed job payload
+type TypedHandler[In, Out any] func(ctx context.Context, input In) (Out, error)
+
+// TypedJob is a job whose payload is statically typed. The embedded
+// Job carries the metadata (ID, Priority, Timeout, ...); its Data field is
+// replaced by Input on submission.
+type TypedJob[In any] struct {
+	types.Job
+	Input In
+}
+
+// TypedResult is a job result whose output is statically typed
+type TypedResult[Out any] struct {
+	types.JobResult
+	Output Out
+}
+
+// TypedPool is a type-safe view of a Pool whose jobs all take In and
+// produce Out. It is a thin layer over Pool: handlers never need type
+// assertions, and the untyped Pool remains available through Pool().
+type TypedPool[In, Out any] struct {
+	pool    *Pool
+	results chan TypedResult[Out]
+
+	mu         sync.Mutex
+	stopStream context.CancelFunc
+	streamDone chan struct{}
+	held       *types.JobResult // Taken from the pool but not yet delivered
+}
+
+// NewTyped creates a typed pool whose jobs are processed by handler. Any
+// Handler already set in config is replaced.
+func NewTyped[In, Out any](config types.PoolConfig, handler TypedHandler[In, Out]) *TypedPool[In, Out] {
+	config.Handler = func(ctx context.Context, job types.Job) (interface{}, error) {
+		input, ok := job.Data.(In)
+		if !ok {
+			var want In
+			return nil, Permanent(fmt.Errorf("job %s: payload is %T, want %T", job.ID, job.Data, want))
+		}
+		return handler(ctx, input)
+	}
+
+	return &TypedPool[In, Out]{
+		pool:    NewPool(config),
+		results: make(chan TypedResult[Out]),
+	}
+}
+
+// Pool returns the underlying untyped pool
+func (tp *TypedPool[In, Out]) Pool() *Pool {
+	return tp.pool
+}
+
+// Start starts the underlying pool and the typed result stream
+func (tp *TypedPool[In, Out]) Start() error {
+	if err := tp.pool.Start(); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	tp.mu.Lock()
+	tp.stopStream = cancel
+	tp.streamDone = done
+	tp.mu.Unlock()
+
+	go tp.stream(ctx, done)
+	return nil
+}
+
+// Stop stops the typed result stream and the underlying pool
+func (tp *TypedPool[In, Out]) Stop() error {
+	tp.mu.Lock()
+	cancel, done := tp.stopStream, tp.streamDone
+	tp.stopStream, tp.streamDone = nil, nil
+	tp.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+	return tp.pool.Stop()
+}
+
+// Submit submits a typed job whose result is delivered on Results
+func (tp *TypedPool[In, Out]) Submit(ctx context.Context, job TypedJob[In]) error {
+	return tp.pool.SubmitWithContext(ctx, job.untyped())
+}
+
+// SubmitAsync submits a typed job and returns a future for its output
+func (tp *TypedPool[In, Out]) SubmitAsync(ctx context.Context, job TypedJob[In]) (*TypedFuture[Out], error) {
+	future, err := tp.pool.SubmitAsync(ctx, job.untyped())
+	if err != nil {
+		return nil, err
+	}
+	return &TypedFuture[Out]{future: future}, nil
+}
+
+// Results returns the stream of results for jobs submitted with Submit.
+// The channel is shared across restarts and never closed; results not
+// yet received when the pool stops are delivered after the next Start.
+func (tp *TypedPool[In, Out]) Results() <-chan TypedResult[Out] {
+	return tp.results
+}
+
+// stream forwards results from the untyped pool until ctx is cancelled.
+// A result taken from the pool but not yet delivered when ctx is
+// cancelled is handed back rather than dropped.
+func (tp *TypedPool[In, Out]) stream(ctx context.Context, done chan<- struct{}) {
+	defer close(done)
+
+	for {
+		result, ok := tp.takeHeld()
+		if !ok {
+			var err error
+			if result, err = tp.pool.GetResultWithContext(ctx); err != nil {
+				return
+			}
+		}
+
+		select {
+		case tp.results <- typedResult[Out](result):
+		case <-ctx.Done():
+			tp.handBack(result)
+			return
+		}
+	}
+}
+
+// handBack returns an undelivered result to the pool's result queue, or
+// holds it for the next stream if workers have refilled the queue since
+func (tp *TypedPool[In, Out]) handBack(result types.JobResult) {
+	select {
+	case tp.pool.resultQueue <- result:
+	default:
+		tp.mu.Lock()
+		tp.held = &result
+		tp.mu.Unlock()
+	}
+}
+
+// takeHeld returns the result held back by an earlier stream, if any
+func (tp *TypedPool[In, Out]) takeHeld() (types.JobResult, bool) {
+	tp.mu.Lock()
+	defer tp.mu.Unlock()
+
+	if tp.held == nil {
+		return types.JobResult{}, false
+	}
+	result := *tp.held
+	tp.held = nil
+	return result, true
+}
+
+// untyped converts a typed job into the pool's job type
+func (j TypedJob[In]) untyped() types.Job {
+	job := j.Job
+	job.Data = j.Input
+	return job
+}
+
+// typedResult converts a pool result into a typed result. Failed jobs
+// carry the zero Out.
+func typedResult[Out any](result types.JobResult) TypedResult[Out] {
+	typed := TypedResult[Out]{JobResult: result}
+	if output, ok := result.Data.(Out); ok {
+		typed.Output = output
+	}
+	return typed
+}
+
+// TypedFuture is a JobFuture whose output is statically typed
+type TypedFuture[Out any] struct {
+	future *JobFuture
+}
+
+// ID returns the ID of the job behind the future
+func (f *TypedFuture[Out]) ID() string {
+	return f.future.ID()
+}
+
+// Done returns a channel that is closed once the job has a final result
+func (f *TypedFuture[Out]) Done() <-chan struct{} {
+	return f.future.Done()
+}
+
+// Wait blocks until the job finishes or ctx is done and returns the job's
+// output and error
+func (f *TypedFuture[Out]) Wait(ctx context.Context) (Out, error) {
+	result, err := f.future.Wait(ctx)
+	return typedResult[Out](result).Output, err
+}
+
+// Result returns the typed result without blocking. ok is false while the
+// job is still pending.
+func (f *TypedFuture[Out]) Result() (result TypedResult[Out], ok bool) {
+	untyped, ok := f.future.Result()
+	if !ok {
+		return TypedResult[Out]{}, false
+	}
+	return typedResult[Out](untyped), true
+}
+
+// Cancel cancels the job
+func (f *TypedFuture[Out]) Cancel() error {
+	return f.future.Cancel()
+}
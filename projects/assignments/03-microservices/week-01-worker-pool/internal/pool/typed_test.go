@@ -0,0 +1,150 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cs-mastery/worker-pool/pkg/types"
+)
+
+type greeting struct {
+	Name string
+}
+
+// startTyped starts a typed pool that greets by name and stops it when
+// the test ends
+func startTyped(t *testing.T, config types.PoolConfig, calls *atomic.Int32) *TypedPool[greeting, string] {
+	t.Helper()
+	tp := NewTyped(config, func(ctx context.Context, in greeting) (string, error) {
+		calls.Add(1)
+		if in.Name == "" {
+			return "", Permanent(errors.New("name is required"))
+		}
+		return "hello " + in.Name, nil
+	})
+	if err := tp.Start(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { tp.Stop() })
+	return tp
+}
+
+// nextTyped waits a second for the next result on tp's stream
+func nextTyped(t *testing.T, tp *TypedPool[greeting, string]) TypedResult[string] {
+	t.Helper()
+	select {
+	case result := <-tp.Results():
+		return result
+	case <-time.After(time.Second):
+		t.Fatal("no typed result")
+		return TypedResult[string]{}
+	}
+}
+
+func TestTypedRoundTrip(t *testing.T) {
+	var calls atomic.Int32
+	tp := startTyped(t, types.PoolConfig{WorkerCount: 1}, &calls)
+
+	job := TypedJob[greeting]{Job: types.Job{ID: "ada"}, Input: greeting{Name: "ada"}}
+	if err := tp.Submit(context.Background(), job); err != nil {
+		t.Fatal(err)
+	}
+	result := nextTyped(t, tp)
+	if result.JobID != "ada" || result.Error != nil || result.Output != "hello ada" {
+		t.Errorf("result = %s %q %v, want ada %q", result.JobID, result.Output, result.Error, "hello ada")
+	}
+
+	// A failed job carries the zero output
+	if err := tp.Submit(context.Background(), TypedJob[greeting]{Job: types.Job{ID: "nobody"}}); err != nil {
+		t.Fatal(err)
+	}
+	if result := nextTyped(t, tp); result.Error == nil || result.Output != "" {
+		t.Errorf("result = %q %v, want no output and an error", result.Output, result.Error)
+	}
+}
+
+func TestTypedFuture(t *testing.T) {
+	var calls atomic.Int32
+	tp := startTyped(t, types.PoolConfig{WorkerCount: 1}, &calls)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	future, err := tp.SubmitAsync(ctx, TypedJob[greeting]{Job: types.Job{ID: "grace"}, Input: greeting{Name: "grace"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	output, err := future.Wait(ctx)
+	if err != nil || output != "hello grace" {
+		t.Fatalf("Wait = %q, %v; want %q", output, err, "hello grace")
+	}
+	if result, ok := future.Result(); !ok || result.JobID != "grace" || result.Output != "hello grace" {
+		t.Errorf("Result = %+v, %v; want grace's output", result, ok)
+	}
+
+	// The result went to the future, not the stream
+	select {
+	case result := <-tp.Results():
+		t.Errorf("future's result %s was also streamed", result.JobID)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func TestTypedPayloadMismatchIsPermanent(t *testing.T) {
+	var calls atomic.Int32
+	tp := startTyped(t, types.PoolConfig{
+		WorkerCount: 1,
+		RetryPolicy: types.RetryPolicy{MaxAttempts: 3},
+	}, &calls)
+
+	// The untyped pool accepts any payload
+	if err := tp.Pool().Submit(types.Job{ID: "wrong", Data: "ada"}); err != nil {
+		t.Fatal(err)
+	}
+	result := nextTyped(t, tp)
+
+	var permanent *permanentError
+	if !errors.As(result.Error, &permanent) || !strings.Contains(result.Error.Error(), "payload is string") {
+		t.Errorf("error = %v, want a permanent payload mismatch", result.Error)
+	}
+	if result.Attempt != 1 {
+		t.Errorf("Attempt = %d, want 1", result.Attempt)
+	}
+	if n := calls.Load(); n != 0 {
+		t.Errorf("handler called %d times, want 0", n)
+	}
+}
+
+func TestTypedStopKeepsUndeliveredResult(t *testing.T) {
+	var calls atomic.Int32
+	tp := startTyped(t, types.PoolConfig{WorkerCount: 1}, &calls)
+
+	if err := tp.Submit(context.Background(), TypedJob[greeting]{Job: types.Job{ID: "ada"}, Input: greeting{Name: "ada"}}); err != nil {
+		t.Fatal(err)
+	}
+	// Let the stream take the result before anyone reads it
+	deadline := time.Now().Add(time.Second)
+	for {
+		record, err := tp.Pool().GetJobStatus("ada")
+		if err == nil && record.Status == types.JobCompleted && len(tp.pool.resultQueue) == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("result was not taken")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if err := tp.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	if err := tp.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if result := nextTyped(t, tp); result.JobID != "ada" || result.Output != "hello ada" {
+		t.Errorf("result = %s %q, want ada's result after restart", result.JobID, result.Output)
+	}
+}
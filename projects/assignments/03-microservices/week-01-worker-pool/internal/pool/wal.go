@@ -0,0 +1,622 @@
+package pool
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cs-mastery/worker-pool/pkg/types"
+)
+
+// FileBackend errors
+var (
+	ErrBackendClosed = errors.New("queue backend is closed")
+	ErrCorruptLog    = errors.New("queue log is corrupt")
+)
+
+// FsyncPolicy decides when a FileBackend flushes its log to disk
+type FsyncPolicy int
+
+const (
+	FsyncAlways   FsyncPolicy = iota // After every record, before the call returns
+	FsyncInterval                    // Every FsyncInterval; a crash loses at most that much
+	FsyncNever                       // Left to the operating system
+)
+
+// String returns the policy's configuration name
+func (f FsyncPolicy) String() string {
+	switch f {
+	case FsyncAlways:
+		return "always"
+	case FsyncInterval:
+		return "interval"
+	case FsyncNever:
+		return "never"
+	default:
+		return "unknown"
+	}
+}
+
+// FileBackend defaults
+const (
+	defaultSegmentSize   = 64 << 20
+	defaultFsyncInterval = time.Second
+)
+
+// walHeaderSize is the length and CRC-32 that precede every record
+const walHeaderSize = 8
+
+// FileBackendConfig configures a FileBackend
+type FileBackendConfig struct {
+	Dir           string        // Created if missing
+	SegmentSize   int64         // Bytes written before starting a new segment; defaults to 64 MiB
+	Fsync         FsyncPolicy   // Defaults to FsyncAlways
+	FsyncInterval time.Duration // For FsyncInterval; defaults to 1s
+	Codecs        *CodecRegistry
+}
+
+// walRecord is one entry of the log
+type walRecord struct {
+	Op      string          `json:"op"` // "append", "update" or "ack"
+	ID      string          `json:"id"`
+	Job     *walJob         `json:"job,omitempty"`
+	Status  types.JobStatus `json:"status,omitempty"`
+	Attempt int             `json:"attempt,omitempty"`
+	RunAt   time.Time       `json:"run_at,omitempty"`
+}
+
+// walJob is the durable part of a job. Contexts, retry policies and
+// attempt errors are not recorded.
+type walJob struct {
+	Type           string        `json:"type,omitempty"`
+	Tenant         string        `json:"tenant,omitempty"`
+	PartitionKey   string        `json:"partition_key,omitempty"`
+	IdempotencyKey string        `json:"idempotency_key,omitempty"`
+	Data           []byte        `json:"data,omitempty"` // Encoded by the type's codec; nil for nil Data
+	Priority       int           `json:"priority,omitempty"`
+	Timeout        time.Duration `json:"timeout,omitempty"`
+	CreatedAt      time.Time     `json:"created_at"`
+}
+
+// walEntry is the latest recorded state of an unacknowledged job
+type walEntry struct {
+	job     walJob
+	status  types.JobStatus
+	attempt int
+	runAt   time.Time
+	seq     uint64 // Append order
+	size    int64  // Bytes the entry takes when compacted
+}
+
+// FileBackend is a QueueBackend that appends every submission and state
+// change to a write-ahead log split into segment files. Once most of the
+// sealed segments hold acknowledged jobs, the unfinished jobs are
+// rewritten into a new segment and the old ones deleted. Payloads are
+// encoded with the codec registered for the job's type.
+//
+// A torn record at the end of the newest segment, as left by a crash
+// mid-write, is ignored. Damage anywhere else fails NewFileBackend with
+// ErrCorruptLog and leaves the log as it is.
+type FileBackend struct {
+	config FileBackendConfig
+
+	mu          sync.Mutex
+	live        map[string]*walEntry
+	seq         uint64
+	sealed      []string // Segment paths before the active one, oldest first
+	sealedBytes int64
+	liveBytes   int64
+	active      *os.File
+	activeNum   int
+	activeSize  int64
+	dirty       bool // Written since the last fsync
+	closed      bool
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewFileBackend opens the log in config.Dir, reading back the jobs it
+// records, and compacts it into a fresh segment. Nothing is compacted if
+// a segment cannot be read.
+func NewFileBackend(config FileBackendConfig) (*FileBackend, error) {
+	if config.Dir == "" {
+		return nil, fmt.Errorf("file backend: directory is required")
+	}
+	if config.SegmentSize <= 0 {
+		config.SegmentSize = defaultSegmentSize
+	}
+	if config.FsyncInterval <= 0 {
+		config.FsyncInterval = defaultFsyncInterval
+	}
+	if config.Codecs == nil {
+		config.Codecs = NewCodecRegistry()
+	}
+	if err := os.MkdirAll(config.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("file backend: %w", err)
+	}
+
+	b := &FileBackend{
+		config: config,
+		live:   make(map[string]*walEntry),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+
+	paths, last, err := b.segments()
+	if err != nil {
+		return nil, err
+	}
+	for i, path := range paths {
+		size, err := b.load(path, i == len(paths)-1)
+		if err != nil {
+			return nil, err
+		}
+		b.sealed = append(b.sealed, path)
+		b.sealedBytes += size
+	}
+
+	b.mu.Lock()
+	err = b.openSegmentLocked(last + 1)
+	if err == nil {
+		err = b.compactLocked()
+	}
+	b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	if config.Fsync == FsyncInterval {
+		go b.syncLoop()
+	} else {
+		close(b.done)
+	}
+	return b, nil
+}
+
+// segments lists the log's segment files in order and returns the
+// highest segment number
+func (b *FileBackend) segments() ([]string, int, error) {
+	matches, err := filepath.Glob(filepath.Join(b.config.Dir, "wal-*.log"))
+	if err != nil {
+		return nil, 0, fmt.Errorf("file backend: %w", err)
+	}
+
+	nums := make(map[string]int, len(matches))
+	var paths []string
+	last := 0
+	for _, path := range matches {
+		var n int
+		if _, err := fmt.Sscanf(filepath.Base(path), "wal-%d.log", &n); err != nil {
+			continue
+		}
+		nums[path] = n
+		paths = append(paths, path)
+		if n > last {
+			last = n
+		}
+	}
+	sort.Slice(paths, func(i, j int) bool { return nums[paths[i]] < nums[paths[j]] })
+	return paths, last, nil
+}
+
+// load applies the records of one segment and returns its size. Only the
+// last segment may end in a torn record: one cut short, or whose final
+// bytes never reached the disk.
+func (b *FileBackend) load(path string, last bool) (int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("file backend: %w", err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return 0, fmt.Errorf("file backend: %w", err)
+	}
+
+	r := bufio.NewReader(f)
+	var size int64
+	for remaining := info.Size(); remaining > 0; {
+		record, n, err := readRecord(r, remaining)
+		if err != nil {
+			if last && (errors.Is(err, io.ErrUnexpectedEOF) || n == remaining) {
+				return size, nil
+			}
+			return 0, fmt.Errorf("file backend: %w: %s at offset %d: %v", ErrCorruptLog, path, size, err)
+		}
+		size += n
+		remaining -= n
+		b.apply(record, n)
+	}
+	return size, nil
+}
+
+// readRecord reads one framed record from a segment with remaining bytes
+// left and returns it with its size. A record running past the end of
+// the segment fails with io.ErrUnexpectedEOF. The size is also returned
+// for a complete record that fails its checksum or cannot be decoded.
+func readRecord(r io.Reader, remaining int64) (walRecord, int64, error) {
+	if remaining < walHeaderSize {
+		return walRecord{}, 0, io.ErrUnexpectedEOF
+	}
+	var header [walHeaderSize]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return walRecord{}, 0, err
+	}
+	length := binary.LittleEndian.Uint32(header[:4])
+	sum := binary.LittleEndian.Uint32(header[4:])
+
+	size := int64(walHeaderSize) + int64(length)
+	if size > remaining {
+		return walRecord{}, 0, io.ErrUnexpectedEOF
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return walRecord{}, 0, err
+	}
+	if crc32.ChecksumIEEE(payload) != sum {
+		return walRecord{}, size, errors.New("checksum mismatch")
+	}
+
+	var record walRecord
+	if err := json.Unmarshal(payload, &record); err != nil {
+		return walRecord{}, size, err
+	}
+	return record, size, nil
+}
+
+// frame encodes a record with its length and checksum
+func frame(record walRecord) ([]byte, error) {
+	payload, err := json.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+	buf := make([]byte, walHeaderSize+len(payload))
+	binary.LittleEndian.PutUint32(buf[:4], uint32(len(payload)))
+	binary.LittleEndian.PutUint32(buf[4:8], crc32.ChecksumIEEE(payload))
+	copy(buf[walHeaderSize:], payload)
+	return buf, nil
+}
+
+// apply updates the live jobs with one record of size bytes
+func (b *FileBackend) apply(record walRecord, size int64) {
+	switch record.Op {
+	case "append":
+		if record.Job == nil {
+			return
+		}
+		if old, ok := b.live[record.ID]; ok {
+			b.liveBytes -= old.size
+		}
+		b.seq++
+		b.live[record.ID] = &walEntry{
+			job:     *record.Job,
+			status:  record.Status,
+			attempt: record.Attempt,
+			runAt:   record.RunAt,
+			seq:     b.seq,
+			size:    size,
+		}
+		b.liveBytes += size
+	case "update":
+		if entry, ok := b.live[record.ID]; ok {
+			entry.status = record.Status
+			entry.attempt = record.Attempt
+			entry.runAt = record.RunAt
+		}
+	case "ack":
+		if entry, ok := b.live[record.ID]; ok {
+			b.liveBytes -= entry.size
+			delete(b.live, record.ID)
+		}
+	}
+}
+
+// Append records a newly accepted job, encoding its payload with the
+// codec for its type
+func (b *FileBackend) Append(job types.Job, runAt time.Time) error {
+	var data []byte
+	if job.Data != nil {
+		codec, err := b.config.Codecs.Lookup(job.Type)
+		if err != nil {
+			return err
+		}
+		if data, err = codec.Encode(job.Data); err != nil {
+			return fmt.Errorf("encoding payload: %w", err)
+		}
+	}
+
+	return b.write(walRecord{
+		Op: "append",
+		ID: job.ID,
+		Job: &walJob{
+			Type:           job.Type,
+			Tenant:         job.Tenant,
+			PartitionKey:   job.PartitionKey,
+			IdempotencyKey: job.IdempotencyKey,
+			Data:           data,
+			Priority:       job.Priority,
+			Timeout:        job.Timeout,
+			CreatedAt:      job.CreatedAt,
+		},
+		Status:  types.JobPending,
+		Attempt: job.Attempt,
+		RunAt:   runAt,
+	})
+}
+
+// Update records a state change of an appended job
+func (b *FileBackend) Update(id string, status types.JobStatus, attempt int, runAt time.Time) error {
+	return b.write(walRecord{Op: "update", ID: id, Status: status, Attempt: attempt, RunAt: runAt})
+}
+
+// Ack records that a job has finished for good
+func (b *FileBackend) Ack(id string) error {
+	return b.write(walRecord{Op: "ack", ID: id})
+}
+
+// write appends a record to the active segment, starting a new segment
+// first if the active one is full. Updates and acks of jobs that are not
+// live are skipped.
+func (b *FileBackend) write(record walRecord) error {
+	buf, err := frame(record)
+	if err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return ErrBackendClosed
+	}
+	if _, ok := b.live[record.ID]; !ok && record.Op != "append" {
+		return nil
+	}
+
+	if b.activeSize > 0 && b.activeSize+int64(len(buf)) > b.config.SegmentSize {
+		if err := b.rotateLocked(); err != nil {
+			return err
+		}
+	}
+	if err := b.writeLocked(buf); err != nil {
+		return err
+	}
+	if b.config.Fsync == FsyncAlways {
+		if err := b.active.Sync(); err != nil {
+			return fmt.Errorf("file backend: %w", err)
+		}
+	}
+	b.apply(record, int64(len(buf)))
+	return nil
+}
+
+// writeLocked writes framed records to the active segment. A failed write
+// is truncated away so later records stay readable. Caller holds b.mu.
+func (b *FileBackend) writeLocked(buf []byte) error {
+	if _, err := b.active.Write(buf); err != nil {
+		b.active.Truncate(b.activeSize)
+		b.active.Seek(b.activeSize, io.SeekStart)
+		return fmt.Errorf("file backend: %w", err)
+	}
+	b.activeSize += int64(len(buf))
+	b.dirty = true
+	return nil
+}
+
+// rotateLocked seals the active segment and starts the next one,
+// compacting once acknowledged jobs make up most of the sealed segments.
+// Caller holds b.mu.
+func (b *FileBackend) rotateLocked() error {
+	if err := b.active.Sync(); err != nil {
+		return fmt.Errorf("file backend: %w", err)
+	}
+	if err := b.active.Close(); err != nil {
+		return fmt.Errorf("file backend: %w", err)
+	}
+	b.sealed = append(b.sealed, b.active.Name())
+	b.sealedBytes += b.activeSize
+
+	if err := b.openSegmentLocked(b.activeNum + 1); err != nil {
+		return err
+	}
+	if b.sealedBytes >= 2*b.liveBytes {
+		return b.compactLocked()
+	}
+	return nil
+}
+
+// openSegmentLocked creates segment n and makes it the active one.
+// Caller holds b.mu.
+func (b *FileBackend) openSegmentLocked(n int) error {
+	path := filepath.Join(b.config.Dir, fmt.Sprintf("wal-%08d.log", n))
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("file backend: %w", err)
+	}
+	b.active, b.activeNum, b.activeSize, b.dirty = f, n, 0, false
+	return nil
+}
+
+// compactLocked writes the live jobs into the active segment, which must
+// be empty, and deletes the sealed segments. Caller holds b.mu.
+func (b *FileBackend) compactLocked() error {
+	entries := make([]*walEntry, 0, len(b.live))
+	ids := make(map[*walEntry]string, len(b.live))
+	for id, entry := range b.live {
+		entries = append(entries, entry)
+		ids[entry] = id
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].seq < entries[j].seq })
+
+	var liveBytes int64
+	for _, entry := range entries {
+		job := entry.job
+		buf, err := frame(walRecord{
+			Op:      "append",
+			ID:      ids[entry],
+			Job:     &job,
+			Status:  entry.status,
+			Attempt: entry.attempt,
+			RunAt:   entry.runAt,
+		})
+		if err != nil {
+			return err
+		}
+		if err := b.writeLocked(buf); err != nil {
+			return err
+		}
+		entry.size = int64(len(buf))
+		liveBytes += entry.size
+	}
+	if err := b.active.Sync(); err != nil {
+		return fmt.Errorf("file backend: %w", err)
+	}
+	b.dirty = false
+	b.liveBytes = liveBytes
+
+	for _, path := range b.sealed {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("file backend: %w", err)
+		}
+	}
+	b.sealed, b.sealedBytes = nil, 0
+	return syncDir(b.config.Dir)
+}
+
+// Compact rewrites the unfinished jobs into a new segment and deletes the
+// rest of the log
+func (b *FileBackend) Compact() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return ErrBackendClosed
+	}
+	if err := b.rotateLocked(); err != nil {
+		return err
+	}
+	if len(b.sealed) > 0 {
+		return b.compactLocked()
+	}
+	return nil
+}
+
+// Replay returns the unacknowledged jobs in the order they were appended,
+// decoding their payloads with the registered codecs. A job whose payload
+// cannot be decoded is returned with Err set and no Data.
+func (b *FileBackend) Replay() ([]types.QueueEntry, error) {
+	b.mu.Lock()
+	type liveJob struct {
+		id    string
+		entry walEntry
+	}
+	jobs := make([]liveJob, 0, len(b.live))
+	for id, entry := range b.live {
+		jobs = append(jobs, liveJob{id: id, entry: *entry})
+	}
+	b.mu.Unlock()
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].entry.seq < jobs[j].entry.seq })
+
+	entries := make([]types.QueueEntry, 0, len(jobs))
+	for _, lj := range jobs {
+		stored := lj.entry.job
+		job := types.Job{
+			ID:             lj.id,
+			Type:           stored.Type,
+			Tenant:         stored.Tenant,
+			PartitionKey:   stored.PartitionKey,
+			IdempotencyKey: stored.IdempotencyKey,
+			Priority:       stored.Priority,
+			Timeout:        stored.Timeout,
+			CreatedAt:      stored.CreatedAt,
+			Attempt:        lj.entry.attempt,
+		}
+		entry := types.QueueEntry{Job: job, Status: lj.entry.status, RunAt: lj.entry.runAt}
+		if stored.Data != nil {
+			entry.Job.Data, entry.Err = decodePayload(b.config.Codecs, stored.Type, stored.Data)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// decodePayload decodes a recorded payload with the codec for its job
+// type
+func decodePayload(codecs *CodecRegistry, jobType string, raw []byte) (interface{}, error) {
+	codec, err := codecs.Lookup(jobType)
+	if err != nil {
+		return nil, err
+	}
+	data, err := codec.Decode(raw)
+	if err != nil {
+		return nil, fmt.Errorf("decoding payload: %w", err)
+	}
+	return data, nil
+}
+
+// syncLoop flushes the log every FsyncInterval until Close
+func (b *FileBackend) syncLoop() {
+	defer close(b.done)
+
+	ticker := time.NewTicker(b.config.FsyncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			b.mu.Lock()
+			if b.dirty && !b.closed {
+				if b.active.Sync() == nil {
+					b.dirty = false
+				}
+			}
+			b.mu.Unlock()
+		case <-b.stop:
+			return
+		}
+	}
+}
+
+// Close flushes and closes the log. Later calls fail with
+// ErrBackendClosed.
+func (b *FileBackend) Close() error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return ErrBackendClosed
+	}
+	b.closed = true
+	close(b.stop)
+	err := b.active.Sync()
+	if closeErr := b.active.Close(); err == nil {
+		err = closeErr
+	}
+	b.mu.Unlock()
+
+	<-b.done
+	return err
+}
+
+// syncDir flushes a directory so created and deleted files survive a
+// crash
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return fmt.Errorf("file backend: %w", err)
+	}
+	defer d.Close()
+	if err := d.Sync(); err != nil {
+		return fmt.Errorf("file backend: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,328 @@
+package pool
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cs-mastery/worker-pool/pkg/types"
+)
+
+// appendRecord is the framed append record of a job without payload
+func appendRecord(t *testing.T, id string) []byte {
+	t.Helper()
+	buf, err := frame(walRecord{Op: "append", ID: id, Job: &walJob{}, Attempt: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buf
+}
+
+// ackRecord is the framed ack record of a job
+func ackRecord(t *testing.T, id string) []byte {
+	t.Helper()
+	buf, err := frame(walRecord{Op: "ack", ID: id})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buf
+}
+
+// corrupt returns a copy of a framed record with its payload damaged
+func corrupt(buf []byte) []byte {
+	bad := append([]byte(nil), buf...)
+	bad[len(bad)-2] ^= 0xff
+	return bad
+}
+
+// replayIDs returns the IDs of the jobs a backend replays, in order
+func replayIDs(t *testing.T, b types.QueueBackend) []string {
+	t.Helper()
+	entries, err := b.Replay()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, entry := range entries {
+		ids = append(ids, entry.Job.ID)
+	}
+	return ids
+}
+
+// segmentFiles returns the contents of the log's segments by file name
+func segmentFiles(t *testing.T, dir string) map[string][]byte {
+	t.Helper()
+	paths, err := filepath.Glob(filepath.Join(dir, "wal-*.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := make(map[string][]byte, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[filepath.Base(path)] = data
+	}
+	return files
+}
+
+func TestFileBackendLoad(t *testing.T) {
+	a, b, c := appendRecord(t, "a"), appendRecord(t, "b"), appendRecord(t, "c")
+	huge := make([]byte, walHeaderSize)
+	huge[3] = 0x7f // A length far past the end of the segment
+
+	tests := []struct {
+		name     string
+		segments [][][]byte // Records of each segment, oldest segment first
+		want     []string
+		corrupt  bool
+	}{
+		{
+			name:     "clean",
+			segments: [][][]byte{{a, b}, {c}},
+			want:     []string{"a", "b", "c"},
+		},
+		{
+			name:     "acks across segments",
+			segments: [][][]byte{{a, b}, {ackRecord(t, "a"), c}},
+			want:     []string{"b", "c"},
+		},
+		{
+			name:     "torn record in last segment",
+			segments: [][][]byte{{a}, {b, c[:len(c)-5]}},
+			want:     []string{"a", "b"},
+		},
+		{
+			name:     "torn header in last segment",
+			segments: [][][]byte{{a}, {b, c[:3]}},
+			want:     []string{"a", "b"},
+		},
+		{
+			name:     "length past the end of last segment",
+			segments: [][][]byte{{a}, {b, huge}},
+			want:     []string{"a", "b"},
+		},
+		{
+			name:     "bad checksum on last record",
+			segments: [][][]byte{{a}, {b, corrupt(c)}},
+			want:     []string{"a", "b"},
+		},
+		{
+			name:     "torn record in earlier segment",
+			segments: [][][]byte{{a, b[:len(b)-5]}, {c}},
+			corrupt:  true,
+		},
+		{
+			name:     "bad checksum before the end of last segment",
+			segments: [][][]byte{{a}, {corrupt(b), c}},
+			corrupt:  true,
+		},
+		{
+			name:     "corrupt middle segment",
+			segments: [][][]byte{{a}, {corrupt(b)}, {c}},
+			corrupt:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for i, records := range tt.segments {
+				path := filepath.Join(dir, fmt.Sprintf("wal-%08d.log", i+1))
+				if err := os.WriteFile(path, bytes.Join(records, nil), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			before := segmentFiles(t, dir)
+
+			backend, err := NewFileBackend(FileBackendConfig{Dir: dir})
+			if tt.corrupt {
+				if !errors.Is(err, ErrCorruptLog) {
+					t.Fatalf("NewFileBackend = %v, want ErrCorruptLog", err)
+				}
+				if after := segmentFiles(t, dir); !reflect.DeepEqual(after, before) {
+					t.Error("the log was changed after failing to load")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer backend.Close()
+			if got := replayIDs(t, backend); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("replayed %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadRecordFraming(t *testing.T) {
+	buf, err := frame(walRecord{Op: "update", ID: "job", Status: types.JobProcessing, Attempt: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	record, n, err := readRecord(bytes.NewReader(buf), int64(len(buf)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != int64(len(buf)) || record.Op != "update" || record.ID != "job" || record.Attempt != 3 {
+		t.Errorf("readRecord = %+v, %d bytes", record, n)
+	}
+
+	// Every payload byte is covered by the checksum
+	for i := walHeaderSize; i < len(buf); i++ {
+		bad := append([]byte(nil), buf...)
+		bad[i] ^= 0x01
+		if _, n, err := readRecord(bytes.NewReader(bad), int64(len(bad))); err == nil || n != int64(len(buf)) {
+			t.Fatalf("flipping byte %d: readRecord = %d bytes, %v; want a checksum error", i, n, err)
+		}
+	}
+	for _, cut := range []int{0, walHeaderSize - 1, len(buf) - 1} {
+		if _, _, err := readRecord(bytes.NewReader(buf[:cut]), int64(cut)); !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Errorf("record cut to %d bytes: %v, want io.ErrUnexpectedEOF", cut, err)
+		}
+	}
+}
+
+func TestFileBackendRestart(t *testing.T) {
+	dir := t.TempDir()
+	b := openFileBackend(t, dir)
+
+	due := time.Now().Add(time.Hour).Round(0)
+	for _, job := range []types.Job{{ID: "later"}, {ID: "running"}, {ID: "done"}} {
+		runAt := time.Time{}
+		if job.ID == "later" {
+			runAt = due
+		}
+		if err := b.Append(job, runAt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	b.Update("running", types.JobProcessing, 2, time.Time{})
+	b.Ack("done")
+	b.Update("unknown", types.JobProcessing, 1, time.Time{})
+	if err := b.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Ack("later"); !errors.Is(err, ErrBackendClosed) {
+		t.Errorf("Ack after Close = %v, want ErrBackendClosed", err)
+	}
+
+	b = openFileBackend(t, dir)
+	defer b.Close()
+	entries, err := b.Replay()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("replayed %d jobs, want later and running", len(entries))
+	}
+	if e := entries[0]; e.Job.ID != "later" || !e.RunAt.Equal(due) || e.Status != types.JobPending {
+		t.Errorf("first entry = %s %s due %v, want later pending due %v", e.Job.ID, e.Status, e.RunAt, due)
+	}
+	if e := entries[1]; e.Job.ID != "running" || e.Status != types.JobProcessing || e.Job.Attempt != 2 {
+		t.Errorf("second entry = %s %s attempt %d, want running processing attempt 2", e.Job.ID, e.Status, e.Job.Attempt)
+	}
+}
+
+func TestFileBackendRolloverAndCompaction(t *testing.T) {
+	dir := t.TempDir()
+	b, err := NewFileBackend(FileBackendConfig{Dir: dir, SegmentSize: 512})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Keep every seventh job; the rest finish as they go
+	var kept []string
+	for i := 0; i < 100; i++ {
+		id := fmt.Sprintf("job-%02d", i)
+		if err := b.Append(types.Job{ID: id}, time.Time{}); err != nil {
+			t.Fatal(err)
+		}
+		if i%7 == 0 {
+			kept = append(kept, id)
+			continue
+		}
+		if err := b.Ack(id); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Without compaction the log would hold dozens of segments
+	if n := len(segmentFiles(t, dir)); n > 5 {
+		t.Errorf("%d segments after acknowledging most jobs, want compaction to keep a few", n)
+	}
+	if got := replayIDs(t, b); !reflect.DeepEqual(got, kept) {
+		t.Errorf("replayed %v, want %v", got, kept)
+	}
+
+	if err := b.Compact(); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(segmentFiles(t, dir)); n != 1 {
+		t.Errorf("%d segments after Compact, want 1", n)
+	}
+	if err := b.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	b = openFileBackend(t, dir)
+	defer b.Close()
+	if got := replayIDs(t, b); !reflect.DeepEqual(got, kept) {
+		t.Errorf("replayed %v after reopening, want %v", got, kept)
+	}
+	if n := len(segmentFiles(t, dir)); n != 1 {
+		t.Errorf("%d segments after reopening, want 1", n)
+	}
+}
+
+func TestFileBackendFsyncPolicies(t *testing.T) {
+	for _, policy := range []FsyncPolicy{FsyncAlways, FsyncInterval, FsyncNever} {
+		t.Run(policy.String(), func(t *testing.T) {
+			dir := t.TempDir()
+			config := FileBackendConfig{Dir: dir, Fsync: policy, FsyncInterval: 5 * time.Millisecond}
+			b, err := NewFileBackend(config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := b.Append(types.Job{ID: "a"}, time.Time{}); err != nil {
+				t.Fatal(err)
+			}
+
+			if policy == FsyncInterval {
+				deadline := time.Now().Add(time.Second)
+				for {
+					b.mu.Lock()
+					dirty := b.dirty
+					b.mu.Unlock()
+					if !dirty {
+						break
+					}
+					if time.Now().After(deadline) {
+						t.Fatal("the log was never flushed")
+					}
+					time.Sleep(time.Millisecond)
+				}
+			}
+			if err := b.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			b, err = NewFileBackend(config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer b.Close()
+			if got := replayIDs(t, b); !reflect.DeepEqual(got, []string{"a"}) {
+				t.Errorf("replayed %v, want [a]", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/cs-mastery/worker-pool/pkg/types"
+)
+
+// ErrJobAbandoned is the error of a job the watchdog abandoned because it
+// kept running past its timeout and grace period. It wraps
+// context.DeadlineExceeded, so the job is reported as timed out.
+var ErrJobAbandoned = errors.New("job abandoned by watchdog")
+
+// Reasons a job is reported as stuck
+const (
+	StuckPastTimeout     = "past_timeout"     // Still running after its timeout fired
+	StuckMissedHeartbeat = "missed_heartbeat" // No heartbeat within HeartbeatTimeout
+)
+
+// attempt is one in-flight run of a job, shared by the worker running it,
+// the watchdog, the lease reaper, Heartbeat and ExtendLease
+type attempt struct {
+	job        types.Job
+	worker     *Worker
+	startedAt  time.Time
+	cancel     context.CancelFunc // Cancels the attempt's job context
+	lastBeat   atomic.Int64       // Unix nanos of the last heartbeat, zero if none
+	leaseUntil atomic.Int64       // Unix nanos the lease expires at, zero without leases
+	claimed    atomic.Bool        // Set by whichever of worker, watchdog or lease reaper reports the result
+}
+
+// claim reports whether the caller gets to report the attempt's result.
+// Only the first caller does.
+func (a *attempt) claim() bool {
+	return a.claimed.CompareAndSwap(false, true)
+}
+
+// stuckReason returns why the attempt counts as stuck at now, or "" if it
+// is not
+func (a *attempt) stuckReason(now time.Time, heartbeatTimeout time.Duration) string {
+	if a.job.Timeout > 0 && now.Sub(a.startedAt) > a.job.Timeout {
+		return StuckPastTimeout
+	}
+	if beat := a.lastBeat.Load(); heartbeatTimeout > 0 && beat != 0 &&
+		now.Sub(time.Unix(0, beat)) > heartbeatTimeout {
+		return StuckMissedHeartbeat
+	}
+	return ""
+}
+
+// attemptKey is the context key under which a job's attempt is stored
+type attemptKey struct{}
+
+// Heartbeat records that the job running under ctx is still making
+// progress. Handlers doing long work should call it periodically; it
+// returns ctx.Err() so loops can stop once the job is cancelled.
+func Heartbeat(ctx context.Context) error {
+	if a, ok := ctx.Value(attemptKey{}).(*attempt); ok {
+		a.lastBeat.Store(time.Now().UnixNano())
+	}
+	return ctx.Err()
+}
+
+// StuckJobs returns the running jobs that are past their timeout or have
+// missed a heartbeat, longest running first
+func (p *Pool) StuckJobs() []types.StuckJob {
+	now := time.Now()
+
+	p.inflightMu.Lock()
+	defer p.inflightMu.Unlock()
+
+	var stuck []types.StuckJob
+	for _, a := range p.inflight {
+		reason := a.stuckReason(now, p.config.HeartbeatTimeout)
+		if reason == "" {
+			continue
+		}
+
+		job := types.StuckJob{
+			JobID:     a.job.ID,
+			WorkerID:  a.worker.id,
+			Attempt:   a.job.Attempt,
+			StartedAt: a.startedAt,
+			Elapsed:   now.Sub(a.startedAt),
+			Timeout:   a.job.Timeout,
+			Reason:    reason,
+		}
+		if beat := a.lastBeat.Load(); beat != 0 {
+			job.LastHeartbeat = time.Unix(0, beat)
+		}
+		stuck = append(stuck, job)
+	}
+
+	sort.Slice(stuck, func(i, j int) bool {
+		return stuck[i].Elapsed > stuck[j].Elapsed
+	})
+	return stuck
+}
+
+// watchdogInterval returns how often the watchdog checks running jobs
+func watchdogInterval(grace time.Duration) time.Duration {
+	interval := grace / 4
+	if interval < 10*time.Millisecond {
+		interval = 10 * time.Millisecond
+	}
+	if interval > time.Second {
+		interval = time.Second
+	}
+	return interval
+}
+
+// watchdog abandons jobs that keep running for longer than their timeout
+// plus StuckJobGrace, reporting them as timed out and replacing the
+// worker stuck running them
+func (p *Pool) watchdog(ctx context.Context) {
+	defer p.wg.Done()
+
+	if p.config.StuckJobGrace <= 0 {
+		<-ctx.Done()
+		return
+	}
+
+	ticker := time.NewTicker(watchdogInterval(p.config.StuckJobGrace))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case now := <-ticker.C:
+			for _, a := range p.overdue(now) {
+				p.abandon(ctx, a)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// overdue returns the running attempts past their timeout plus grace
+func (p *Pool) overdue(now time.Time) []*attempt {
+	p.inflightMu.Lock()
+	defer p.inflightMu.Unlock()
+
+	var overdue []*attempt
+	for _, a := range p.inflight {
+		if a.job.Timeout > 0 && now.Sub(a.startedAt) > a.job.Timeout+p.config.StuckJobGrace {
+			overdue = append(overdue, a)
+		}
+	}
+	return overdue
+}
+
+// abandon reports a stuck attempt as timed out and replaces its worker.
+// The stuck goroutine is left to finish on its own; its result is
+// discarded.
+func (p *Pool) abandon(ctx context.Context, a *attempt) {
+	if !a.claim() {
+		// The worker finished the job after all
+		return
+	}
+
+	w := a.worker
+	w.Stop()
+	p.releaseWorker(w)
+	p.replaceWorker(ctx, w)
+	p.metrics.IncrementJobsAbandoned()
+
+	now := time.Now()
+	p.complete(ctx, a.job, types.JobResult{
+		JobID:      a.job.ID,
+		Error:      fmt.Errorf("%w after %v: %w", ErrJobAbandoned, now.Sub(a.startedAt).Round(time.Millisecond), context.DeadlineExceeded),
+		WorkerID:   w.id,
+		StartTime:  a.startedAt,
+		EndTime:    now,
+		Duration:   now.Sub(a.startedAt),
+		Attempt:    a.job.Attempt,
+		Deliveries: a.job.Deliveries,
+	})
+}
+
+// replaceWorker swaps a fresh worker in for w, unless the pool is
+// stopping
+func (p *Pool) replaceWorker(ctx context.Context, w *Worker) {
+	p.workersMu.Lock()
+	defer p.workersMu.Unlock()
+
+	if ctx.Err() != nil {
+		return
+	}
+	for i, worker := range p.workers {
+		if worker == w {
+			p.workers[i] = p.startWorker(ctx, w.id)
+			return
+		}
+	}
+}
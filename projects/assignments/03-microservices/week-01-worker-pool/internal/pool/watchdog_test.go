@@ -0,0 +1,103 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cs-mastery/worker-pool/pkg/types"
+)
+
+func TestWatchdogAbandonsStuckJob(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	p := NewPool(types.PoolConfig{
+		WorkerCount:   1,
+		StuckJobGrace: 20 * time.Millisecond,
+		Handler: func(ctx context.Context, job types.Job) (interface{}, error) {
+			if job.ID == "stuck" {
+				// Ignores its context, as a wedged handler would
+				<-release
+			}
+			return job.ID, nil
+		},
+	})
+	if err := p.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer p.Stop()
+
+	if err := p.Submit(types.Job{ID: "stuck", Timeout: 20 * time.Millisecond}); err != nil {
+		t.Fatal(err)
+	}
+	result, err := p.GetResult()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !errors.Is(result.Error, ErrJobAbandoned) || !errors.Is(result.Error, context.DeadlineExceeded) {
+		t.Fatalf("result error = %v, want an abandoned timeout", result.Error)
+	}
+
+	// The only worker is still stuck, so this job runs on its replacement
+	if err := p.Submit(types.Job{ID: "next"}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case result := <-p.resultQueue:
+		if result.JobID != "next" || result.Error != nil {
+			t.Fatalf("result = %s %v, want next succeeding", result.JobID, result.Error)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("stuck worker was not replaced")
+	}
+	if n := p.GetWorkerCount(); n != 1 {
+		t.Errorf("worker count = %d, want 1", n)
+	}
+}
+
+func TestStuckJobsReportsMissedHeartbeat(t *testing.T) {
+	beat := make(chan struct{})
+	release := make(chan struct{})
+
+	p := NewPool(types.PoolConfig{
+		WorkerCount:      1,
+		HeartbeatTimeout: 20 * time.Millisecond,
+		Handler: func(ctx context.Context, job types.Job) (interface{}, error) {
+			Heartbeat(ctx)
+			close(beat)
+			<-release
+			return nil, nil
+		},
+	})
+	if err := p.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer p.Stop()
+
+	if err := p.Submit(types.Job{ID: "quiet"}); err != nil {
+		t.Fatal(err)
+	}
+	<-beat
+	if stuck := p.StuckJobs(); len(stuck) != 0 {
+		t.Errorf("stuck jobs right after a heartbeat = %v, want none", stuck)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	stuck := p.StuckJobs()
+	if len(stuck) != 1 || stuck[0].JobID != "quiet" || stuck[0].Reason != StuckMissedHeartbeat {
+		t.Fatalf("stuck jobs = %+v, want quiet with a missed heartbeat", stuck)
+	}
+	if stuck[0].LastHeartbeat.IsZero() {
+		t.Error("LastHeartbeat is zero")
+	}
+
+	close(release)
+	if _, err := p.GetResult(); err != nil {
+		t.Fatal(err)
+	}
+	if stuck := p.StuckJobs(); len(stuck) != 0 {
+		t.Errorf("stuck jobs after finishing = %v, want none", stuck)
+	}
+}
@@ -2,7 +2,9 @@ package pool
 
 import (
 	"context"
+	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/cs-mastery/worker-pool/pkg/types"
@@ -10,176 +12,252 @@ import (
 
 // Worker represents an individual worker in the pool
 type Worker struct {
-	// TODO: Add worker fields
-	// Hints:
-	// - id: unique worker identifier
-	// - jobQueue: channel to receive jobs from
-	// - resultQueue: channel to send results to
-	// - ctx: context for cancellation
-	// - status: current worker status
-	// - jobsProcessed: counter for processed jobs
-	// - lastJobTime: timestamp of last job processing
-	// - startTime: when worker was started
-	// - mu: mutex for thread safety
-
-	// Example implementation:
-	// id            int
-	// jobQueue      <-chan types.Job
-	// resultQueue   chan<- types.JobResult
-	// ctx           context.Context
-	// status        types.WorkerStatus
-	// jobsProcessed int64
-	// lastJobTime   time.Time
-	// startTime     time.Time
-	// mu            sync.RWMutex
+	id            int
+	jobQueue      <-chan types.Job
+	source        jobSource // Replaces jobQueue when set
+	observer      jobObserver
+	ctx           context.Context
+	cancel        context.CancelFunc // Stops the worker; nil if not owned by a pool
+	quit          chan struct{}      // Closed to retire the worker after its current job
+	retireOnce    sync.Once
+	released      atomic.Bool // Set once the pool stops waiting for the worker
+	handler       types.JobHandler
+	metrics       *Metrics
+	status        types.WorkerStatus
+	jobsProcessed int64
+	restarts      int
+	lastJobTime   time.Time
+	startTime     time.Time
+	mu            sync.RWMutex
 }
 
-// NewWorker creates a new worker with the given parameters
-func NewWorker(id int, jobQueue <-chan types.Job, resultQueue chan<- types.JobResult, ctx context.Context) *Worker {
-	// TODO: Implement worker creation
-	// Hints:
-	// - Initialize all worker fields
-	// - Set status to idle initially
-	// - Record start time
-	// - Return pointer to new worker
+// jobObserver is told when a worker starts and finishes each attempt. The
+// pool implements it to track job status and running attempts, and to
+// decide whether a finished attempt is retried, dead-lettered or
+// published.
+type jobObserver interface {
+	started(a *attempt)
+	complete(ctx context.Context, job types.Job, result types.JobResult)
+}
 
+// NewWorker creates a new worker with the given parameters. A nil handler
+// makes the worker simulate work; a nil metrics collector disables
+// per-job metrics. middlewares wrap the handler, first outermost (see
+// Chain).
+func NewWorker(id int, jobQueue <-chan types.Job, observer jobObserver, ctx context.Context, handler types.JobHandler, metrics *Metrics, middlewares ...Middleware) *Worker {
+	if handler == nil {
+		handler = simulateWork
+	}
 	return &Worker{
-		// TODO: Initialize fields
-		// Example:
-		// id:          id,
-		// jobQueue:    jobQueue,
-		// resultQueue: resultQueue,
-		// ctx:         ctx,
-		// status:      types.WorkerIdle,
-		// startTime:   time.Now(),
+		id:        id,
+		jobQueue:  jobQueue,
+		observer:  observer,
+		ctx:       ctx,
+		handler:   Chain(handler, middlewares...),
+		metrics:   metrics,
+		quit:      make(chan struct{}),
+		status:    types.WorkerIdle,
+		startTime: time.Now(),
 	}
 }
 
-// Start begins the worker's job processing loop
+// Start begins the worker's job processing loop. A panic that escapes
+// job processing stops the worker; the pool runs its workers under a
+// supervisor that restarts them instead.
 func (w *Worker) Start(wg *sync.WaitGroup) {
-	// TODO: Implement worker start logic
-	// Hints:
-	// - Defer wg.Done() to signal completion
-	// - Run main processing loop
-	// - Handle graceful shutdown on context cancellation
-	// - Update worker status appropriately
-
 	defer wg.Done()
+	w.run()
+}
+
+// run processes jobs until the worker's context ends, it is retired or
+// the job queue closes. A panic that escapes job processing is recovered
+// and returned.
+func (w *Worker) run() (crash *PanicError) {
+	defer w.setStatus(types.WorkerStopped)
+	defer func() {
+		if r := recover(); r != nil {
+			crash = newPanicError("", r)
+		}
+	}()
+
+	for {
+		// A stopped or retired worker must not pick up another job
+		if w.ctx.Err() != nil || w.IsRetired() {
+			return nil
+		}
+
+		if w.source != nil {
+			if !w.takeFromSource() {
+				return nil
+			}
+			continue
+		}
 
-	// TODO: Add implementation
-	// Example structure:
-	// for {
-	//     select {
-	//     case job, ok := <-w.jobQueue:
-	//         if !ok {
-	//             // Job queue closed, exit
-	//             return
-	//         }
-	//         w.processJob(job)
-	//     case <-w.ctx.Done():
-	//         // Context cancelled, graceful shutdown
-	//         return
-	//     }
-	// }
+		select {
+		case job, ok := <-w.jobQueue:
+			if !ok {
+				// Job queue closed, exit
+				return
+			}
+			w.processJob(job)
+		case <-w.ctx.Done():
+			// Context cancelled, graceful shutdown
+			return
+		case <-w.quit:
+			// Retired by the pool
+			return
+		}
+	}
+}
+
+// takeFromSource runs the next job from the worker's source, waiting for
+// one if there is none. It returns false when the worker should exit.
+func (w *Worker) takeFromSource() bool {
+	job, ok := w.source.take(w)
+	if !ok {
+		// Mark idle before looking again so a job pushed in between
+		// either is found or wakes this worker
+		w.source.idle(w, true)
+		job, ok = w.source.take(w)
+		if !ok {
+			select {
+			case <-w.source.wait(w):
+			case <-w.ctx.Done():
+				return false
+			case <-w.quit:
+				return false
+			}
+		}
+		w.source.idle(w, false)
+	}
+
+	if ok {
+		w.processJob(job)
+	}
+	return true
 }
 
 // processJob handles the processing of a single job
 func (w *Worker) processJob(job types.Job) {
-	// TODO: Implement job processing logic
-	// Hints:
-	// - Update worker status to busy
-	// - Record job start time
-	// - Set up job timeout if specified
-	// - Call the actual job processing function
-	// - Handle job cancellation via context
-	// - Record processing time and update metrics
-	// - Send result to result queue
-	// - Update worker status back to idle
-
-	// TODO: Add implementation
-	// Example structure:
-	// w.setStatus(types.WorkerBusy)
-	// defer w.setStatus(types.WorkerIdle)
-	//
-	// startTime := time.Now()
-	// w.lastJobTime = startTime
-	//
-	// result := types.JobResult{
-	//     JobID:     job.ID,
-	//     WorkerID:  w.id,
-	//     StartTime: startTime,
-	// }
-	//
-	// // Set up job context with timeout
-	// jobCtx := w.ctx
-	// if job.Timeout > 0 {
-	//     var cancel context.CancelFunc
-	//     jobCtx, cancel = context.WithTimeout(w.ctx, job.Timeout)
-	//     defer cancel()
-	// }
-	//
-	// // Process the job
-	// data, err := w.executeJob(jobCtx, job)
-	// endTime := time.Now()
-	//
-	// result.Data = data
-	// result.Error = err
-	// result.EndTime = endTime
-	// result.Duration = endTime.Sub(startTime)
-	//
-	// // Send result
-	// select {
-	// case w.resultQueue <- result:
-	//     w.jobsProcessed++
-	// case <-w.ctx.Done():
-	//     // Worker shutting down
-	//     return
-	// }
+	w.setStatus(types.WorkerBusy)
+	defer w.setStatus(types.WorkerIdle)
+
+	startTime := time.Now()
+	w.mu.Lock()
+	w.lastJobTime = startTime
+	w.mu.Unlock()
+
+	if w.metrics != nil {
+		w.metrics.RecordWaitTime(job.Priority, startTime.Sub(job.EnqueuedAt))
+	}
+	job.Deliveries++
+	a := &attempt{job: job, worker: w, startedAt: startTime}
+
+	result := types.JobResult{
+		JobID:      job.ID,
+		WorkerID:   w.id,
+		StartTime:  startTime,
+		Attempt:    job.Attempt,
+		Deliveries: job.Deliveries,
+	}
+
+	// Set up job context: cancelled by the worker, the job's own context,
+	// the job timeout or the loss of its lease, whichever comes first
+	jobCtx, cancel := context.WithCancel(context.WithValue(w.ctx, attemptKey{}, a))
+	defer cancel()
+	a.cancel = cancel
+	if job.Context != nil {
+		stop := context.AfterFunc(job.Context, cancel)
+		defer stop()
+		// AfterFunc calls cancel in its own goroutine if the job was
+		// cancelled before it got here, which may be too late for the
+		// check below
+		if job.Context.Err() != nil {
+			cancel()
+		}
+	}
+	if job.Timeout > 0 {
+		var timeoutCancel context.CancelFunc
+		jobCtx, timeoutCancel = context.WithTimeout(jobCtx, job.Timeout)
+		defer timeoutCancel()
+	}
+	w.observer.started(a)
+
+	// Process the job unless it was cancelled while queued
+	var data interface{}
+	err := jobCtx.Err()
+	if err == nil {
+		data, err = w.executeJob(jobCtx, job)
+	}
+	endTime := time.Now()
+
+	result.Data = data
+	result.Error = err
+	result.EndTime = endTime
+	result.Duration = endTime.Sub(startTime)
+
+	// Settle the lease. If the watchdog abandoned this attempt or its
+	// lease expired, the job was already reported or queued again.
+	if !a.claim() {
+		return
+	}
+
+	w.mu.Lock()
+	w.jobsProcessed++
+	w.mu.Unlock()
+
+	// complete acks a success and nacks a failure
+	w.observer.complete(w.ctx, job, result)
 }
 
-// executeJob performs the actual job work
-func (w *Worker) executeJob(ctx context.Context, job types.Job) (interface{}, error) {
-	// TODO: Implement job execution logic
-	// Hints:
-	// - This is where the actual work happens
-	// - Respect context cancellation
-	// - Handle different job types if needed
-	// - Simulate work for now (can be replaced with real logic)
-	// - Return processed data or error
-
-	// TODO: Add implementation
-	// Example structure (for simulation):
-	// // Simulate work that respects context
-	// select {
-	// case <-time.After(100 * time.Millisecond): // Simulate work time
-	//     // Job completed successfully
-	//     return fmt.Sprintf("Processed: %v", job.Data), nil
-	// case <-ctx.Done():
-	//     // Job was cancelled
-	//     return nil, ctx.Err()
-	// }
-
-	return nil, nil // TODO: Remove this line when implementing
+// executeJob performs the actual job work through the middleware chain.
+// A panic in the handler is returned as a *PanicError.
+func (w *Worker) executeJob(ctx context.Context, job types.Job) (data interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			data, err = nil, newPanicError(job.ID, r)
+		}
+	}()
+	return w.handler(ctx, job)
 }
 
-// Stop gracefully stops the worker
-func (w *Worker) Stop() {
-	// TODO: Implement worker stop logic
-	// Hints:
-	// - Update worker status to stopped
-	// - The actual stopping is handled by context cancellation
-	// - This method is mainly for status tracking
+// simulateWork stands in for a handler, taking 100ms unless ctx ends first
+func simulateWork(ctx context.Context, job types.Job) (interface{}, error) {
+	select {
+	case <-time.After(100 * time.Millisecond):
+		return fmt.Sprintf("Processed: %v", job.Data), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
 
+// Stop stops the worker. The context of its current job is cancelled and
+// it picks up no further jobs.
+func (w *Worker) Stop() {
+	if w.cancel != nil {
+		w.cancel()
+	}
 	w.setStatus(types.WorkerStopped)
 }
 
+// Retire stops the worker once its current job, if any, finishes. Unlike
+// Stop it does not cancel the running job.
+func (w *Worker) Retire() {
+	w.retireOnce.Do(func() { close(w.quit) })
+}
+
+// IsRetired returns whether the worker has been retired
+func (w *Worker) IsRetired() bool {
+	select {
+	case <-w.quit:
+		return true
+	default:
+		return false
+	}
+}
+
 // GetStatus returns the current worker status
 func (w *Worker) GetStatus() types.WorkerStatus {
-	// TODO: Implement status getter
-	// Hints:
-	// - Use read lock for thread safety
-	// - Return current status
-
 	w.mu.RLock()
 	defer w.mu.RUnlock()
 	return w.status
@@ -187,11 +265,6 @@ func (w *Worker) GetStatus() types.WorkerStatus {
 
 // setStatus updates the worker status
 func (w *Worker) setStatus(status types.WorkerStatus) {
-	// TODO: Implement status setter
-	// Hints:
-	// - Use write lock for thread safety
-	// - Update status field
-
 	w.mu.Lock()
 	defer w.mu.Unlock()
 	w.status = status
@@ -204,23 +277,29 @@ func (w *Worker) GetID() int {
 
 // GetJobsProcessed returns the number of jobs processed by this worker
 func (w *Worker) GetJobsProcessed() int64 {
-	// TODO: Implement jobs processed counter getter
-	// Hints:
-	// - Use read lock for thread safety
-	// - Return jobs processed count
-
 	w.mu.RLock()
 	defer w.mu.RUnlock()
 	return w.jobsProcessed
 }
 
+// restarted records that the worker's loop was restarted after a crash
+func (w *Worker) restarted() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.restarts++
+	w.status = types.WorkerIdle
+}
+
+// GetRestarts returns how many times the worker was restarted after a
+// crash
+func (w *Worker) GetRestarts() int {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.restarts
+}
+
 // GetLastJobTime returns when this worker last processed a job
 func (w *Worker) GetLastJobTime() time.Time {
-	// TODO: Implement last job time getter
-	// Hints:
-	// - Use read lock for thread safety
-	// - Return last job time
-
 	w.mu.RLock()
 	defer w.mu.RUnlock()
 	return w.lastJobTime
@@ -250,11 +329,3 @@ func (w *Worker) IsBusy() bool {
 func (w *Worker) IsStopped() bool {
 	return w.GetStatus() == types.WorkerStopped
 }
-
-// TODO: Add any additional methods you think would be useful
-// Consider:
-// - Health check methods
-// - Performance metrics for individual workers
-// - Job history tracking
-// - Error rate tracking
-// - Load balancing hints
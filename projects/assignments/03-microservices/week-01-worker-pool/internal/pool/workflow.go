@@ -0,0 +1,419 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/cs-mastery/worker-pool/pkg/types"
+)
+
+// Workflow errors
+var (
+	ErrMissingWorkflowID   = errors.New("workflow ID is required")
+	ErrDuplicateWorkflowID = errors.New("workflow ID already in use")
+	ErrWorkflowNotFound    = errors.New("workflow not found")
+	ErrInvalidWorkflow     = errors.New("invalid workflow")
+)
+
+// workflowStep is one step of a running workflow
+type workflowStep struct {
+	step            types.WorkflowStep
+	name            string
+	state           types.StepState
+	jobID           string
+	output          interface{}
+	err             error
+	compensationErr error
+	history         []types.StepChange
+}
+
+// WorkflowRun is a handle to a workflow submitted with SubmitWorkflow.
+// Step results go to the workflow, never to the pool's shared result
+// queue.
+type WorkflowRun struct {
+	pool *Pool
+	id   string
+	done chan struct{}
+
+	mu         sync.Mutex
+	steps      []*workflowStep
+	state      types.WorkflowState
+	output     interface{}
+	err        error
+	cancelled  bool
+	startedAt  time.Time
+	finishedAt time.Time
+}
+
+// SubmitWorkflow validates a workflow and submits its first step. Each
+// later step is submitted once the one before it succeeds, with that
+// step's output as its input. When a step fails, or the workflow is
+// cancelled, the remaining steps are skipped and the compensations of the
+// steps that succeeded run one at a time, most recent first. A failed
+// compensation is recorded and the rest still run.
+//
+// ctx bounds how long SubmitWorkflow waits for queue space for the first
+// step; if it cannot be queued the workflow is dropped and the error
+// returned.
+func (p *Pool) SubmitWorkflow(ctx context.Context, workflow types.Workflow) (*WorkflowRun, error) {
+	if workflow.ID == "" {
+		return nil, ErrMissingWorkflowID
+	}
+	if !p.IsRunning() {
+		return nil, ErrPoolNotRunning
+	}
+
+	r, err := newWorkflowRun(p, workflow)
+	if err != nil {
+		return nil, err
+	}
+
+	p.workflowsMu.Lock()
+	p.evictWorkflowsLocked(time.Now())
+	if existing, ok := p.workflows[workflow.ID]; ok && !existing.finished() {
+		p.workflowsMu.Unlock()
+		return nil, fmt.Errorf("%w: %s", ErrDuplicateWorkflowID, workflow.ID)
+	}
+	p.workflows[workflow.ID] = r
+	p.workflowsMu.Unlock()
+
+	results, err := r.submitStep(ctx, 0, workflow.Input)
+	if err != nil {
+		p.workflowsMu.Lock()
+		if p.workflows[workflow.ID] == r {
+			delete(p.workflows, workflow.ID)
+		}
+		p.workflowsMu.Unlock()
+		return nil, fmt.Errorf("submitting workflow step %s: %w", r.steps[0].name, err)
+	}
+
+	go r.run(results)
+	return r, nil
+}
+
+// newWorkflowRun validates a workflow and builds its steps
+func newWorkflowRun(p *Pool, workflow types.Workflow) (*WorkflowRun, error) {
+	if len(workflow.Steps) == 0 {
+		return nil, fmt.Errorf("%w: no steps", ErrInvalidWorkflow)
+	}
+
+	now := time.Now()
+	r := &WorkflowRun{
+		pool:      p,
+		id:        workflow.ID,
+		done:      make(chan struct{}),
+		state:     types.WorkflowRunning,
+		startedAt: now,
+	}
+
+	names := make(map[string]bool, len(workflow.Steps))
+	for i, step := range workflow.Steps {
+		name := step.Name
+		if name == "" {
+			name = fmt.Sprintf("step-%d", i)
+		}
+		if step.Handler == nil {
+			return nil, fmt.Errorf("%w: step %s has no handler", ErrInvalidWorkflow, name)
+		}
+		if names[name] {
+			return nil, fmt.Errorf("%w: duplicate step %s", ErrInvalidWorkflow, name)
+		}
+		names[name] = true
+
+		r.steps = append(r.steps, &workflowStep{
+			step:    step,
+			name:    name,
+			state:   types.StepPending,
+			history: []types.StepChange{{State: types.StepPending, At: now}},
+		})
+	}
+	return r, nil
+}
+
+// run drives the workflow from the first step's result to the end,
+// submitting each step after the one before it succeeds
+func (r *WorkflowRun) run(results <-chan types.JobResult) {
+	last := len(r.steps) - 1
+	for i := 0; ; i++ {
+		result := <-results
+		if result.Error != nil {
+			r.fail(i, result.Error)
+			return
+		}
+
+		r.mu.Lock()
+		step := r.steps[i]
+		step.output = result.Data
+		r.setStepLocked(step, types.StepSucceeded)
+		if i == last {
+			r.output = result.Data
+			r.state = types.WorkflowSucceeded
+			r.finishLocked()
+			r.mu.Unlock()
+			return
+		}
+		r.mu.Unlock()
+
+		var err error
+		if results, err = r.submitStep(context.Background(), i+1, result.Data); err != nil {
+			r.fail(i+1, err)
+			return
+		}
+	}
+}
+
+// submitStep queues step i with the given input. The returned channel
+// receives the step's final result.
+func (r *WorkflowRun) submitStep(ctx context.Context, i int, input interface{}) (<-chan types.JobResult, error) {
+	r.mu.Lock()
+	if r.cancelled {
+		r.mu.Unlock()
+		return nil, context.Canceled
+	}
+	step := r.steps[i]
+	step.jobID = fmt.Sprintf("%s-step-%d", r.id, i)
+	r.setStepLocked(step, types.StepRunning)
+	r.mu.Unlock()
+
+	handler := step.step.Handler
+	job := step.step.Job
+	job.ID = step.jobID
+	job.Type = ""
+	job.Data = taskFunc(func(ctx context.Context, job types.Job) (interface{}, error) {
+		job.Data = input
+		return handler(ctx, job)
+	})
+
+	results := make(chan types.JobResult, 1)
+	w := &watcher{fn: func(result types.JobResult) { results <- result }, exclusive: true}
+	if err := r.pool.submit(ctx, job, w); err != nil {
+		return nil, err
+	}
+
+	// The workflow may have been cancelled while the step was being queued
+	r.mu.Lock()
+	cancelled := r.cancelled
+	r.mu.Unlock()
+	if cancelled {
+		r.pool.CancelJob(job.ID)
+	}
+	return results, nil
+}
+
+// fail records that step i failed, skips the steps after it and
+// compensates the ones before it
+func (r *WorkflowRun) fail(i int, err error) {
+	r.mu.Lock()
+	step := r.steps[i]
+	step.err = err
+	r.setStepLocked(step, types.StepFailed)
+	for _, later := range r.steps[i+1:] {
+		r.setStepLocked(later, types.StepSkipped)
+	}
+	r.err = fmt.Errorf("step %s: %w", step.name, err)
+	r.state = types.WorkflowCompensating
+	r.mu.Unlock()
+
+	r.compensate(i - 1)
+}
+
+// compensate runs the compensations of the succeeded steps up to and
+// including step from, most recent first, then settles the workflow
+func (r *WorkflowRun) compensate(from int) {
+	state := types.WorkflowCompensated
+	for i := from; i >= 0; i-- {
+		r.mu.Lock()
+		step := r.steps[i]
+		if step.state != types.StepSucceeded || step.step.Compensate == nil {
+			r.mu.Unlock()
+			continue
+		}
+		r.setStepLocked(step, types.StepCompensating)
+		r.mu.Unlock()
+
+		err := r.runCompensation(i, step)
+
+		r.mu.Lock()
+		if err != nil {
+			step.compensationErr = err
+			r.setStepLocked(step, types.StepCompensationFailed)
+			state = types.WorkflowCompensationFailed
+		} else {
+			r.setStepLocked(step, types.StepCompensated)
+		}
+		r.mu.Unlock()
+	}
+
+	r.mu.Lock()
+	r.state = state
+	r.finishLocked()
+	r.mu.Unlock()
+}
+
+// runCompensation runs step i's compensating handler through the pool
+// with the step's output as input and waits for its final result
+func (r *WorkflowRun) runCompensation(i int, step *workflowStep) error {
+	r.mu.Lock()
+	output := step.output
+	r.mu.Unlock()
+
+	compensate := step.step.Compensate
+	job := step.step.Job
+	job.ID = fmt.Sprintf("%s-compensate-%d", r.id, i)
+	job.Type = ""
+	job.Data = taskFunc(func(ctx context.Context, job types.Job) (interface{}, error) {
+		job.Data = output
+		return compensate(ctx, job)
+	})
+
+	results := make(chan types.JobResult, 1)
+	w := &watcher{fn: func(result types.JobResult) { results <- result }, exclusive: true}
+	if err := r.pool.submit(context.Background(), job, w); err != nil {
+		return err
+	}
+	return (<-results).Error
+}
+
+// setStepLocked moves a step to a new state and records the change.
+// Caller holds r.mu.
+func (r *WorkflowRun) setStepLocked(step *workflowStep, state types.StepState) {
+	step.state = state
+	step.history = append(step.history, types.StepChange{State: state, At: time.Now()})
+}
+
+// finishLocked settles the workflow. Caller holds r.mu.
+func (r *WorkflowRun) finishLocked() {
+	r.finishedAt = time.Now()
+	close(r.done)
+	go r.pool.workflowFinished(r)
+}
+
+// ID returns the workflow's ID
+func (r *WorkflowRun) ID() string {
+	return r.id
+}
+
+// Done returns a channel that is closed once the workflow has succeeded
+// or finished compensating
+func (r *WorkflowRun) Done() <-chan struct{} {
+	return r.done
+}
+
+// finished reports whether the workflow has settled
+func (r *WorkflowRun) finished() bool {
+	select {
+	case <-r.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// Wait blocks until the workflow settles or ctx is done and returns the
+// workflow's final status, or ctx's error if ctx ends first
+func (r *WorkflowRun) Wait(ctx context.Context) (types.WorkflowStatus, error) {
+	select {
+	case <-r.done:
+		return r.Status(), nil
+	case <-ctx.Done():
+		return types.WorkflowStatus{}, ctx.Err()
+	}
+}
+
+// Cancel stops the workflow: the running step is cancelled through the
+// pool and the steps that already succeeded are compensated. It does
+// nothing once a step has failed or the workflow has finished.
+func (r *WorkflowRun) Cancel() {
+	r.mu.Lock()
+	if r.state != types.WorkflowRunning || r.cancelled {
+		r.mu.Unlock()
+		return
+	}
+	r.cancelled = true
+	var running string
+	for _, step := range r.steps {
+		if step.state == types.StepRunning {
+			running = step.jobID
+		}
+	}
+	r.mu.Unlock()
+
+	if running != "" {
+		r.pool.CancelJob(running)
+	}
+}
+
+// Status returns a snapshot of the workflow and its steps
+func (r *WorkflowRun) Status() types.WorkflowStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	status := types.WorkflowStatus{
+		ID:         r.id,
+		State:      r.state,
+		Steps:      make([]types.WorkflowStepStatus, 0, len(r.steps)),
+		Output:     r.output,
+		Error:      r.err,
+		StartedAt:  r.startedAt,
+		FinishedAt: r.finishedAt,
+	}
+	for _, step := range r.steps {
+		status.Steps = append(status.Steps, types.WorkflowStepStatus{
+			Name:              step.name,
+			State:             step.state,
+			JobID:             step.jobID,
+			Output:            step.output,
+			Error:             step.err,
+			CompensationError: step.compensationErr,
+			History:           append([]types.StepChange(nil), step.history...),
+		})
+	}
+	return status
+}
+
+// GetWorkflowStatus returns the status of a workflow submitted with
+// SubmitWorkflow. Finished workflows remain available like finished jobs,
+// for PoolConfig.JobRetention.
+func (p *Pool) GetWorkflowStatus(id string) (types.WorkflowStatus, error) {
+	p.workflowsMu.Lock()
+	p.evictWorkflowsLocked(time.Now())
+	r, ok := p.workflows[id]
+	p.workflowsMu.Unlock()
+
+	if !ok {
+		return types.WorkflowStatus{}, fmt.Errorf("%w: %s", ErrWorkflowNotFound, id)
+	}
+	return r.Status(), nil
+}
+
+// workflowFinished queues a finished workflow for eviction
+func (p *Pool) workflowFinished(r *WorkflowRun) {
+	p.workflowsMu.Lock()
+	defer p.workflowsMu.Unlock()
+
+	if p.workflows[r.id] != r {
+		return
+	}
+	p.finishedWorkflows = append(p.finishedWorkflows, finishedJob{id: r.id, at: r.finishedAt})
+	p.evictWorkflowsLocked(time.Now())
+}
+
+// evictWorkflowsLocked drops finished workflows past JobRetention or
+// beyond MaxJobRecords. Caller holds workflowsMu.
+func (p *Pool) evictWorkflowsLocked(now time.Time) {
+	for len(p.finishedWorkflows) > 0 {
+		oldest := p.finishedWorkflows[0]
+		expired := p.config.JobRetention > 0 && now.Sub(oldest.at) > p.config.JobRetention
+		if !expired && len(p.finishedWorkflows) <= p.config.MaxJobRecords {
+			return
+		}
+
+		p.finishedWorkflows = p.finishedWorkflows[1:]
+		if r, ok := p.workflows[oldest.id]; ok && r.finished() && r.finishedAt.Equal(oldest.at) {
+			delete(p.workflows, oldest.id)
+		}
+	}
+}
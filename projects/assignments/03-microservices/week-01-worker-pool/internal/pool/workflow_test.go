@@ -0,0 +1,155 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cs-mastery/worker-pool/pkg/types"
+)
+
+// stepStates returns the states in a step's history, oldest first
+func stepStates(step types.WorkflowStepStatus) []types.StepState {
+	var states []types.StepState
+	for _, change := range step.History {
+		states = append(states, change.State)
+	}
+	return states
+}
+
+func TestWorkflowCompensatesInReverse(t *testing.T) {
+	p := NewPool(types.PoolConfig{WorkerCount: 2})
+	if err := p.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer p.Stop()
+
+	var mu sync.Mutex
+	var log []string
+	record := func(event string) {
+		mu.Lock()
+		defer mu.Unlock()
+		log = append(log, event)
+	}
+
+	// Each step appends its name to the input; step d fails, and the
+	// compensation of b fails too without stopping a's
+	var steps []types.WorkflowStep
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		name := name
+		steps = append(steps, types.WorkflowStep{
+			Name: name,
+			Handler: func(ctx context.Context, job types.Job) (interface{}, error) {
+				record("run " + name)
+				if name == "d" {
+					return nil, errors.New("boom")
+				}
+				return job.Data.(string) + name, nil
+			},
+			Compensate: func(ctx context.Context, job types.Job) (interface{}, error) {
+				record(fmt.Sprintf("undo %s(%v)", name, job.Data))
+				if name == "b" {
+					return nil, errors.New("undo failed")
+				}
+				return nil, nil
+			},
+		})
+	}
+
+	run, err := p.SubmitWorkflow(context.Background(), types.Workflow{ID: "order", Input: ">", Steps: steps})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	status, err := run.Wait(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"run a", "run b", "run c", "run d", "undo c(>abc)", "undo b(>ab)", "undo a(>a)"}
+	if !reflect.DeepEqual(log, want) {
+		t.Errorf("events = %v, want %v", log, want)
+	}
+	if status.State != types.WorkflowCompensationFailed {
+		t.Errorf("state = %s, want compensation_failed", status.State)
+	}
+	if status.Error == nil || status.Error.Error() != "step d: boom" {
+		t.Errorf("error = %v, want step d: boom", status.Error)
+	}
+	if status.FinishedAt.IsZero() {
+		t.Error("FinishedAt is zero after the workflow settled")
+	}
+
+	P, R, S, F, K := types.StepPending, types.StepRunning, types.StepSucceeded, types.StepFailed, types.StepSkipped
+	C, D, X := types.StepCompensating, types.StepCompensated, types.StepCompensationFailed
+	wantHistory := map[string][]types.StepState{
+		"a": {P, R, S, C, D},
+		"b": {P, R, S, C, X},
+		"c": {P, R, S, C, D},
+		"d": {P, R, F},
+		"e": {P, K},
+	}
+	for _, step := range status.Steps {
+		if got := stepStates(step); !reflect.DeepEqual(got, wantHistory[step.Name]) {
+			t.Errorf("step %s history = %v, want %v", step.Name, got, wantHistory[step.Name])
+		}
+		for i := 1; i < len(step.History); i++ {
+			if step.History[i].At.Before(step.History[i-1].At) {
+				t.Errorf("step %s history is out of order: %v", step.Name, step.History)
+			}
+		}
+	}
+	if err := status.Steps[1].CompensationError; err == nil || err.Error() != "undo failed" {
+		t.Errorf("step b CompensationError = %v, want undo failed", err)
+	}
+
+	// Compensations ran most recent first
+	var undone []time.Time
+	for _, i := range []int{2, 1, 0} {
+		history := status.Steps[i].History
+		undone = append(undone, history[len(history)-1].At)
+	}
+	for i := 1; i < len(undone); i++ {
+		if undone[i].Before(undone[i-1]) {
+			t.Errorf("compensations finished at %v, want steps c, b, a in that order", undone)
+		}
+	}
+}
+
+func TestWorkflowSucceeds(t *testing.T) {
+	p := NewPool(types.PoolConfig{WorkerCount: 1})
+	if err := p.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer p.Stop()
+
+	double := func(ctx context.Context, job types.Job) (interface{}, error) {
+		return job.Data.(int) * 2, nil
+	}
+	run, err := p.SubmitWorkflow(context.Background(), types.Workflow{
+		ID:    "double",
+		Input: 3,
+		Steps: []types.WorkflowStep{{Handler: double}, {Handler: double}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	status, err := run.Wait(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.State != types.WorkflowSucceeded || status.Output != 12 {
+		t.Errorf("status = %s with output %v, want succeeded with 12", status.State, status.Output)
+	}
+	if got, err := p.GetWorkflowStatus("double"); err != nil || got.Steps[1].Name != "step-1" {
+		t.Errorf("GetWorkflowStatus = %+v, %v", got, err)
+	}
+	if _, err := p.GetWorkflowStatus("missing"); !errors.Is(err, ErrWorkflowNotFound) {
+		t.Errorf("GetWorkflowStatus(missing) = %v, want ErrWorkflowNotFound", err)
+	}
+}
@@ -2,195 +2,815 @@ package types
 
 import (
 	"context"
+	"runtime"
+	"time"
 )
 
 // Job represents a unit of work to be processed by the worker pool
 type Job struct {
-	// TODO: Add job fields
-	// Hints:
-	// - ID: unique identifier for the job
-	// - Data: the actual work payload
-	// - Priority: job priority (optional for advanced features)
-	// - Timeout: maximum time allowed for job processing
-	// - CreatedAt: timestamp when job was created
-	// - Context: context for cancellation (optional)
-
-	// Example implementation:
-	// ID        string
-	// Data      interface{}
-	// Priority  int
-	// Timeout   time.Duration
-	// CreatedAt time.Time
-	// Context   context.Context
+	ID     string
+	Type   string // Selects a registered handler; empty uses PoolConfig.Handler
+	Tenant string // Fair-queuing tenant (see PoolConfig.Tenants)
+
+	// PartitionKey names the entity the job acts on; see
+	// PoolConfig.MaxConcurrentPerKey
+	PartitionKey string
+
+	// IdempotencyKey suppresses resubmissions: within
+	// PoolConfig.IdempotencyWindow a job with the same key is not queued
+	// again. IdempotencyFingerprint identifies the request behind the key,
+	// e.g. a hash of its body; reusing the key with a different
+	// fingerprint is refused.
+	IdempotencyKey         string
+	IdempotencyFingerprint string
+	Data                   interface{}
+	Priority               int           // Higher number = higher priority
+	Timeout                time.Duration // Zero uses PoolConfig.JobTimeout
+	CreatedAt              time.Time     // Set by the pool on submission
+	Context                context.Context
+
+	// RetryPolicy overrides PoolConfig.RetryPolicy for this job when set
+	RetryPolicy *RetryPolicy
+
+	// Attempt bookkeeping, maintained by the pool
+	EnqueuedAt    time.Time     // When the current attempt was queued
+	Attempt       int           // 1 for the first run
+	AttemptErrors []error       // Errors from earlier attempts
+	RetryDelay    time.Duration // Backoff applied before the current attempt
+	Deliveries    int           // Times the current attempt was handed to a worker
 }
 
 // JobResult represents the outcome of job processing
 type JobResult struct {
-	// TODO: Add result fields
-	// Hints:
-	// - JobID: reference to the original job
-	// - Data: processed result data
-	// - Error: any error that occurred during processing
-	// - WorkerID: which worker processed this job
-	// - StartTime: when processing started
-	// - EndTime: when processing completed
-	// - Duration: how long processing took
-
-	// Example implementation:
-	// JobID     string
-	// Data      interface{}
-	// Error     error
-	// WorkerID  int
-	// StartTime time.Time
-	// EndTime   time.Time
-	// Duration  time.Duration
+	JobID     string
+	Data      interface{}
+	Error     error
+	WorkerID  int
+	StartTime time.Time
+	EndTime   time.Time
+	Duration  time.Duration
+	Attempt   int     // Attempt that produced this result
+	Errors    []error // Error history across all attempts, oldest first
+
+	// Deliveries counts the times the attempt was handed to a worker; more
+	// than one means its lease expired and it was redelivered
+	Deliveries int
+}
+
+// JobTypeConfig configures how jobs of one type are handled
+type JobTypeConfig struct {
+	Handler        JobHandler
+	Timeout        time.Duration // Default job timeout; zero uses PoolConfig.JobTimeout
+	RetryPolicy    *RetryPolicy  // Default retry policy; nil uses PoolConfig.RetryPolicy
+	MaxConcurrency int           // Jobs of this type running at once; zero is unlimited
+}
+
+// JobTypeMetrics contains per-type job counts and latency
+type JobTypeMetrics struct {
+	Submitted      int64
+	Succeeded      int64
+	Failed         int64
+	Running        int
+	Waiting        int // Queued jobs held back by MaxConcurrency
+	AverageLatency time.Duration
+}
+
+// TenantConfig configures fair queuing for one tenant
+type TenantConfig struct {
+	Weight      int // Share of dispatches relative to other tenants; defaults to 1
+	MaxQueued   int // Jobs waiting in the queue at once; zero is unlimited
+	MaxInFlight int // Jobs handed to workers at once; zero is unlimited
+}
+
+// TenantMetrics contains per-tenant queue depth, throughput and
+// rejections
+type TenantMetrics struct {
+	Queued        int // Jobs waiting in the tenant's queue
+	InFlight      int // Jobs handed to workers
+	Submitted     int64
+	Processed     int64   // Jobs that finished, successfully or not
+	Rejected      int64   // Submissions refused by MaxQueued or a full pool queue
+	JobsPerSecond float64 // Processed jobs per second since metrics started
+}
+
+// RetryPolicy controls how failed jobs are retried
+type RetryPolicy struct {
+	MaxAttempts int                  // Total attempts including the first; <= 1 disables retries
+	Backoff     BackoffPolicy        // Delay before each retry; nil retries immediately
+	Retryable   func(err error) bool // Classifies errors; nil uses the pool default
+}
+
+// BackoffPolicy computes the delay before a retry
+type BackoffPolicy interface {
+	// Next returns the delay before the given attempt (2 for the first
+	// retry); previous is the delay used before the last attempt
+	Next(attempt int, previous time.Duration) time.Duration
+}
+
+// DeadLetter is a job that failed after exhausting its retries
+type DeadLetter struct {
+	Job      Job
+	Result   JobResult
+	FailedAt time.Time
+}
+
+// OverlapPolicy decides what happens when a recurring job fires while an
+// earlier run is still queued or running
+type OverlapPolicy int
+
+const (
+	OverlapSkip           OverlapPolicy = iota // Skip this run
+	OverlapQueue                               // Queue this run anyway
+	OverlapCancelPrevious                      // Cancel earlier runs, then queue this one
+)
+
+// RecurringJob registers a job that runs on a cron schedule
+type RecurringJob struct {
+	Name    string        // Unique schedule name
+	Spec    string        // 5-field cron expression, @every <duration> or @hourly etc.
+	Job     Job           // Template for every run; each run gets ID "<Name>-<unix nanos>" and no IdempotencyKey
+	Overlap OverlapPolicy // Defaults to OverlapSkip
+}
+
+// ScheduleInfo describes a registered recurring job
+type ScheduleInfo struct {
+	Name    string
+	Spec    string
+	Overlap OverlapPolicy
+	NextRun time.Time
+	LastRun time.Time
+	Runs    int64 // Runs submitted to the pool
+	Skipped int64 // Runs skipped because of OverlapSkip
+	Running int   // Runs queued or in flight
 }
 
 // WorkerPool defines the interface for a worker pool
 type WorkerPool interface {
-	// TODO: Define worker pool interface
-	// Hints:
-	// - Start() error: start the worker pool
-	// - Stop() error: gracefully stop the worker pool
-	// - Submit(job Job) error: submit a job for processing
-	// - SubmitWithContext(ctx context.Context, job Job) error: submit with context
-	// - GetMetrics() PoolMetrics: get current pool metrics
-	// - SetWorkerCount(count int) error: dynamically adjust worker count
-
-	// Example interface:
-	// Start() error
-	// Stop() error
-	// Submit(job Job) error
-	// SubmitWithContext(ctx context.Context, job Job) error
-	// GetResult() (JobResult, error)
-	// GetMetrics() PoolMetrics
+	Start() error
+	Stop() error
+	Submit(job Job) error
+	SubmitWithContext(ctx context.Context, job Job) error
+	GetResult() (JobResult, error)
+	GetMetrics() PoolMetrics
+	SetWorkerCount(count int) error
 }
 
 // PoolMetrics contains performance metrics for the worker pool
 type PoolMetrics struct {
-	// TODO: Add metrics fields
-	// Hints:
-	// - JobsSubmitted: total jobs submitted
-	// - JobsProcessed: total jobs completed
-	// - JobsSucceeded: jobs completed successfully
-	// - JobsFailed: jobs that failed
-	// - AverageLatency: average time to process a job
-	// - ActiveWorkers: current number of active workers
-	// - QueueLength: current number of jobs in queue
-	// - TotalWorkers: total number of workers
-
-	// Example implementation:
-	// JobsSubmitted   int64
-	// JobsProcessed   int64
-	// JobsSucceeded   int64
-	// JobsFailed      int64
-	// AverageLatency  time.Duration
-	// ActiveWorkers   int32
-	// QueueLength     int32
-	// TotalWorkers    int32
+	JobsSubmitted  int64
+	JobsProcessed  int64
+	JobsSucceeded  int64
+	JobsFailed     int64
+	JobsRetried    int64
+	JobsCancelled  int64
+	DeadLetters    int64
+	AverageLatency time.Duration
+	JobsPerSecond  float64
+
+	// Handler calls recorded by the pool's metrics middleware
+	Attempts           int64
+	AttemptFailures    int64
+	AverageHandlerTime time.Duration
+
+	ActiveWorkers  int32
+	QueueLength    int32 // Ready and scheduled jobs
+	ScheduledJobs  int32 // Jobs waiting for their run time
+	TotalWorkers   int32
+	WorkerRestarts int64 // Workers restarted after a crash
+	JobsAbandoned  int64 // Jobs abandoned by the stuck-job watchdog
+	StuckJobs      int32 // Running jobs past their timeout or heartbeat
+
+	// Jobs handed back to the queue after their lease expired
+	JobsRedelivered int64
+
+	// Outcomes of submissions that found the queue full (see
+	// OverflowPolicy)
+	SubmitsBlocked    int64 // Waited for space
+	SubmitsRejected   int64 // Refused with ErrQueueFull or ErrSubmitTimeout
+	JobsDroppedOldest int64 // Queued jobs evicted for newer ones
+	JobsDroppedNewest int64 // New jobs dropped
+	JobsRunByCaller   int64 // New jobs run by the submitter
+
+	// Submissions not queued because their idempotency key was already used
+	SubmitsDeduplicated int64
+
+	// Autoscaler activity: number of scale-ups and scale-downs and the
+	// most recent changes, oldest first
+	ScaleUps    int64
+	ScaleDowns  int64
+	ScaleEvents []ScaleEvent
+
+	// Pause state: whether the pool is paused, for how long, and the total
+	// time spent paused since it was created
+	Paused      bool
+	PausedFor   time.Duration
+	TotalPaused time.Duration
+
+	// WaitTimeByPriority breaks down queue wait time by priority band
+	// (see PriorityBand)
+	WaitTimeByPriority map[string]WaitTimeStats
+
+	// Schedules lists registered recurring jobs and their next run
+	Schedules []ScheduleInfo
+
+	// JobTypes breaks down counts and latency by Job.Type
+	JobTypes map[string]JobTypeMetrics
+
+	// Tenants breaks down queue depth, throughput and rejections by
+	// Job.Tenant
+	Tenants map[string]TenantMetrics
+}
+
+// WaitTimeStats summarizes how long jobs waited in the queue before a
+// worker picked them up
+type WaitTimeStats struct {
+	Jobs    int64
+	Average time.Duration
+	Max     time.Duration
+}
+
+// Priority thresholds used to group jobs into bands for reporting
+const (
+	PriorityMedium = 5
+	PriorityHigh   = 8
+)
+
+// PriorityBand returns the reporting band ("high", "medium" or "low") for a
+// job priority
+func PriorityBand(priority int) string {
+	switch {
+	case priority >= PriorityHigh:
+		return "high"
+	case priority >= PriorityMedium:
+		return "medium"
+	default:
+		return "low"
+	}
 }
 
 // JobStatus represents the current status of a job
 type JobStatus int
 
 const (
-	// TODO: Define job status constants
-	// Hints:
-	// - JobPending: job is waiting to be processed
-	// - JobProcessing: job is currently being processed
-	// - JobCompleted: job completed successfully
-	// - JobFailed: job failed during processing
-	// - JobCancelled: job was cancelled
-	// - JobTimedOut: job exceeded timeout duration
-
-	// Example implementation:
-	// JobPending JobStatus = iota
-	// JobProcessing
-	// JobCompleted
-	// JobFailed
-	// JobCancelled
-	// JobTimedOut
-	_ JobStatus = iota // TODO: Replace with actual constants
+	JobPending JobStatus = iota
+	JobProcessing
+	JobCompleted
+	JobFailed
+	JobCancelled
+	JobTimedOut
+	JobScheduled
+)
+
+// String returns the lowercase name of the status
+func (s JobStatus) String() string {
+	switch s {
+	case JobPending:
+		return "pending"
+	case JobProcessing:
+		return "processing"
+	case JobCompleted:
+		return "completed"
+	case JobFailed:
+		return "failed"
+	case JobCancelled:
+		return "cancelled"
+	case JobTimedOut:
+		return "timed_out"
+	case JobScheduled:
+		return "scheduled"
+	default:
+		return "unknown"
+	}
+}
+
+// JobRecord is the tracked lifecycle of a submitted job
+type JobRecord struct {
+	ID          string
+	Status      JobStatus
+	Attempt     int
+	WorkerID    int
+	Error       error
+	SubmittedAt time.Time
+	UpdatedAt   time.Time
+	History     []StatusChange // Oldest first
+}
+
+// StatusChange records one job status transition
+type StatusChange struct {
+	Status JobStatus
+	At     time.Time
+}
+
+// StuckJob describes a running job that is past its timeout or has
+// missed a heartbeat
+type StuckJob struct {
+	JobID         string
+	WorkerID      int
+	Attempt       int
+	StartedAt     time.Time
+	Elapsed       time.Duration
+	Timeout       time.Duration
+	LastHeartbeat time.Time // Zero if the handler never called Heartbeat
+	Reason        string    // "past_timeout" or "missed_heartbeat"
+}
+
+// DrainReport lists what happened to the jobs that were active when the
+// pool started draining
+type DrainReport struct {
+	Completed  []string // Finished before the deadline
+	Cancelled  []string // Cancelled at the deadline after starting
+	NotStarted []string // Cancelled at the deadline while still queued or scheduled
+}
+
+// GraphFailurePolicy decides what happens to the rest of a job graph when
+// a node fails
+type GraphFailurePolicy int
+
+const (
+	GraphFailFast        GraphFailurePolicy = iota // Cancel every node that has not finished
+	GraphContinueOnError                           // Skip the failed node's descendants, run the rest
+)
+
+// GraphNode is one job in a JobGraph
+type GraphNode struct {
+	Job       Job
+	DependsOn []string // Job IDs of nodes that must succeed before this one runs
+}
+
+// JobGraph is a set of jobs with dependencies between them. It must not
+// contain cycles.
+type JobGraph struct {
+	ID      string
+	Nodes   []GraphNode
+	OnError GraphFailurePolicy
+}
+
+// GraphState is the overall state of a job graph
+type GraphState int
+
+const (
+	GraphRunning GraphState = iota
+	GraphSucceeded
+	GraphFailed
+	GraphCancelled
+)
+
+// String returns the lowercase name of the state
+func (s GraphState) String() string {
+	switch s {
+	case GraphRunning:
+		return "running"
+	case GraphSucceeded:
+		return "succeeded"
+	case GraphFailed:
+		return "failed"
+	case GraphCancelled:
+		return "cancelled"
+	default:
+		return "unknown"
+	}
+}
+
+// NodeState is the state of one node in a job graph
+type NodeState int
+
+const (
+	NodeWaiting NodeState = iota // Waiting for its parents
+	NodeQueued                   // Submitted to the pool
+	NodeRunning                  // Picked up by a worker
+	NodeSucceeded
+	NodeFailed
+	NodeCancelled
+	NodeSkipped // Not run because a parent failed
+)
+
+// String returns the lowercase name of the state
+func (s NodeState) String() string {
+	switch s {
+	case NodeWaiting:
+		return "waiting"
+	case NodeQueued:
+		return "queued"
+	case NodeRunning:
+		return "running"
+	case NodeSucceeded:
+		return "succeeded"
+	case NodeFailed:
+		return "failed"
+	case NodeCancelled:
+		return "cancelled"
+	case NodeSkipped:
+		return "skipped"
+	default:
+		return "unknown"
+	}
+}
+
+// GraphStatus is a snapshot of a job graph and each of its nodes
+type GraphStatus struct {
+	ID         string
+	State      GraphState
+	Nodes      []GraphNodeStatus // In the order they were declared
+	StartedAt  time.Time
+	FinishedAt time.Time // Zero while running
+}
+
+// GraphNodeStatus is the state of one node in a GraphStatus
+type GraphNodeStatus struct {
+	ID        string
+	State     NodeState
+	DependsOn []string
+	Result    *JobResult // Set once the node has finished
+}
+
+// GroupStatus is a snapshot of a batch of jobs submitted together
+type GroupStatus struct {
+	ID         string
+	JobIDs     []string // In submission order
+	Total      int
+	Pending    int // Queued or running
+	Succeeded  int
+	Failed     int              // Failed or cancelled
+	Errors     map[string]error // Final error of each failed job by ID
+	CreatedAt  time.Time
+	FinishedAt time.Time // Zero until every job has finished
+}
+
+// Done reports whether every job in the group has finished
+func (s GroupStatus) Done() bool {
+	return s.Pending == 0
+}
+
+// WorkflowStep is one step of a Workflow. Handler receives the previous
+// step's output (the workflow's Input for the first step) as job.Data.
+// Compensate, if set, undoes the step after a later step fails and
+// receives the step's output as job.Data.
+type WorkflowStep struct {
+	Name       string // Defaults to "step-<index>"
+	Handler    JobHandler
+	Compensate JobHandler
+	Job        Job // Priority, Timeout, Tenant, RetryPolicy, ... for the step's jobs
+}
+
+// Workflow is a sequence of steps run one after another, each as a job.
+// If a step fails, the compensations of the steps that succeeded run in
+// reverse order.
+type Workflow struct {
+	ID    string
+	Input interface{}
+	Steps []WorkflowStep
+}
+
+// WorkflowState is the overall state of a workflow
+type WorkflowState int
+
+const (
+	WorkflowRunning      WorkflowState = iota
+	WorkflowCompensating               // A step failed; completed steps are being undone
+	WorkflowSucceeded
+	WorkflowCompensated        // A step failed and every completed step was undone
+	WorkflowCompensationFailed // A step failed and so did at least one compensation
 )
 
+// String returns the lowercase name of the state
+func (s WorkflowState) String() string {
+	switch s {
+	case WorkflowRunning:
+		return "running"
+	case WorkflowCompensating:
+		return "compensating"
+	case WorkflowSucceeded:
+		return "succeeded"
+	case WorkflowCompensated:
+		return "compensated"
+	case WorkflowCompensationFailed:
+		return "compensation_failed"
+	default:
+		return "unknown"
+	}
+}
+
+// StepState is the state of one step in a workflow
+type StepState int
+
+const (
+	StepPending StepState = iota // Waiting for earlier steps
+	StepRunning                  // Submitted to the pool
+	StepSucceeded
+	StepFailed
+	StepSkipped // Not run because an earlier step failed
+	StepCompensating
+	StepCompensated
+	StepCompensationFailed
+)
+
+// String returns the lowercase name of the state
+func (s StepState) String() string {
+	switch s {
+	case StepPending:
+		return "pending"
+	case StepRunning:
+		return "running"
+	case StepSucceeded:
+		return "succeeded"
+	case StepFailed:
+		return "failed"
+	case StepSkipped:
+		return "skipped"
+	case StepCompensating:
+		return "compensating"
+	case StepCompensated:
+		return "compensated"
+	case StepCompensationFailed:
+		return "compensation_failed"
+	default:
+		return "unknown"
+	}
+}
+
+// WorkflowStatus is a snapshot of a workflow and each of its steps
+type WorkflowStatus struct {
+	ID         string
+	State      WorkflowState
+	Steps      []WorkflowStepStatus
+	Output     interface{} // Last step's output once the workflow succeeded
+	Error      error       // Error of the step that failed
+	StartedAt  time.Time
+	FinishedAt time.Time // Zero while running or compensating
+}
+
+// WorkflowStepStatus is the state of one step in a WorkflowStatus
+type WorkflowStepStatus struct {
+	Name              string
+	State             StepState
+	JobID             string // ID of the step's job once submitted
+	Output            interface{}
+	Error             error
+	CompensationError error
+	History           []StepChange // Oldest first
+}
+
+// StepChange records one step state transition
+type StepChange struct {
+	State StepState
+	At    time.Time
+}
+
+// ScheduledJob is a job waiting for its run time before being queued
+type ScheduledJob struct {
+	Job   Job
+	RunAt time.Time
+}
+
+// QueueEntry is an unfinished job read back from a QueueBackend
+type QueueEntry struct {
+	Job    Job       // Attempt is the last attempt recorded
+	Status JobStatus // Last status recorded
+	RunAt  time.Time // When the job is due; zero means as soon as possible
+	Err    error     // Why the job could not be read back; Job.Data is then nil
+}
+
+// QueueBackend records the jobs a pool accepts and their state changes so
+// that unfinished jobs survive a restart. The pool calls it from many
+// goroutines at once.
+type QueueBackend interface {
+	// Append records a newly accepted job, due at runAt (zero means now).
+	// The job is rejected if Append fails.
+	Append(job Job, runAt time.Time) error
+
+	// Update records a state change of an appended job: an attempt
+	// starting, or a retry scheduled for runAt
+	Update(id string, status JobStatus, attempt int, runAt time.Time) error
+
+	// Ack records that a job has finished for good, so it is never
+	// replayed. IDs that were never appended are ignored.
+	Ack(id string) error
+
+	// Replay returns the jobs appended and never acknowledged, in the
+	// order they were appended. A job that cannot be read back, such as
+	// one whose payload fails to decode, is returned with Err set; the
+	// pool dead-letters it.
+	Replay() ([]QueueEntry, error)
+
+	// Close releases the backend's resources
+	Close() error
+}
+
 // Worker represents an individual worker in the pool
 type Worker struct {
-	// TODO: Add worker fields
-	// Hints:
-	// - ID: unique worker identifier
-	// - Status: current worker status (idle, busy, stopped)
-	// - JobsProcessed: number of jobs this worker has processed
-	// - LastJobTime: when this worker last processed a job
-	// - StartTime: when this worker was started
-
-	// Example implementation:
-	// ID            int
-	// Status        WorkerStatus
-	// JobsProcessed int64
-	// LastJobTime   time.Time
-	// StartTime     time.Time
+	ID            int
+	Status        WorkerStatus
+	JobsProcessed int64
+	LastJobTime   time.Time
+	StartTime     time.Time
 }
 
 // WorkerStatus represents the current status of a worker
 type WorkerStatus int
 
 const (
-	// TODO: Define worker status constants
-	// Hints:
-	// - WorkerIdle: worker is waiting for jobs
-	// - WorkerBusy: worker is processing a job
-	// - WorkerStopped: worker has been stopped
-
-	// Example implementation:
-	// WorkerIdle WorkerStatus = iota
-	// WorkerBusy
-	// WorkerStopped
-	_ WorkerStatus = iota // TODO: Replace with actual constants
+	WorkerIdle WorkerStatus = iota
+	WorkerBusy
+	WorkerStopped
+)
+
+// OverflowPolicy decides what happens to a submission when the job queue
+// is full
+type OverflowPolicy int
+
+const (
+	OverflowBlock      OverflowPolicy = iota // Wait for space, up to SubmitTimeout
+	OverflowFailFast                         // Refuse the job with ErrQueueFull
+	OverflowDropOldest                       // Evict the longest-queued job to make room
+	OverflowDropNewest                       // Refuse the new job with ErrJobDropped
+	OverflowCallerRuns                       // Run the job in the submitter's goroutine
+)
+
+// String returns the snake_case name of the policy
+func (o OverflowPolicy) String() string {
+	switch o {
+	case OverflowBlock:
+		return "block"
+	case OverflowFailFast:
+		return "fail_fast"
+	case OverflowDropOldest:
+		return "drop_oldest"
+	case OverflowDropNewest:
+		return "drop_newest"
+	case OverflowCallerRuns:
+		return "caller_runs"
+	default:
+		return "unknown"
+	}
+}
+
+// AutoscaleConfig configures automatic worker scaling. The pool scales up
+// when the queue holds more than QueuePerWorker jobs per worker or the
+// p95 queue wait exceeds WaitP95, and scales down one worker at a time
+// after it has had idle workers and an empty queue for IdleTime. Jobs
+// held back by a job type or partition key concurrency cap do not count
+// as queued: more workers would not start them any sooner.
+type AutoscaleConfig struct {
+	MinWorkers        int           // Defaults to 1
+	MaxWorkers        int           // Zero disables autoscaling
+	QueuePerWorker    int           // Queued jobs per worker that trigger a scale-up; defaults to 1
+	WaitP95           time.Duration // p95 queue wait that triggers a scale-up; zero ignores wait time
+	IdleTime          time.Duration // Sustained idle time before a scale-down; zero never scales down
+	ScaleUpCooldown   time.Duration // Minimum time between scale-ups
+	ScaleDownCooldown time.Duration // Minimum time between scale-downs
+	Interval          time.Duration // How often to evaluate; defaults to 1s
+}
+
+// ScaleEvent records one change in the number of workers
+type ScaleEvent struct {
+	At     time.Time
+	From   int
+	To     int
+	Reason string
+}
+
+// SchedulerKind selects how queued jobs reach workers
+type SchedulerKind int
+
+const (
+	// SchedulerChannel hands each job to the next idle worker over a
+	// shared channel
+	SchedulerChannel SchedulerKind = iota
+	// SchedulerWorkStealing keeps a small deque per worker; idle workers
+	// steal from random busy ones. Priority order is kept across the
+	// shared queue but only approximately within the deques.
+	SchedulerWorkStealing
 )
 
 // PoolConfig contains configuration for the worker pool
 type PoolConfig struct {
-	// TODO: Add configuration fields
-	// Hints:
-	// - WorkerCount: number of workers to create
-	// - QueueSize: size of the job queue buffer
-	// - JobTimeout: default timeout for jobs
-	// - ShutdownTimeout: how long to wait for graceful shutdown
-	// - EnableMetrics: whether to collect metrics
-	// - MetricsInterval: how often to update metrics
-
-	// Example implementation:
-	// WorkerCount      int
-	// QueueSize        int
-	// JobTimeout       time.Duration
-	// ShutdownTimeout  time.Duration
-	// EnableMetrics    bool
-	// MetricsInterval  time.Duration
+	WorkerCount     int
+	QueueSize       int
+	JobTimeout      time.Duration
+	ShutdownTimeout time.Duration
+	EnableMetrics   bool
+	MetricsInterval time.Duration
+
+	// AgingInterval is how long a queued job must wait to gain one
+	// priority level, so low-priority jobs cannot starve. Zero disables
+	// aging and orders strictly by priority, then submission order.
+	AgingInterval time.Duration
+
+	// Handler processes each job. When nil the pool simulates work.
+	Handler JobHandler
+
+	// Middlewares wrap every handler call, typed or not. The first
+	// middleware is the outermost: it runs first and sees the result
+	// last, so Middlewares{A, B} runs A(B(handler)).
+	Middlewares []Middleware
+
+	// RetryPolicy applies to jobs that do not set their own
+	RetryPolicy RetryPolicy
+
+	// DeadLetterSize bounds the dead-letter queue; the oldest entries are
+	// evicted first. Zero disables dead-lettering.
+	DeadLetterSize int
+
+	// JobRetention is how long finished jobs stay queryable by ID; zero
+	// keeps them until MaxJobRecords evicts them. MaxJobRecords bounds the
+	// number of finished jobs kept, oldest evicted first.
+	JobRetention  time.Duration
+	MaxJobRecords int
+
+	// StuckJobGrace is how long a job may keep running after its timeout
+	// before the watchdog abandons it as timed out and replaces its
+	// worker. Zero disables abandonment.
+	StuckJobGrace time.Duration
+
+	// HeartbeatTimeout reports a running job as stuck when it has called
+	// Heartbeat but not again within this long. Zero disables the check.
+	HeartbeatTimeout time.Duration
+
+	// VisibilityTimeout leases each delivered job to its worker for this
+	// long; handlers that need longer extend the lease with ExtendLease.
+	// When a lease expires first, because the handler is stuck or its
+	// worker crashed, the job goes back to the queue for redelivery and
+	// the late result is discarded. Zero disables leases. After
+	// MaxDeliveries deliveries of one attempt the job fails with
+	// ErrLeaseExpired instead; zero redelivers without limit.
+	VisibilityTimeout time.Duration
+	MaxDeliveries     int
+
+	// OverflowPolicy decides what Submit does when the queue is full.
+	// Jobs evicted by OverflowDropOldest finish with an error wrapping
+	// ErrJobDropped and context.Canceled.
+	OverflowPolicy OverflowPolicy
+
+	// SubmitTimeout bounds how long OverflowBlock waits for queue space.
+	// Zero waits until the submit context is done.
+	SubmitTimeout time.Duration
+
+	// Autoscale resizes the pool between MinWorkers and MaxWorkers;
+	// WorkerCount is the starting size
+	Autoscale AutoscaleConfig
+
+	// Scheduler selects how queued jobs reach workers
+	Scheduler SchedulerKind
+
+	// Tenants configures weighted fair queuing by Job.Tenant: tenants take
+	// turns at dispatch in proportion to their weights, and priority only
+	// orders jobs within a tenant. Tenants not listed, including jobs
+	// without a tenant, use DefaultTenant. MaxQueued applies to
+	// submissions, including SubmitAt when it is called; scheduled jobs
+	// coming due and retries are queued regardless.
+	Tenants       map[string]TenantConfig
+	DefaultTenant TenantConfig
+
+	// MaxConcurrentPerKey bounds how many jobs with the same
+	// Job.PartitionKey run at once; zero is unlimited. Jobs over the limit
+	// wait for their key without holding up other keys, and start in
+	// submission order, so 1 runs each key's jobs one at a time in FIFO
+	// order. A retry rejoins the back of its key's line. Under
+	// OverflowCallerRuns the submitter waits for room on the key, until
+	// its submit context is done, before running the job itself.
+	MaxConcurrentPerKey int
+
+	// IdempotencyWindow is how long a job's IdempotencyKey suppresses
+	// resubmissions; zero disables deduplication. At most
+	// MaxIdempotencyKeys keys are remembered, oldest forgotten first.
+	IdempotencyWindow  time.Duration
+	MaxIdempotencyKeys int
+
+	// QueueBackend records accepted jobs so that unfinished ones are
+	// replayed by the first Start after a crash or restart. Nil keeps jobs
+	// in memory only.
+	QueueBackend QueueBackend
 }
 
 // DefaultPoolConfig returns a default configuration for the worker pool
 func DefaultPoolConfig() PoolConfig {
-	// TODO: Implement default configuration
-	// Hints:
-	// - Use reasonable defaults for all fields
-	// - Consider the target machine's CPU count for worker count
-	// - Set appropriate timeouts for different scenarios
-
 	return PoolConfig{
-		// TODO: Add default values
-		// Example:
-		// WorkerCount:     runtime.NumCPU(),
-		// QueueSize:       1000,
-		// JobTimeout:      30 * time.Second,
-		// ShutdownTimeout: 10 * time.Second,
-		// EnableMetrics:   true,
-		// MetricsInterval: 1 * time.Second,
+		WorkerCount:     runtime.NumCPU(),
+		QueueSize:       1000,
+		JobTimeout:      30 * time.Second,
+		ShutdownTimeout: 10 * time.Second,
+		EnableMetrics:   true,
+		MetricsInterval: 1 * time.Second,
+		AgingInterval:   5 * time.Second,
+		RetryPolicy:     RetryPolicy{MaxAttempts: 1},
+		DeadLetterSize:  1000,
+		JobRetention:    10 * time.Minute,
+		MaxJobRecords:   10000,
+		StuckJobGrace:   10 * time.Second,
+		OverflowPolicy:  OverflowBlock,
+		SubmitTimeout:   5 * time.Second,
+
+		IdempotencyWindow:  10 * time.Minute,
+		MaxIdempotencyKeys: 10000,
 	}
 }
 
 // JobHandler defines a function type for processing jobs
 type JobHandler func(ctx context.Context, job Job) (interface{}, error)
 
+// Middleware wraps a JobHandler to add behavior around job execution
+type Middleware func(next JobHandler) JobHandler
+
 // ResultHandler defines a function type for handling job results
 type ResultHandler func(result JobResult)
 
@@ -199,8 +819,6 @@ type ErrorHandler func(err error)
 
 // TODO: Add any additional types or interfaces you think would be useful
 // Consider:
-// - Priority queue interfaces
-// - Job retry mechanisms
 // - Circuit breaker types
 // - Rate limiting types
 // - Monitoring and alerting types
@@ -0,0 +1,91 @@
+package benchmarks
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/cs-mastery/worker-pool/internal/pool"
+	"github.com/cs-mastery/worker-pool/pkg/types"
+)
+
+var (
+	schedulers = []struct {
+		name string
+		kind types.SchedulerKind
+	}{
+		{"channel", types.SchedulerChannel},
+		{"stealing", types.SchedulerWorkStealing},
+	}
+	workerCounts = []int{1, 4, 16, 64}
+	jobDurations = []time.Duration{0, 10 * time.Microsecond, time.Millisecond}
+)
+
+// BenchmarkScheduler measures end-to-end throughput of each scheduler,
+// from Submit to the result being read, across worker counts and job
+// durations
+func BenchmarkScheduler(b *testing.B) {
+	for _, duration := range jobDurations {
+		for _, workers := range workerCounts {
+			for _, s := range schedulers {
+				name := fmt.Sprintf("job=%v/workers=%d/%s", duration, workers, s.name)
+				b.Run(name, func(b *testing.B) {
+					benchmarkPool(b, s.kind, workers, duration)
+				})
+			}
+		}
+	}
+}
+
+// benchmarkPool submits b.N jobs that each take duration and waits for
+// all of their results
+func benchmarkPool(b *testing.B, scheduler types.SchedulerKind, workers int, duration time.Duration) {
+	p := pool.NewPool(types.PoolConfig{
+		WorkerCount: workers,
+		QueueSize:   1024,
+		JobTimeout:  time.Minute,
+		Scheduler:   scheduler,
+		Handler: func(ctx context.Context, job types.Job) (interface{}, error) {
+			if duration > 0 {
+				spin(duration)
+			}
+			return nil, nil
+		},
+	})
+	if err := p.Start(); err != nil {
+		b.Fatal(err)
+	}
+	defer p.Stop()
+
+	done := make(chan error, 1)
+	go func() {
+		for i := 0; i < b.N; i++ {
+			if _, err := p.GetResult(); err != nil {
+				done <- err
+				return
+			}
+		}
+		done <- nil
+	}()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := p.Submit(types.Job{ID: strconv.Itoa(i)}); err != nil {
+			b.Fatal(err)
+		}
+	}
+	if err := <-done; err != nil {
+		b.Fatal(err)
+	}
+	b.StopTimer()
+}
+
+// spin busy-waits for d. Sleeping would measure timer resolution rather
+// than scheduling overhead for short jobs.
+func spin(d time.Duration) {
+	for start := time.Now(); time.Since(start) < d; {
+	}
+}